@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var contentAdapter = flag.String("content-adapter", "", "instead of a Markdown file per post, write the posts and pages into this JSON file in the site's data directory, relative to the target directory, e.g. ../../data/blogger/posts.json, and a _content.gotmpl content adapter making pages of them at build time (Hugo 0.126 or later)")
+
+// A post or page as the content adapter's data file holds it.
+type adapterPage struct {
+	// Where it's added, relative to the adapter's directory, without an extension.
+	Path        string `json:"path"`
+	FrontMatter string `json:"frontmatter"`
+	MediaType   string `json:"mediaType"`
+	Content     string `json:"content"`
+}
+
+// What site.Data holds the data file under, e.g. ["blogger" "posts"] for
+// data/blogger/posts.json, or nil if it isn't in a data directory.
+func adapterDataKeys(name string) []string {
+	parts := strings.Split(path.Clean(filepath.ToSlash(name)), "/")
+	for i := len(parts) - 2; i >= 0; i-- {
+		if parts[i] == "data" {
+			keys := parts[i+1:]
+			last := len(keys) - 1
+			keys[last] = strings.TrimSuffix(keys[last], path.Ext(keys[last]))
+			return keys
+		}
+	}
+	return nil
+}
+
+// Add a post or page to the content adapter's data instead of writing its file. Jobs
+// get here one at a time in export order, so the data file lists them the same way
+// every run.
+func (c *Converter) addAdapterPage(filename string, e Entry) error {
+	var buf bytes.Buffer
+	if err := c.t.Execute(&buf, e); err != nil {
+		return &WriteError{Path: filename, Err: err}
+	}
+	fm, body, _ := splitFrontMatter(normalizeLines(buf.Bytes()))
+	// The front matter without its delimiter lines, for transform.Unmarshal.
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(fm), "\r\n", "\n")), "\n")
+	if len(lines) >= 2 {
+		lines = lines[1 : len(lines)-1]
+	}
+	p := strings.TrimSuffix(filename, path.Ext(filename))
+	p = strings.TrimSuffix(strings.TrimSuffix(p, "/index"), "/_index")
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return &WriteError{Path: filename, Err: fmt.Errorf("outside the content adapter's directory")}
+	}
+	mediaType := "text/markdown"
+	if path.Ext(filename) == ".html" {
+		mediaType = "text/html"
+	}
+	c.adapterPages = append(c.adapterPages, adapterPage{
+		Path:        p,
+		FrontMatter: strings.Join(lines, "\n"),
+		MediaType:   mediaType,
+		Content:     strings.TrimLeft(string(body), "\r\n"),
+	})
+	return nil
+}
+
+// The content adapter: it reads every page's front matter and makes a page of it,
+// with Hugo's own front matter fields at the top level and the rest as params.
+const adapterTempl = `{{/* Made by blogger2hugo: the blog's posts and pages, from %s. */}}
+{{- $fields := slice "title" "linktitle" "slug" "url" "draft" "weight" "aliases" "type" "layout" "description" "summary" "keywords" "build" "outputs" "sitemap" }}
+{{- $dates := dict "date" "date" "updated" "lastmod" "lastmod" "lastmod" "publishdate" "publishDate" "expirydate" "expiryDate" }}
+{{- range %s }}
+  {{- $page := dict "path" .path "content" (dict "mediaType" .mediaType "value" .content) }}
+  {{- $pageDates := dict }}
+  {{- $params := dict }}
+  {{- range $k, $v := .frontmatter | transform.Unmarshal }}
+    {{- $key := lower $k }}
+    {{- if in $fields $key }}
+      {{- $page = merge $page (dict $key $v) }}
+    {{- else if eq $key "menu" }}
+      {{- $page = merge $page (dict "menus" $v) }}
+    {{- else if isset $dates $key }}
+      {{- $pageDates = merge $pageDates (dict (index $dates $key) (time.AsTime $v)) }}
+    {{- else }}
+      {{- $params = merge $params (dict $k $v) }}
+    {{- end }}
+  {{- end }}
+  {{- $.AddPage (merge $page (dict "dates" $pageDates "params" $params)) }}
+{{- end }}
+`
+
+// Write the content adapter's data file, and the adapter itself into the target directory.
+func (c *Converter) writeContentAdapter() error {
+	name := path.Clean(filepath.ToSlash(*contentAdapter))
+	f, err := c.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	pages := c.adapterPages
+	if pages == nil {
+		pages = []adapterPage{}
+	}
+	err = enc.Encode(pages)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	keys := adapterDataKeys(name)
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = fmt.Sprintf("%q", k)
+	}
+	if f, err = c.create("_content.gotmpl"); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, adapterTempl, name, "index site.Data "+strings.Join(quoted, " "))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
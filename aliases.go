@@ -0,0 +1,27 @@
+package main
+
+import "flag"
+
+var aliases = flag.Bool("aliases", false, "list the Blogger path of each post and page, e.g. /2014/05/hello-world.html, as an alias in its front matter, so the site generator writes a redirect there itself")
+
+// Give each post and page written the Blogger paths mapped to it as Aliases: its own,
+// and those of any copies of it -duplicates=skip left out. Paths the new permalink
+// already serves are left out, as an alias there would replace the page.
+func (c *Converter) addAliases() {
+	byPath := map[string][]string{}
+	for _, m := range c.mappings {
+		if m.Blogger == "" {
+			continue
+		}
+		from := bloggerPath(m.Blogger)
+		if from == "" || from == m.Permalink {
+			continue
+		}
+		byPath[m.Path] = append(byPath[m.Path], from)
+	}
+	for k := range c.exp.Entries {
+		if e := &c.exp.Entries[k]; e.Path != "" {
+			e.Aliases = byPath[e.Path]
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// <a name="x"></a><h2>Title</h2>
+	anchorBeforeHeading = regexp.MustCompile(`(?is)<a\s+name\s*=\s*("[^"]*"|'[^']*')\s*>\s*</a>\s*<(h[1-6])\b([^>]*)>`)
+	// <h2><a name="x"></a>Title</h2>
+	anchorInHeading = regexp.MustCompile(`(?is)<(h[1-6])\b([^>]*)>\s*<a\s+name\s*=\s*("[^"]*"|'[^']*')\s*>\s*</a>`)
+	namedAnchor     = regexp.MustCompile(`(?i)<a\b([^>]*)\bname\s*=\s*("[^"]*"|'[^']*')([^>]*)>`)
+	idAttr          = regexp.MustCompile(`(?i)\bid\s*=`)
+)
+
+// Give the targets of in-page links an id, which Markdown and HTML5 keep, instead of
+// the old <a name> anchors Blogger's editor made. Anchors next to a heading move onto it,
+// so the heading gets the matching explicit ID, e.g. ## Title {#x} once in Markdown.
+func explicitAnchors(content string) string {
+	content = anchorBeforeHeading.ReplaceAllStringFunc(content, func(s string) string {
+		m := anchorBeforeHeading.FindStringSubmatch(s)
+		if idAttr.MatchString(m[3]) {
+			return s
+		}
+		return "<" + m[2] + " id=" + m[1] + m[3] + ">"
+	})
+	content = anchorInHeading.ReplaceAllStringFunc(content, func(s string) string {
+		m := anchorInHeading.FindStringSubmatch(s)
+		if idAttr.MatchString(m[2]) {
+			return s
+		}
+		return "<" + m[1] + " id=" + m[3] + m[2] + ">"
+	})
+	return namedAnchor.ReplaceAllStringFunc(content, func(s string) string {
+		m := namedAnchor.FindStringSubmatch(s)
+		if idAttr.MatchString(m[1] + m[3]) {
+			return s
+		}
+		return "<a id=" + m[2] + m[1] + "name=" + m[2] + m[3] + ">"
+	})
+}
+
+// Whether an entry has an element a link's fragment can land on.
+func hasAnchor(content, fragment string) bool {
+	for _, q := range []string{`"`, `'`} {
+		for _, attr := range []string{"id=", "name="} {
+			if strings.Contains(content, attr+q+fragment+q) {
+				return true
+			}
+		}
+	}
+	return false
+}
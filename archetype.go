@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"path"
+	"path/filepath"
+	"text/template"
+)
+
+var archetypeFile = flag.String("archetype", "", "write a Hugo archetype with the same front matter as the converted posts to this file, relative to the target directory (e.g. ../../archetypes/posts.md)")
+
+// Archetypes are Hugo templates themselves, so ours use [[ ]] to leave Hugo's {{ }} alone.
+var archetypeTempls = map[string]string{
+	"yaml": `---
+title: "{{ replace .File.ContentBaseName "-" " " | title }}"[[ if slugInFrontMatter ]]
+slug: "{{ .File.ContentBaseName }}"[[ end ]]
+date: {{ .Date }}
+updated: {{ .Date }}
+[[ tagsKey ]]: []
+draft: true
+author: ""
+---
+`,
+	"toml": `+++
+title = "{{ replace .File.ContentBaseName "-" " " | title }}"[[ if slugInFrontMatter ]]
+slug = "{{ .File.ContentBaseName }}"[[ end ]]
+date = {{ .Date }}
+updated = {{ .Date }}
+[[ tagsKey ]] = []
+draft = true
+[author]
+	name = ""
+	uri = ""
+[author.image]
+	source = ""
+	width = ""
+	height = ""
+
++++
+`,
+}
+
+func (c *Converter) writeArchetype() error {
+	at, err := template.New("").Delims("[[", "]]").Funcs(c.templateFuncs()).Parse(archetypeTempls[*format])
+	if err != nil {
+		return err
+	}
+	f, err := c.create(path.Clean(filepath.ToSlash(*archetypeFile)))
+	if err != nil {
+		return err
+	}
+	if err := at.Execute(f, nil); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var outputArchive = flag.String("output-archive", "", "write all generated files into this .zip, .tar or .tar.gz archive instead of a directory")
+var memoryBudget = flag.Int("memory-budget", 64, "with -output-archive, how many MB of files may wait in memory to go into the archive; past it they wait in temporary files")
+
+// An archive that generated files are streamed into.
+type archive struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+	zw *zip.Writer
+	// Files are written side by side, and go in one at a time as they're closed.
+	mu sync.Mutex
+	// The time every file is stamped with.
+	modTime time.Time
+	// The directory files go into inside the archive, e.g. content/posts, or "".
+	prefix string
+	// Bytes of files waiting in memory, up to -memory-budget.
+	budgetMu sync.Mutex
+	inMemory int64
+}
+
+// Start an archive on w in the format implied by name: .zip, .tar, .tar.gz or .tgz.
+func newArchive(w io.Writer, name string) (*archive, error) {
+	a := &archive{modTime: archiveTime()}
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, ".zip"):
+		a.zw = zip.NewWriter(w)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		a.gz = gzip.NewWriter(w)
+		a.tw = tar.NewWriter(a.gz)
+	case strings.HasSuffix(lower, ".tar"):
+		a.tw = tar.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("Unknown archive format for %s, expected .zip, .tar or .tar.gz", name)
+	}
+	return a, nil
+}
+
+// Put files under dir inside the archive, the target directory, so the files written
+// beside it, e.g. -redirects-file=../../static/_redirects, land where they would on disk.
+func (a *archive) setPrefix(dir string) error {
+	p := filepath.ToSlash(filepath.Clean(dir))
+	if p == "." {
+		p = ""
+	} else if !fs.ValidPath(p) {
+		return fmt.Errorf("%s sets the paths inside -output-archive, so it has to be a relative path without ..", dir)
+	}
+	a.prefix = p
+	return nil
+}
+
+// Create name, relative to the target directory, which has to stay inside the archive.
+func (a *archive) Create(name string) (io.WriteCloser, error) {
+	full := path.Join(a.prefix, name)
+	if !fs.ValidPath(full) {
+		return nil, fmt.Errorf("%s is outside the archive and cannot be archived", name)
+	}
+	return &archiveFile{a: a, name: full}, nil
+}
+
+func (a *archive) Close() error {
+	if a.zw != nil {
+		return a.zw.Close()
+	}
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	if a.gz != nil {
+		return a.gz.Close()
+	}
+	return nil
+}
+
+// When the files in an archive were made: $SOURCE_DATE_EPOCH if it's set, so that the
+// same export and flags give the same archive byte for byte, or else now.
+func archiveTime() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Now()
+}
+
+// Take n bytes of the memory budget, if that much is left.
+func (a *archive) reserve(n int) bool {
+	a.budgetMu.Lock()
+	defer a.budgetMu.Unlock()
+	if a.inMemory+int64(n) > int64(*memoryBudget)<<20 {
+		return false
+	}
+	a.inMemory += int64(n)
+	return true
+}
+
+func (a *archive) release(n int) {
+	a.budgetMu.Lock()
+	a.inMemory -= int64(n)
+	a.budgetMu.Unlock()
+}
+
+// A file kept back until it's closed, as tar needs each file's size up front and
+// both formats take one file at a time. It waits in memory while the budget allows,
+// and in a temporary file once it doesn't, e.g. for a large video.
+type archiveFile struct {
+	a    *archive
+	name string
+	buf  bytes.Buffer
+	tmp  scratchFile
+	size int64
+}
+
+func (f *archiveFile) Write(p []byte) (int, error) {
+	if f.tmp == nil && !f.a.reserve(len(p)) {
+		tmp, err := newScratch()
+		if err != nil {
+			return 0, err
+		}
+		f.tmp = tmp
+		_, err = tmp.Write(f.buf.Bytes())
+		f.a.release(f.buf.Len())
+		f.buf = bytes.Buffer{}
+		if err != nil {
+			return 0, err
+		}
+	}
+	f.size += int64(len(p))
+	if f.tmp != nil {
+		return f.tmp.Write(p)
+	}
+	return f.buf.Write(p)
+}
+
+func (f *archiveFile) Close() error {
+	var r io.Reader = &f.buf
+	if f.tmp != nil {
+		defer f.tmp.Close()
+		if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = f.tmp
+	} else {
+		defer f.a.release(f.buf.Len())
+	}
+	f.a.mu.Lock()
+	defer f.a.mu.Unlock()
+	var w io.Writer
+	if f.a.zw != nil {
+		var err error
+		if w, err = f.a.zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: f.a.modTime}); err != nil {
+			return err
+		}
+	} else {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: f.size, ModTime: f.a.modTime, Typeflag: tar.TypeReg}
+		if err := f.a.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		w = f.a.tw
+	}
+	_, err := io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// Files go under the target directory inside the archive, and names relative to it may
+// reach beside it, as -redirects-file does, but not out of the archive.
+func TestArchivePrefix(t *testing.T) {
+	var b bytes.Buffer
+	a, err := newArchive(&b, "site.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"../site", "/srv/site/content"} {
+		if err := a.setPrefix(dir); err == nil {
+			t.Errorf("setPrefix(%q) gave no error", dir)
+		}
+	}
+	if err := a.setPrefix("content/posts/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2020-01-15-first-snow.md", "../../static/_redirects"} {
+		w, err := a.Create(name)
+		if err != nil {
+			t.Fatalf("Create(%q): %s", name, err)
+		}
+		w.Write([]byte("x"))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.Create("../../../links.csv"); err == nil {
+		t.Error("a file outside the archive was created")
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"content/posts/2020-01-15-first-snow.md", "static/_redirects"}
+	if got := zipNames(t, b.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("archived %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+var authorsList = flag.Bool("authors", false, "also list every author of each post under authors in the front matter, for themes that support several authors per post on team blogs")
+var authorPages = flag.String("author-pages", "", "write a page for each author into this directory, relative to the target directory, e.g. ../authors when converting into content/posts: <author>/_index.md with their name and profile, and their avatar beside it; implies -authors")
+
+// The names of an entry's authors, Author first as the script or transforms left it.
+func entryAuthors(e Entry) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	add(e.Author.Name)
+	for i, a := range e.Authors {
+		if i > 0 {
+			add(a.Name)
+		}
+	}
+	return names
+}
+
+// The authors list for the front matter under -authors, quoted and comma separated.
+func authorsFrontMatter(e Entry) string {
+	if !*authorsList && *authorPages == "" {
+		return ""
+	}
+	return hugo.QuoteList(entryAuthors(e))
+}
+
+var authorTempl = template.Must(template.New("").Parse(`---
+title: "{{ .Name }}"{{ with .Uri }}
+profile: "{{ . }}"{{ end }}{{ with .Avatar }}
+avatar: "{{ . }}"{{ end }}
+---
+`))
+
+// Write the -author-pages: an _index.md for each author of the posts and pages written,
+// under the key Hugo gives their name as an authors term, with their avatar downloaded
+// beside it. An avatar that can't be downloaded is linked where it is instead.
+func (c *Converter) writeAuthorPages(ctx context.Context) error {
+	authors := map[string]Author{}
+	for _, e := range c.exp.Entries {
+		if kind := e.Kind(); kind != "post" && kind != "page" || e.Path == "" || skipHidden(e) {
+			continue
+		}
+		all := e.Authors
+		if len(all) == 0 {
+			all = []Author{e.Author}
+		} else {
+			all = append([]Author{e.Author}, all[1:]...)
+		}
+		for _, a := range all {
+			if a.Name == "" {
+				continue
+			}
+			key := c.authorKey(a)
+			// The fullest profile found for them.
+			if prev, ok := authors[key]; !ok || prev.Uri == "" && prev.Image.Source == "" {
+				authors[key] = a
+			}
+		}
+	}
+	keys := make([]string, 0, len(authors))
+	for key := range authors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	dir := path.Clean(strings.ReplaceAll(*authorPages, `\`, "/"))
+	client := &http.Client{Timeout: 15 * time.Second}
+	failed := 0
+	for _, key := range keys {
+		a := authors[key]
+		avatar := avatarURL(a.Image.Source)
+		if avatar != "" {
+			name, err := c.downloadAvatar(ctx, client, avatar, path.Join(dir, key))
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				failed++
+			} else {
+				avatar = name
+			}
+		}
+		f, err := c.create(path.Join(dir, key, "_index.md"))
+		if err != nil {
+			return err
+		}
+		err = authorTempl.Execute(f, struct {
+			Name, Uri, Avatar string
+		}{a.Name, a.Uri, avatar})
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("Wrote pages for %d authors to %s.", len(keys), dir)
+	if failed > 0 {
+		log.Printf("%d author avatars could not be downloaded and are linked where they were.", failed)
+	}
+	return nil
+}
+
+// The URL of an author's avatar, or "" for none or Blogger's placeholder.
+func avatarURL(src string) string {
+	if src == "" || src == defaultAvatar || strings.HasSuffix(src, "/b16-rounded.gif") || strings.HasSuffix(src, "/blank.gif") {
+		return ""
+	}
+	if strings.HasPrefix(src, "//") {
+		return "https:" + src
+	}
+	return src
+}
+
+// Save the avatar at u into dir, returning its file name.
+func (c *Converter) downloadAvatar(ctx context.Context, client *http.Client, u, dir string) (string, error) {
+	resp, err := request(ctx, client, "GET", u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", u, resp.Status)
+	}
+	ext := strings.ToLower(path.Ext(strings.SplitN(u, "?", 2)[0]))
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+	default:
+		ext = ".jpg"
+	}
+	name := "avatar" + ext
+	f, err := c.create(path.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return name, err
+}
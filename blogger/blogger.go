@@ -0,0 +1,292 @@
+// Package blogger reads Blogger's Atom export format: the file Blogger's
+// "Back up content" produces, and the feeds inside a Google Takeout.
+package blogger
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Date time.Time
+
+func (d Date) String() string {
+	return time.Time(d).Format("2006-01-02T15:04:05Z")
+}
+
+// The layouts dates are read in: Blogger's own, and the variants older exports and
+// other tools writing Blogger's format use. A fraction of a second is optional, of any
+// number of digits, in the layouts with .999999999.
+var dateLayouts = []string{
+	// Blogger's, 2014-05-19T23:36:00.000-07:00, and RFC 3339's others: without a
+	// fraction, with six digits, or in UTC as Z.
+	time.RFC3339Nano,
+	// An offset without its colon, -0700.
+	"2006-01-02T15:04:05.999999999-0700",
+	// Without seconds.
+	"2006-01-02T15:04Z07:00",
+	// Without a time zone, taken as UTC.
+	"2006-01-02T15:04:05.999999999",
+	// A space for the T.
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02",
+}
+
+// A date that's empty or can't be read is left zero, for the caller to fill in, rather
+// than failing the entry.
+func (d *Date) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var v string
+	if err := dec.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*d, _ = parseDate(v)
+	return nil
+}
+
+// The date v, or the zero date and false if it's not empty but can't be read.
+func parseDate(v string) (Date, bool) {
+	// RFC 3339 allows a lowercase t and z.
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if v == "" {
+		return Date{}, true
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return Date(t), true
+		}
+	}
+	return Date{}, false
+}
+
+// Whether the date is missing, or couldn't be read.
+func (d Date) IsZero() bool {
+	return time.Time(d).IsZero()
+}
+
+type Draft bool
+
+func (d *Draft) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var v string
+	dec.DecodeElement(&v, &start)
+	switch v {
+	case "yes":
+		*d = true
+		return nil
+	case "no":
+		*d = false
+		return nil
+	}
+	return fmt.Errorf("Unknown value for draft boolean: %s", v)
+}
+
+type Reply struct {
+	Rel    string `xml:"rel,attr"`
+	Link   string `xml:"href,attr"`
+	Source string `xml:"source,attr"`
+	// The media type and size in bytes of what's linked, given for enclosures.
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type Image struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Source string `xml:"src,attr"`
+}
+
+type Author struct {
+	Name  string `xml:"name"`
+	Uri   string `xml:"uri"`
+	Image Image  `xml:"image"`
+}
+
+type Export struct {
+	XMLName xml.Name `xml:"feed"`
+	Title   string   `xml:"title"`
+	Entries []Entry  `xml:"entry"`
+}
+
+// An entry of the export: a post, page or comment, or one of the blog's
+// settings or its theme.
+type Entry struct {
+	ID        string  `xml:"id"`
+	Published Date    `xml:"published"`
+	Updated   Date    `xml:"updated"`
+	Draft     Draft   `xml:"control>draft"`
+	Title     string  `xml:"title"`
+	Content   string  `xml:"content"`
+	Tags      Tags    `xml:"category"`
+	Author    Author  `xml:"author"`
+	Source    Reply   `xml:"in-reply-to"`
+	Links     []Reply `xml:"link"`
+	// Every author of the entry, Author first; on team blogs a post can have several.
+	Authors []Author `xml:"-"`
+	// Elements of the entry that aren't otherwise read, such as the geo tags and
+	// categories blogging apps like Open Live Writer add.
+	Extensions []Extension `xml:"-"`
+	// Values that couldn't be read, and were read with a default instead.
+	problems []problem
+}
+
+// A value of an entry that couldn't be read, and what it was taken as.
+type problem struct {
+	what, read string
+}
+
+// An element of an entry kept as it was, with its attributes and inner XML.
+type Extension struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// Entries decode as tagged, except that title and content may be Atom xhtml, whose
+// markup would otherwise be dropped, and that a draft flag or date that can't be read
+// is read with a default, and noted for the Parser.
+func (e *Entry) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	type entry Entry
+	var v struct {
+		entry
+		Published  string      `xml:"published"`
+		Updated    string      `xml:"updated"`
+		Draft      string      `xml:"control>draft"`
+		Title      Text        `xml:"title"`
+		Content    Text        `xml:"content"`
+		Authors    []Author    `xml:"author"`
+		Tags       []category  `xml:"category"`
+		Extensions []Extension `xml:",any"`
+	}
+	if err := dec.DecodeElement(&v, &start); err != nil {
+		// The ID, if it was read, says which entry it was.
+		e.ID = v.ID
+		return err
+	}
+	*e = Entry(v.entry)
+	e.Title, e.Content = v.Title.String(), v.Content.String()
+	var ok bool
+	if e.Published, ok = parseDate(v.Published); !ok {
+		e.problems = append(e.problems, problem{fmt.Sprintf("published date %q can't be read", v.Published), "missing"})
+	}
+	if e.Updated, ok = parseDate(v.Updated); !ok {
+		e.problems = append(e.problems, problem{fmt.Sprintf("updated date %q can't be read", v.Updated), "missing"})
+	}
+	switch d := strings.TrimSpace(v.Draft); d {
+	case "yes":
+		e.Draft = true
+	case "", "no":
+	default:
+		// Read the way other tools write flags, e.g. true or 1.
+		e.Draft = Draft(strings.EqualFold(d, "yes") || strings.EqualFold(d, "true") || d == "1")
+		read := "not a draft"
+		if e.Draft {
+			read = "a draft"
+		}
+		e.problems = append(e.problems, problem{fmt.Sprintf("unknown value for draft: %q", d), read})
+	}
+	if len(v.Authors) > 0 {
+		e.Author, e.Authors = v.Authors[0], v.Authors
+	}
+	for _, c := range v.Tags {
+		if c.XMLName.Space == "" || c.XMLName.Space == atomNS {
+			e.Tags = append(e.Tags, c.Tag)
+			continue
+		}
+		// Another app's categories, e.g. Open Live Writer's.
+		x := Extension{XMLName: c.XMLName, Inner: c.Inner}
+		if c.Name != "" {
+			x.Attrs = append(x.Attrs, xml.Attr{Name: xml.Name{Local: "term"}, Value: c.Name})
+		}
+		if c.Scheme != "" {
+			x.Attrs = append(x.Attrs, xml.Attr{Name: xml.Name{Local: "scheme"}, Value: c.Scheme})
+		}
+		x.Attrs = append(x.Attrs, c.Attrs...)
+		v.Extensions = append(v.Extensions, x)
+	}
+	e.Extensions = v.Extensions
+	return nil
+}
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+// A category element, which may be Atom's or another namespace's.
+type category struct {
+	XMLName xml.Name
+	Tag
+	Attrs []xml.Attr `xml:",any,attr"`
+	Inner string     `xml:",innerxml"`
+}
+
+const (
+	KindScheme  = "http://schemas.google.com/g/2005#kind"
+	KindPrefix  = "http://schemas.google.com/blogger/2008/kind#"
+	LabelScheme = "http://www.blogger.com/atom/ns#"
+)
+
+// The kind of entry, e.g. post, page, comment, settings or template.
+func (e Entry) Kind() string {
+	for _, tag := range e.Tags {
+		if tag.Scheme == KindScheme {
+			return strings.TrimPrefix(tag.Name, KindPrefix)
+		}
+	}
+	return ""
+}
+
+type Tag struct {
+	Name   string `xml:"term,attr"`
+	Scheme string `xml:"scheme,attr"`
+}
+
+type Tags []Tag
+
+// The entry's labels, in the order Blogger lists them.
+func (t Tags) Labels() []string {
+	var names []string
+	for _, t := range t {
+		if t.Scheme == LabelScheme {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// The tags with their labels replaced by labels.
+func (t Tags) WithLabels(labels []string) Tags {
+	var with Tags
+	for _, t := range t {
+		if t.Scheme != LabelScheme {
+			with = append(with, t)
+		}
+	}
+	for _, l := range labels {
+		with = append(with, Tag{Name: l, Scheme: LabelScheme})
+	}
+	return with
+}
+
+// Atom text is escaped HTML or plain text, except type="xhtml", which is inline markup
+// inside a wrapping div.
+type Text struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+var xhtmlDiv = regexp.MustCompile(`(?s)^<(?:\w+:)?div\b[^>]*>(.*)</(?:\w+:)?div>$`)
+
+func (t Text) String() string {
+	if t.Type == "xhtml" {
+		inner := strings.TrimSpace(t.Inner)
+		if m := xhtmlDiv.FindStringSubmatch(inner); m != nil {
+			inner = strings.TrimSpace(m[1])
+		}
+		return inner
+	}
+	return t.Text
+}
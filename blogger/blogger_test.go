@@ -0,0 +1,67 @@
+package blogger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	pdt := time.FixedZone("", -7*60*60)
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"2014-05-19T23:36:00.000-07:00", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{"2014-05-19T23:36:00-07:00", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{"2014-05-19T23:36:00.123456Z", time.Date(2014, 5, 19, 23, 36, 0, 123456000, time.UTC), true},
+		{"2014-05-19t23:36:00z", time.Date(2014, 5, 19, 23, 36, 0, 0, time.UTC), true},
+		{"2014-05-19T23:36:00.000-0700", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{"2014-05-19T23:36-07:00", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{"2014-05-19T23:36:00", time.Date(2014, 5, 19, 23, 36, 0, 0, time.UTC), true},
+		{"2014-05-19 23:36:00 -0700", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{"Mon, 19 May 2014 23:36:00 -0700", time.Date(2014, 5, 19, 23, 36, 0, 0, pdt), true},
+		{" 2014-05-19 ", time.Date(2014, 5, 19, 0, 0, 0, 0, time.UTC), true},
+		// Missing, which isn't an error.
+		{"", time.Time{}, true},
+		{"yesterday", time.Time{}, false},
+		{"2014-13-45", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.in)
+		if ok != tt.ok || !time.Time(got).Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %t, want %v, %t", tt.in, time.Time(got), ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// An export of one post with the given draft flag and published date.
+func oddExport(draft, published string) string {
+	return `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:app="http://purl.org/atom/app#"><entry><id>tag:blogger.com,1999:blog-1.post-2</id>` +
+		`<published>` + published + `</published><app:control><app:draft>` + draft + `</app:draft></app:control><title>Odd</title></entry></feed>`
+}
+
+func TestParseOddValues(t *testing.T) {
+	var logged []string
+	p := &Parser{Logf: func(format string, v ...interface{}) { logged = append(logged, fmt.Sprintf(format, v...)) }}
+	exp, err := p.Parse(strings.NewReader(oddExport("true", "last week")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Entries) != 1 || !bool(exp.Entries[0].Draft) || !exp.Entries[0].Published.IsZero() {
+		t.Fatalf("got %+v, want one draft with no published date", exp.Entries)
+	}
+	if len(logged) != 2 {
+		t.Errorf("logged %q, want the draft flag and the date", logged)
+	}
+
+	p = &Parser{Strict: true}
+	_, err = p.Parse(strings.NewReader(oddExport("true", "2014-05-19T23:36:00.000-07:00")))
+	var ee *EntryError
+	if !errors.As(err, &ee) || !strings.Contains(err.Error(), `"true"`) {
+		t.Errorf("strict: got %v, want an entry error about the draft flag", err)
+	}
+}
@@ -0,0 +1,45 @@
+package blogger
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// A ParseError is an export that isn't well-formed XML, usually one cut short.
+type ParseError struct {
+	Line int // where it breaks off
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if _, ok := e.Err.(*xml.SyntaxError); ok {
+		// Syntax errors carry the line already.
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// An EntryError is an entry that couldn't be read or converted.
+type EntryError struct {
+	ID   string // the entry's ID, when it got that far
+	Line int    // where the entry starts in the export, when it came from one
+	Err  error
+}
+
+func (e *EntryError) Error() string {
+	switch {
+	case e.ID != "" && e.Line > 0:
+		return fmt.Sprintf("entry %s at line %d: %s", e.ID, e.Line, e.Err)
+	case e.ID != "":
+		return fmt.Sprintf("entry %s: %s", e.ID, e.Err)
+	}
+	return fmt.Sprintf("entry at line %d: %s", e.Line, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,287 @@
+package blogger
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// A Parser reads an export entry by entry, so it can be used on exports too large
+// to hold in memory.
+//
+// Exports that can't be trusted, e.g. uploaded by anyone, are best read with limits set.
+// Entities aren't a risk: only XML's predefined entities and character references are
+// expanded, and a document using any other fails to parse.
+type Parser struct {
+	// Skip entries that fail to decode, and end a truncated or malformed export
+	// at its last complete entry, instead of stopping at the first error.
+	Recover bool
+	// Fail entries with a value that can't be read, such as a draft flag that's
+	// neither yes nor no, instead of reading it with a default and reporting it.
+	Strict bool
+	// Called with each entry as it's decoded. Parse collects them when nil.
+	Entry func(e Entry) error
+	// Where problems skipped under Recover are reported; defaults to log.Printf.
+	Logf func(format string, v ...interface{})
+	// The most bytes an entry, or what's between two entries, can take up, and how
+	// deeply elements can nest. Zero means no limit. An export going over a limit
+	// fails to parse, even under Recover, with an error wrapping ErrLimit.
+	MaxEntrySize int64
+	MaxDepth     int
+}
+
+// ErrLimit is wrapped by the errors of an export that goes over a Parser's limits.
+var ErrLimit = errors.New("export goes over the parser's limits")
+
+// Parse reads a whole Blogger export.
+func Parse(r io.Reader) (*Export, error) {
+	return new(Parser).Parse(r)
+}
+
+// Parse reads an export, returning its entries unless p.Entry takes them. Errors
+// reading the export are a *ParseError or an *EntryError; an error from p.Entry
+// is returned as it is.
+func (p *Parser) Parse(r io.Reader) (*Export, error) {
+	var e Export
+	err := p.Decode(r, &e.Title, func(dec *xml.Decoder, start xml.StartElement) error {
+		var entry Entry
+		if err := dec.DecodeElement(&entry, &start); err != nil {
+			if _, broken := err.(*xml.SyntaxError); broken {
+				return err
+			}
+			return &EntryError{ID: entry.ID, Err: err}
+		}
+		if len(entry.problems) > 0 {
+			if p.Strict {
+				what := make([]string, len(entry.problems))
+				for i, pr := range entry.problems {
+					what[i] = pr.what
+				}
+				return &EntryError{ID: entry.ID, Err: errors.New(strings.Join(what, "; "))}
+			}
+			for _, pr := range entry.problems {
+				p.logf("Entry %s: %s; taken as %s", entry.ID, pr.what, pr.read)
+			}
+			entry.problems = nil
+		}
+		if p.Entry != nil {
+			if err := p.Entry(entry); err != nil {
+				return stop{err}
+			}
+			return nil
+		}
+		e.Entries = append(e.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (p *Parser) logf(format string, v ...interface{}) {
+	if p.Logf != nil {
+		p.Logf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
+// Decode the feed title and each <entry> of an Atom feed one at a time, passing entries
+// to fn. Without Recover the first error stops decoding; with it, entries that fail to
+// decode are skipped and reported, and a broken document ends decoding where it broke,
+// keeping every complete entry before it. A broken document is a *ParseError, and
+// other errors from fn come back as an *EntryError.
+func (p *Parser) Decode(r io.Reader, title *string, fn func(dec *xml.Decoder, start xml.StartElement) error) error {
+	logf := p.logf
+	limits := &limitReader{r: r, maxSize: p.MaxEntrySize, maxDepth: p.MaxDepth}
+	dec := xml.NewDecoder(limits)
+	depth, entries, skipped := 0, 0, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !p.Recover || errors.Is(err, ErrLimit) {
+				return &ParseError{Line: decoderLine(dec), Err: err}
+			}
+			logf("The export breaks off at line %d, after %d complete entries: %s", decoderLine(dec), entries, err)
+			break
+		}
+		switch se := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case se.Name.Local == "title" && depth == 2 && title != nil:
+				if err := dec.DecodeElement(title, &se); err != nil && !p.Recover {
+					return &ParseError{Line: decoderLine(dec), Err: err}
+				}
+				depth--
+			case se.Name.Local == "entry":
+				line := decoderLine(dec)
+				limits.read = 0
+				err := fn(dec, se)
+				limits.read = 0
+				if err != nil {
+					if s, ok := err.(stop); ok {
+						return s.err
+					}
+					if syntax, broken := err.(*xml.SyntaxError); broken {
+						if !p.Recover {
+							return &ParseError{Line: syntax.Line, Err: err}
+						}
+						logf("The export breaks off in the entry at line %d, after %d complete entries: %s", line, entries, err)
+						return nil
+					}
+					ee, ok := err.(*EntryError)
+					if !ok {
+						ee = &EntryError{Err: err}
+					}
+					ee.Line = line
+					if !p.Recover || errors.Is(err, ErrLimit) {
+						return ee
+					}
+					logf("Skipping the %s", ee)
+					skipped++
+					// The rest of a skipped entry is read through as ordinary tokens.
+					continue
+				}
+				entries++
+				depth--
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if skipped > 0 {
+		logf("Skipped %d entries that couldn't be read.", skipped)
+	}
+	return nil
+}
+
+// An error from Parser.Entry, which ends parsing as it is.
+type stop struct {
+	err error
+}
+
+func (s stop) Error() string {
+	return s.err.Error()
+}
+
+func decoderLine(dec *xml.Decoder) int {
+	l, _ := dec.InputPos()
+	return l
+}
+
+// Reads r, failing once more than maxSize bytes go by between entries or elements nest
+// more than maxDepth deep. Nesting is followed by a light scan of what's read, which
+// leaves anything malformed to the decoder.
+type limitReader struct {
+	r        io.Reader
+	maxSize  int64
+	read     int64
+	maxDepth int
+	depth    int
+	// Where the scan is, and the two bytes before.
+	state  scanState
+	quote  byte
+	p1, p2 byte
+}
+
+type scanState int
+
+const (
+	inText scanState = iota
+	afterLT
+	inStartTag
+	inEndTag
+	inQuote
+	afterBang
+	inComment
+	inCDATA
+	inDirective
+	inPI
+)
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.maxSize > 0 && l.read > l.maxSize {
+		return 0, fmt.Errorf("%w: more than %d bytes in one entry", ErrLimit, l.maxSize)
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.maxDepth > 0 {
+		for _, c := range p[:n] {
+			l.scan(c)
+			if l.depth > l.maxDepth {
+				return 0, fmt.Errorf("%w: elements nested more than %d deep", ErrLimit, l.maxDepth)
+			}
+		}
+	}
+	return n, err
+}
+
+func (l *limitReader) scan(c byte) {
+	switch l.state {
+	case inText:
+		if c == '<' {
+			l.state = afterLT
+		}
+	case afterLT:
+		switch c {
+		case '/':
+			l.depth--
+			l.state = inEndTag
+		case '!':
+			l.state = afterBang
+		case '?':
+			l.state = inPI
+		default:
+			l.depth++
+			l.state = inStartTag
+		}
+	case inStartTag:
+		switch c {
+		case '"', '\'':
+			l.quote, l.state = c, inQuote
+		case '>':
+			if l.p1 == '/' {
+				l.depth--
+			}
+			l.state = inText
+		}
+	case inQuote:
+		if c == l.quote {
+			l.state = inStartTag
+		}
+	case inEndTag, inDirective:
+		if c == '>' {
+			l.state = inText
+		}
+	case afterBang:
+		switch c {
+		case '-':
+			l.state = inComment
+		case '[':
+			l.state = inCDATA
+		default:
+			l.state = inDirective
+		}
+	case inComment:
+		if c == '>' && l.p1 == '-' && l.p2 == '-' {
+			l.state = inText
+		}
+	case inCDATA:
+		if c == '>' && l.p1 == ']' && l.p2 == ']' {
+			l.state = inText
+		}
+	case inPI:
+		if c == '>' && l.p1 == '?' {
+			l.state = inText
+		}
+	}
+	l.p2, l.p1 = l.p1, c
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+var bloggerAPI = flag.String("blogger-api", "", "fetch the blog with this URL or ID from the Blogger API instead of reading an export file; needs -api-key or -api-token")
+var apiKey = flag.String("api-key", "", "Google API key for -blogger-api; reads published posts, pages and comments only")
+var apiToken = flag.String("api-token", "", "OAuth 2.0 access token for -blogger-api, with the blogger scope; also reads drafts")
+var apiSince = flag.String("api-since", "", "with -blogger-api, only fetch posts published since this date (2006-01-02 or RFC 3339), for incremental migrations")
+
+const bloggerAPIBase = "https://www.googleapis.com/blogger/v3/"
+
+type apiBlog struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type apiAuthor struct {
+	DisplayName string `json:"displayName"`
+	URL         string `json:"url"`
+	Image       struct {
+		URL string `json:"url"`
+	} `json:"image"`
+}
+
+type apiPost struct {
+	ID        string    `json:"id"`
+	Published string    `json:"published"`
+	Updated   string    `json:"updated"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Author    apiAuthor `json:"author"`
+	Labels    []string  `json:"labels"`
+	Status    string    `json:"status"`
+}
+
+type apiComment struct {
+	ID        string    `json:"id"`
+	Published string    `json:"published"`
+	Updated   string    `json:"updated"`
+	Content   string    `json:"content"`
+	Author    apiAuthor `json:"author"`
+	Status    string    `json:"status"`
+	Post      struct {
+		ID string `json:"id"`
+	} `json:"post"`
+	InReplyTo struct {
+		ID string `json:"id"`
+	} `json:"inReplyTo"`
+}
+
+// Fetch a blog through the Blogger API v3 into the same shape as an export.
+func fetchBloggerAPI(ctx context.Context) (Export, error) {
+	if *apiKey == "" && *apiToken == "" {
+		return Export{}, fmt.Errorf("-blogger-api needs -api-key or -api-token")
+	}
+	var blog apiBlog
+	var err error
+	if strings.Contains(*bloggerAPI, "://") {
+		err = apiGet(ctx, "blogs/byurl", url.Values{"url": {*bloggerAPI}}, &blog)
+	} else {
+		err = apiGet(ctx, "blogs/"+url.PathEscape(*bloggerAPI), nil, &blog)
+	}
+	if err != nil {
+		return Export{}, err
+	}
+	exp := Export{Title: blog.Name}
+	if *pageViewsData != "" && *apiToken != "" {
+		if exp.PageViews, err = fetchPageViews(ctx, blog.ID); err != nil {
+			log.Printf("Failed fetching the blog's page views, leaving them out: %s", err)
+		}
+	}
+	prefix := "tag:blogger.com,1999:blog-" + blog.ID
+
+	statuses := []string{"live"}
+	if *apiToken != "" {
+		statuses = append(statuses, "draft", "scheduled")
+	}
+	for _, kind := range []string{"posts", "pages"} {
+		q := url.Values{"fetchBodies": {"true"}, "status": statuses}
+		if kind == "posts" {
+			q.Set("maxResults", "500")
+			q.Set("fetchImages", "false")
+			if *apiSince != "" {
+				since, err := apiDate(*apiSince)
+				if err != nil {
+					return Export{}, fmt.Errorf("-api-since: %s", err)
+				}
+				q.Set("startDate", since)
+			}
+		}
+		var posts []apiPost
+		if err := apiList(ctx, "blogs/"+blog.ID+"/"+kind, q, &posts); err != nil {
+			return Export{}, err
+		}
+		for _, p := range posts {
+			e := Entry{
+				Entry: blogger.Entry{
+					ID:        prefix + "." + strings.TrimSuffix(kind, "s") + "-" + p.ID,
+					Published: feedDate(p.Published, p.Updated),
+					Updated:   feedDate(p.Updated, p.Published),
+					Draft:     Draft(p.Status != "" && p.Status != "LIVE"),
+					Title:     p.Title,
+					Content:   p.Content,
+					Tags:      Tags{kindTag(strings.TrimSuffix(kind, "s"))},
+					Author:    p.Author.author(),
+				},
+			}
+			if p.URL != "" && p.Status != "DRAFT" {
+				e.Links = []Reply{{Rel: "alternate", Link: p.URL}}
+			}
+			for _, l := range p.Labels {
+				e.Tags = append(e.Tags, labelTag(l))
+			}
+			exp.Entries = append(exp.Entries, e)
+		}
+	}
+
+	posts := map[string]bool{}
+	for _, e := range exp.Entries {
+		posts[idNumber(e.ID)] = true
+	}
+	var comments []apiComment
+	if err := apiList(ctx, "blogs/"+blog.ID+"/comments", url.Values{"maxResults": {"500"}, "fetchBodies": {"true"}, "status": {"live"}}, &comments); err != nil {
+		return Export{}, err
+	}
+	for _, c := range comments {
+		if !posts[c.Post.ID] {
+			continue
+		}
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        prefix + ".post-" + c.ID,
+				Published: feedDate(c.Published, c.Updated),
+				Updated:   feedDate(c.Updated, c.Published),
+				Title:     c.Author.DisplayName,
+				Content:   c.Content,
+				Tags:      Tags{kindTag("comment")},
+				Author:    c.Author.author(),
+				Source:    Reply{Source: "api/" + c.Post.ID},
+			},
+		}
+		if c.InReplyTo.ID != "" {
+			e.Links = []Reply{{Rel: "related", Link: "api/" + c.InReplyTo.ID}}
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+func (a apiAuthor) author() Author {
+	return Author{Name: a.DisplayName, Uri: a.URL, Image: Image{Source: a.Image.URL}}
+}
+
+// The API wants RFC 3339 dates.
+func apiDate(s string) (string, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t.Format(time.RFC3339), err
+}
+
+// Fetch every page of a list endpoint, appending each page's items to items.
+func apiList(ctx context.Context, endpoint string, q url.Values, items interface{}) error {
+	all := []json.RawMessage{}
+	for {
+		var page struct {
+			Items         []json.RawMessage `json:"items"`
+			NextPageToken string            `json:"nextPageToken"`
+		}
+		if err := apiGet(ctx, endpoint, q, &page); err != nil {
+			return err
+		}
+		all = append(all, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		q.Set("pageToken", page.NextPageToken)
+	}
+	b, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, items)
+}
+
+func apiGet(ctx context.Context, endpoint string, q url.Values, v interface{}) error {
+	if q == nil {
+		q = url.Values{}
+	}
+	if *apiKey != "" {
+		q.Set("key", *apiKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", bloggerAPIBase+endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if *apiToken != "" {
+		req.Header.Set("Authorization", "Bearer "+*apiToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Blogger API %s: %s %s", endpoint, resp.Status, apiErr.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
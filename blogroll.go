@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var blogrollFile = flag.String("blogroll", "", "write the links of the theme's LinkList and BlogList gadgets as YAML to this file, relative to the target directory (e.g. ../../data/blogroll.yaml)")
+
+var (
+	widgetPattern        = regexp.MustCompile(`(?s)<b:widget\b([^>]*[^/])>(.*?)</b:widget>`)
+	widgetAttrPattern    = regexp.MustCompile(`(\w+)=(?:'([^']*)'|"([^"]*)")`)
+	widgetSettingPattern = regexp.MustCompile(`(?s)<b:widget-setting\s+name=(?:'([^']*)'|"([^"]*)")\s*>(.*?)</b:widget-setting>`)
+	// LinkList keeps its links as numbered pairs of settings, e.g. link-0 and text-0.
+	linkSettingPattern = regexp.MustCompile(`^(link|text|url|title)-(\d+)$`)
+)
+
+type blogrollLink struct {
+	Name, URL string
+}
+
+type blogroll struct {
+	Widget, Type, Title string
+	Links               []blogrollLink
+}
+
+// The link lists of the blog's sidebar gadgets, from the widget settings Blogger
+// keeps in the theme.
+func (c *Converter) blogrolls() []blogroll {
+	var lists []blogroll
+	for _, e := range c.exp.Entries {
+		if e.Kind() != "template" {
+			continue
+		}
+		for _, w := range widgetPattern.FindAllStringSubmatch(e.Content, -1) {
+			attrs := map[string]string{}
+			for _, a := range widgetAttrPattern.FindAllStringSubmatch(w[1], -1) {
+				attrs[a[1]] = html.UnescapeString(a[2] + a[3])
+			}
+			if attrs["type"] != "LinkList" && attrs["type"] != "BlogList" {
+				continue
+			}
+			links := map[int]*blogrollLink{}
+			for _, s := range widgetSettingPattern.FindAllStringSubmatch(w[2], -1) {
+				m := linkSettingPattern.FindStringSubmatch(s[1] + s[2])
+				if m == nil {
+					continue
+				}
+				n, _ := strconv.Atoi(m[2])
+				if links[n] == nil {
+					links[n] = &blogrollLink{}
+				}
+				value := strings.TrimSpace(html.UnescapeString(s[3]))
+				if m[1] == "link" || m[1] == "url" {
+					links[n].URL = value
+				} else {
+					links[n].Name = value
+				}
+			}
+			keys := make([]int, 0, len(links))
+			for n := range links {
+				keys = append(keys, n)
+			}
+			sort.Ints(keys)
+			list := blogroll{Widget: attrs["id"], Type: attrs["type"], Title: attrs["title"]}
+			for _, n := range keys {
+				if links[n].URL != "" {
+					list.Links = append(list.Links, *links[n])
+				}
+			}
+			if len(list.Links) > 0 {
+				lists = append(lists, list)
+			}
+		}
+	}
+	return lists
+}
+
+// Returns how many links were written.
+func (c *Converter) writeBlogroll() (int, error) {
+	f, err := c.create(path.Clean(filepath.ToSlash(*blogrollFile)))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, list := range c.blogrolls() {
+		fmt.Fprintf(f, "- widget: %q\n  type: %q\n  title: %q\n  links:\n", list.Widget, list.Type, list.Title)
+		for _, l := range list.Links {
+			if _, err := fmt.Fprintf(f, "    - name: %q\n      url: %q\n", l.Name, l.URL); err != nil {
+				f.Close()
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, f.Close()
+}
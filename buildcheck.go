@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var validateBuild = flag.Bool("validate-build", false, "once converted, build the site with hugo --renderToMemory and list the posts it fails on, e.g. for bad front matter or a shortcode the site doesn't have, before it's deployed")
+var hugoCommand = flag.String("hugo", "hugo", "with -validate-build, the hugo command to build with")
+
+// A file and position in Hugo's error messages, e.g. "/site/content/posts/x.md:3:1".
+var hugoErrorPos = regexp.MustCompile(`([^\s"'\x60]+\.(?:md|markdown|html|htm)):(\d+):(\d+)`)
+
+// Build the Hugo site the posts were converted into in dir, without writing it
+// anywhere, and report the posts and pages it fails on. Returns how many failed; a
+// build that fails elsewhere than in a converted file is an error.
+func (c *Converter) validateSiteBuild(ctx context.Context, dir string) (int, error) {
+	hugo, err := exec.LookPath(*hugoCommand)
+	if err != nil {
+		return 0, fmt.Errorf("-validate-build needs Hugo: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+	root, ok := c.siteRoot(absDir)
+	if !ok {
+		// Not in a site of its own: build the posts alone, in a bare site rendering only
+		// their content.
+		if root, err = bareSite(absDir); err != nil {
+			return 0, err
+		}
+		defer os.RemoveAll(root)
+	}
+	cmd := exec.CommandContext(ctx, hugo, "--renderToMemory", "--buildDrafts", "--buildFuture", "--source", root)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	runErr := cmd.Run()
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return 0, fmt.Errorf("-validate-build: %w", runErr)
+	}
+
+	// The converted files by where Hugo sees them.
+	entries := map[string]Entry{}
+	for _, e := range c.exp.Entries {
+		if e.Path == "" {
+			continue
+		}
+		full := filepath.Join(absDir, filepath.FromSlash(e.Path))
+		entries[full] = e
+		if real, err := filepath.EvalSymlinks(full); err == nil {
+			entries[real] = e
+		}
+	}
+	failed := map[string]bool{}
+	var reports []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		for _, m := range hugoErrorPos.FindAllStringSubmatch(line, -1) {
+			e, ok := entries[filepath.Clean(m[1])]
+			if !ok || failed[e.Path] {
+				continue
+			}
+			failed[e.Path] = true
+			reports = append(reports, fmt.Sprintf("%s (%s %q, %s), line %s: %s", e.Path, e.Kind(), e.Title, e.ID, m[2], strings.TrimSpace(line)))
+		}
+	}
+	if len(reports) > 0 {
+		log.Printf("Hugo failed to build %d converted posts and pages:", len(reports))
+		for _, r := range reports {
+			log.Printf("\t%s", r)
+		}
+		return len(reports), nil
+	}
+	if runErr != nil {
+		return 0, fmt.Errorf("-validate-build: hugo failed: %w\n%s", runErr, strings.TrimSpace(out.String()))
+	}
+	log.Printf("The site builds with Hugo.")
+	return 0, nil
+}
+
+// The root of the Hugo site absDir is in: the -hugo-site, or the directory holding
+// the content directory absDir is under, if it has a config file.
+func (c *Converter) siteRoot(absDir string) (string, bool) {
+	if *hugoSite != "" {
+		return *hugoSite, true
+	}
+	for d := absDir; ; {
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", false
+		}
+		if filepath.Base(d) == "content" {
+			for _, name := range append(siteConfigFiles, "hugo.yaml", "config.yaml") {
+				if _, err := os.Stat(filepath.Join(parent, filepath.FromSlash(name))); err == nil {
+					return parent, true
+				}
+			}
+			return "", false
+		}
+		d = parent
+	}
+}
+
+// A temporary Hugo site with content as its content directory and layouts that render
+// nothing but each page's content, for building posts that aren't in a site yet.
+func bareSite(content string) (string, error) {
+	root, err := os.MkdirTemp("", "blogger2hugo-site-*")
+	if err != nil {
+		return "", err
+	}
+	files := map[string]string{
+		"hugo.toml":                    fmt.Sprintf("baseURL = \"https://example.org/\"\ncontentDir = %q\ndisableKinds = [\"taxonomy\", \"term\", \"RSS\", \"sitemap\", \"robotsTXT\", \"404\"]\n", filepath.ToSlash(content)),
+		"layouts/_default/single.html": "{{ .Content }}",
+		"layouts/_default/list.html":   "{{ range .Pages }}{{ .Content }}{{ end }}",
+	}
+	for name, text := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err == nil {
+			err = os.WriteFile(full, []byte(text), 0644)
+		}
+		if err != nil {
+			os.RemoveAll(root)
+			return "", err
+		}
+	}
+	return root, nil
+}
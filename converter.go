@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+// A Converter holds what one conversion works on and finds out as it goes: the export,
+// where its files go, how slugs, redirects and sections are made, and what's been
+// written so far.
+type Converter struct {
+	exp Export
+	t   *template.Template
+	out WriteFS
+	// The static site generator converted for, from -target.
+	target Target
+	// Front matter added to every post, from -extra.
+	extra string
+	// How slugs are made, and whether from the Blogger URL (blogger) or the title.
+	slug       hugo.SlugOptions
+	slugSource string
+	// New hosts for links to old ones, by normalized old host.
+	domainRewrites map[string]string
+	// The format of the redirects written, if any, and the file, relative to the
+	// target directory, if not the format's usual one.
+	redirectsFormat, redirectsFile string
+	// How posts are split into date sections: year, month, or "" for not at all.
+	sections string
+
+	// The URL path the target directory is served under, worked out from where it sits
+	// in the Hugo site. E.g. mysite/content/posts -> /posts
+	urlPrefix string
+	// Front matter key Blogger labels are written under, from the site's taxonomies.
+	tagsKey string
+	// The site's permalink pattern for the section being converted into, e.g. /:year/:month/:slug/
+	permalinkPattern string
+	// Whether to write a slug key into the front matter, for permalink patterns that use it.
+	slugInFrontMatter bool
+	// The blog's main language, and every language posts were found in, under -multilingual.
+	language  string
+	languages []string
+
+	// Filenames already used, keyed case-insensitively since NTFS and APFS treat names
+	// differing only in case as the same file.
+	claimed map[string]Entry
+	// Slugs given to posts whose titles had nothing usable in them, for the final report.
+	fallbacks []string
+	mappings  []urlMapping
+	// Newest post date seen for each section directory, relative to the target directory.
+	sectionDates map[string]Date
+	// Author names by the directory key made from them, for section titles.
+	authorNames map[string]string
+	// Converted posts and pages by their normalized Blogger URL.
+	linkTargets map[string]Entry
+	// Hosts the blog was served from, normalized.
+	blogHosts map[string]bool
+	// Internal links that didn't match any converted entry, for the final report.
+	unresolvedLinks []string
+	// HTTP status (or error) of each external link checked, by URL.
+	linkStatus    map[string]string
+	externalLinks []externalLink
+	// Bundled media already copied to the output.
+	copiedMedia map[string]bool
+	mediaMu     sync.Mutex
+	// The images downloaded under -images, by bundle directory, then original URL.
+	bundledImages map[string]map[string]*bundledImage
+	// The posts and pages for the data file, under -content-adapter.
+	adapterPages []adapterPage
+	// Time spent in each stage, for -timings.
+	timings timings
+}
+
+// A Converter writing into the current directory for -target, with its front matter.
+func newConverter() *Converter {
+	c := &Converter{
+		out:            DirFS("."),
+		urlPrefix:      "/",
+		tagsKey:        "tags",
+		claimed:        map[string]Entry{},
+		sectionDates:   map[string]Date{},
+		authorNames:    map[string]string{},
+		linkTargets:    map[string]Entry{},
+		blogHosts:      map[string]bool{},
+		linkStatus:     map[string]string{},
+		copiedMedia:    map[string]bool{},
+		slugSource:     "blogger",
+		domainRewrites: map[string]string{},
+	}
+	c.target = targets[*target]
+	c.setTemplate(c.target.Template())
+	return c
+}
+
+func (c *Converter) setTemplate(text string) {
+	c.t = template.Must(template.New("").Funcs(c.templateFuncs()).Parse(text))
+}
+
+func (c *Converter) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"quoteList":         hugo.QuoteList,
+		"authors":           authorsFrontMatter,
+		"extras":            extrasFrontMatter,
+		"tagsKey":           func() string { return c.tagsKey },
+		"featuredKey":       func() string { return *featuredKey },
+		"slugInFrontMatter": func() bool { return c.slugInFrontMatter },
+		"liquidRaw":         liquidRaw,
+		"join":              strings.Join,
+		"pelicanValue":      pelicanValue,
+		"noteBody":          c.noteBody,
+		"noteComments":      c.noteComments,
+		"noteTags":          noteTags,
+		"zolaComments":      c.zolaComments,
+		"permalink":         func(e Entry) string { return c.permalink(e, e.Path) },
+		"hexoPermalink":     func(e Entry) string { return strings.TrimPrefix(c.permalink(e, e.Path), "/") },
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var missingDate = flag.String("missing-date", "", "the date to give posts, pages and comments with neither a published nor an updated date that can be read, when the export doesn't say when it was made either, as 2006-01-02 or 2006-01-02T15:04:05Z07:00 (default the time of the run)")
+
+// When the export was made, as Blogger dates the blog's settings in it, or the zero
+// date for exports without them.
+func (c *Converter) exportDate() Date {
+	var latest time.Time
+	for _, e := range c.exp.Entries {
+		if e.Kind() == "settings" && time.Time(e.Updated).After(latest) {
+			latest = time.Time(e.Updated)
+		}
+	}
+	return Date(latest)
+}
+
+// The -missing-date, or the time of the run. checkFlags makes sure it's one of the
+// layouts.
+func missingDateValue() (Date, error) {
+	if *missingDate == "" {
+		return Date(time.Now().UTC().Truncate(time.Second)), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, *missingDate); err == nil {
+			return Date(t), nil
+		}
+	}
+	return Date{}, fmt.Errorf("Bad -missing-date: %s, expected a date such as 2006-01-02", *missingDate)
+}
+
+// Fill in the dates of the posts, pages and comments that are missing one, or had one
+// that couldn't be read: the published date from the updated, the updated from the
+// published, and failing both, the date of the export, or the -missing-date. Returns a
+// description of each.
+func (c *Converter) fillDates() []string {
+	var exported, fallback Date
+	var found []string
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		kind := e.Kind()
+		if kind != "post" && kind != "page" && kind != "comment" {
+			continue
+		}
+		if !e.Published.IsZero() && !e.Updated.IsZero() {
+			continue
+		}
+		var from string
+		switch {
+		case !e.Updated.IsZero():
+			e.Published, from = e.Updated, "published date from its updated date"
+		case !e.Published.IsZero():
+			e.Updated, from = e.Published, "updated date from its published date"
+		default:
+			if exported.IsZero() {
+				exported = c.exportDate()
+			}
+			if !exported.IsZero() {
+				e.Published, e.Updated, from = exported, exported, "dates from the export's"
+				break
+			}
+			if fallback.IsZero() {
+				fallback, _ = missingDateValue()
+			}
+			e.Published, e.Updated, from = fallback, fallback, "dates from -missing-date"
+		}
+		what := fmt.Sprintf("%s %q", kind, e.Title)
+		if kind == "comment" {
+			what = fmt.Sprintf("comment by %q", strings.TrimSpace(e.Author.Name))
+		}
+		found = append(found, fmt.Sprintf("%s (%s): %s, %s", what, e.ID, from, e.Published))
+	}
+	return found
+}
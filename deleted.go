@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var deleted = flag.String("deleted", "skip", "what to do with posts in Blogger's trash or deleted, and the placeholders left for removed posts and comments: skip to leave them out, or keep to convert them, posts and pages as drafts")
+
+// What Blogger leaves in place of a post or comment that's been removed.
+var removedPlaceholder = regexp.MustCompile(`(?i)^(?:\s|<[^>]*>)*this (?:post|comment) has been removed(?: by (?:the author|a blog administrator))?\.?(?:\s|<[^>]*>)*$`)
+
+// Why an entry looks deleted, or "" if it doesn't: it's in the trash or deleted, a
+// placeholder for a removed post or comment, or a post with neither a title nor content.
+func deletedReason(e Entry) string {
+	if e.Deleted != "" {
+		return e.Deleted
+	}
+	content := entryContent(e)
+	if removedPlaceholder.MatchString(content) {
+		return "removed"
+	}
+	if kind := e.Kind(); (kind == "post" || kind == "page") && strings.TrimSpace(e.Title) == "" && strings.TrimSpace(content) == "" {
+		return "empty, without a title or content"
+	}
+	return ""
+}
+
+// Mark the posts, pages and comments that look deleted, and apply -deleted to them.
+// Returns a description of each.
+func (c *Converter) markDeleted() []string {
+	var found []string
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		kind := e.Kind()
+		if kind != "post" && kind != "page" && kind != "comment" {
+			continue
+		}
+		if e.Deleted = deletedReason(*e); e.Deleted == "" {
+			continue
+		}
+		found = append(found, fmt.Sprintf("%s %q (%s): %s", kind, e.Title, e.ID, e.Deleted))
+		if *deleted == "keep" && kind != "comment" {
+			e.Draft = true
+		}
+	}
+	return found
+}
+
+// Whether a deleted entry is left out of the conversion.
+func skipDeleted(e Entry) bool {
+	return e.Deleted != "" && *deleted == "skip"
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestDeletedReason(t *testing.T) {
+	trashed := entryOfKind("post", "Hello", "<p>Hi</p>")
+	trashed.Deleted = "in the trash"
+	tests := []struct {
+		name string
+		e    Entry
+		want string
+	}{
+		{"post", entryOfKind("post", "Hello", "<p>Hi</p>"), ""},
+		{"trashed", trashed, "in the trash"},
+		{"removed post", entryOfKind("post", "Hello", "This post has been removed by the author."), "removed"},
+		{"removed comment", entryOfKind("comment", "", "<p>This comment has been removed by a blog administrator.</p>"), "removed"},
+		{"removed, quoted", entryOfKind("comment", "", "He said this comment has been removed."), ""},
+		{"empty post", entryOfKind("post", " ", "\n"), "empty, without a title or content"},
+		{"empty page", entryOfKind("page", "", ""), "empty, without a title or content"},
+		{"untitled post", entryOfKind("post", "", "<p>Hi</p>"), ""},
+		{"empty comment", entryOfKind("comment", "", ""), ""},
+	}
+	for _, tt := range tests {
+		if got := deletedReason(tt.e); got != tt.want {
+			t.Errorf("%s: deletedReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
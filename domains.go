@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var rewriteDomainsFlag = flag.String("rewrite-domains", "", "comma separated old=new domain rewrites for links in posts and comments, e.g. oldblog.blogspot.com=example.com; new can be direct, which follows the link's redirect (for feedproxy.google.com)")
+
+// Read -rewrite-domains into the new host for each normalized old host.
+func parseDomainRewrites(s string) (map[string]string, error) {
+	rewrites := map[string]string{}
+	for _, rule := range strings.Split(s, ",") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(rule, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("expected old=new, got %q", rule)
+		}
+		rewrites[normalizeHost(from)] = to
+	}
+	return rewrites, nil
+}
+
+// Apply the domain rewrites to the links of every entry, so custom domain moves and
+// feed proxies are resolved before internal links are matched or checked.
+func (c *Converter) rewriteDomains(ctx context.Context) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	resolved := map[string]string{}
+	failed := 0
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		setContent(e, linkAttrPattern.ReplaceAllStringFunc(entryContent(*e), func(attr string) string {
+			m := linkAttrPattern.FindStringSubmatch(attr)
+			raw := linkAttrURL(m)
+			u, err := url.Parse(raw)
+			if err != nil || u.Host == "" {
+				return attr
+			}
+			to, ok := c.domainRewrites[normalizeHost(u.Host)]
+			if !ok {
+				return attr
+			}
+			var dest string
+			if to == "direct" {
+				if _, done := resolved[raw]; !done {
+					resolved[raw] = resolveRedirect(ctx, client, raw)
+					if resolved[raw] == "" {
+						failed++
+					}
+				}
+				if dest = resolved[raw]; dest == "" {
+					return attr
+				}
+			} else {
+				if scheme, host, ok := strings.Cut(to, "://"); ok {
+					u.Scheme, u.Host = scheme, host
+				} else {
+					u.Host = to
+				}
+				dest = u.String()
+			}
+			return strings.Replace(attr, m[1][1:len(m[1])-1], html.EscapeString(dest), 1)
+		}))
+	}
+	if failed > 0 {
+		log.Printf("%d links could not be followed to their destination and were left as they were.", failed)
+	}
+}
+
+// Where a link ends up after following its redirects, or "" if it can't be followed.
+func resolveRedirect(ctx context.Context, client *http.Client, u string) string {
+	resp, err := request(ctx, client, "HEAD", u)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 || resp.Request.URL.String() == u {
+		return ""
+	}
+	return resp.Request.URL.String()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var duplicates = flag.String("duplicates", "warn", "what to do with posts and pages published twice, with the same title and body, as Blogger sometimes does: warn to list them and convert every copy, or skip to convert only the newest, with the comments of all of them, and send the others' URLs to it")
+
+// What two copies of a post have the same: its kind, and its title and body as text,
+// whatever the case and spacing.
+func duplicateKey(e Entry) string {
+	body := html.UnescapeString(tagPattern.ReplaceAllString(entryContent(e), " "))
+	if strings.TrimSpace(body) == "" {
+		// Only images or embeds, which the markup tells apart.
+		body = entryContent(e)
+	}
+	norm := func(s string) string { return strings.ToLower(strings.Join(strings.Fields(s), " ")) }
+	sum := sha256.Sum256([]byte(norm(body)))
+	return e.Kind() + "\x00" + norm(e.Title) + "\x00" + string(sum[:])
+}
+
+// Find the posts and pages that are copies of another, and under -duplicates=skip mark
+// all but one of each as a Duplicate of the one converted: a published copy rather than
+// a draft, and of those the newest. Returns a description of each copy, and the
+// entries skipped by the one they're a copy of.
+func (c *Converter) markDuplicates() ([]string, map[int]int) {
+	copies := map[string][]int{}
+	var keys []string
+	for k, e := range c.exp.Entries {
+		kind := e.Kind()
+		if (kind != "post" && kind != "page") || skipDeleted(e) || strings.TrimSpace(e.Title+entryContent(e)) == "" {
+			continue
+		}
+		key := duplicateKey(e)
+		if copies[key] == nil {
+			keys = append(keys, key)
+		}
+		copies[key] = append(copies[key], k)
+	}
+	dated := func(e Entry) string {
+		if e.Draft {
+			return "a draft of " + e.Published.String()
+		}
+		return "published " + e.Published.String()
+	}
+	var found []string
+	kept := map[int]int{}
+	for _, key := range keys {
+		ks := copies[key]
+		if len(ks) < 2 {
+			continue
+		}
+		keep := ks[0]
+		for _, k := range ks[1:] {
+			e, best := c.exp.Entries[k], c.exp.Entries[keep]
+			if best.Draft != e.Draft {
+				if best.Draft {
+					keep = k
+				}
+				continue
+			}
+			if !time.Time(e.Published).Before(time.Time(best.Published)) {
+				keep = k
+			}
+		}
+		best := c.exp.Entries[keep]
+		for _, k := range ks {
+			if k == keep {
+				continue
+			}
+			e := &c.exp.Entries[k]
+			found = append(found, fmt.Sprintf("%s %q (%s), %s, a copy of %s, %s", e.Kind(), e.Title, e.ID, dated(*e), best.ID, dated(best)))
+			if *duplicates == "skip" {
+				e.Duplicate = best.ID
+				kept[k] = keep
+			}
+		}
+	}
+	return found, kept
+}
+
+// Send the Blogger URLs of the copies left out to the one converted, in the redirects
+// and in links from other posts.
+func (c *Converter) redirectDuplicates(kept map[int]int) {
+	ks := make([]int, 0, len(kept))
+	for k := range kept {
+		ks = append(ks, k)
+	}
+	sort.Ints(ks)
+	for _, k := range ks {
+		e, best := c.exp.Entries[k], c.exp.Entries[kept[k]]
+		if e.URL == "" || best.Path == "" || e.URL == best.URL {
+			continue
+		}
+		c.mappings = append(c.mappings, urlMapping{
+			Kind:      e.Kind(),
+			ID:        e.ID,
+			Blogger:   e.URL,
+			Path:      best.Path,
+			Permalink: c.permalink(best, best.Path),
+		})
+		if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+			c.blogHosts[normalizeHost(u.Host)] = true
+			c.linkTargets[linkKey(u)] = best
+		}
+	}
+}
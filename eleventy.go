@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Eleventy's posts: posts/YYYY-MM-DD-slug.md in the site's input directory, each
+// tagged posts for the collection, and served at its Blogger URL through permalink.
+// Its front matter is YAML, and comments go into _data/comments.
+type eleventyTarget struct{}
+
+var eleventyTempl = `---
+title: "{{ .Title }}"
+date: {{ .Published }}
+updated: {{ .Updated }}
+tags: [{{ if eq .Kind "post" }}"posts"{{ end }}{{ with quoteList .Tags.Labels }}{{ if eq $.Kind "post" }}, {{ end }}{{ . }}{{ end }}]{{ if .Draft }}
+draft: true
+permalink: false
+eleventyExcludeFromCollections: true{{ else }}
+permalink: "{{ permalink . }}"{{ if .Unlisted }}
+eleventyExcludeFromCollections: true{{ end }}{{ end }}{{ if .Featured }}
+{{ featuredKey }}: true{{ end }}{{ with .Views }}
+views: {{ . }}{{ end }}{{ with .BloggerID }}
+blogger_id: "{{ . }}"{{ end }}{{ with .URL }}
+blogger_url: "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }}: {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related: [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings: [{{ . }}]{{ end }}{{ with extras . }}
+{{ . }}{{ end }}{{ with .Extra }}
+{{.}}{{ end }}
+author: "{{ .Author.Name }}"{{ with .Enclosure }}
+enclosure:
+  url: "{{ .URL }}"{{ if .Length }}
+  length: {{ .Length }}{{ end }}
+  type: "{{ .Type }}"{{ end }}
+---
+
+{{ liquidRaw .Content }}
+`
+
+func (eleventyTarget) Template() string { return eleventyTempl }
+
+func (eleventyTarget) PlacePost(c *Converter, e *Entry) error {
+	date := e.Published.String()[:10]
+	name, err := c.claimPath("posts", date+"-"+c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = strings.TrimPrefix(name, date+"-")
+	e.Path = path.Join("posts", name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (eleventyTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := path.Clean(filepath.ToSlash(*pagesDir))
+	name, err := c.claimPath(dir, c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// The post's Blogger URL, which its permalink keeps, or failing one where Eleventy
+// puts it by default: /posts/hello/ for posts/2014-05-19-hello.md.
+func (eleventyTarget) Permalink(c *Converter, e Entry, name string) string {
+	if p := bloggerPath(e.URL); p != "" && p != "/" {
+		return p
+	}
+	dir, base := path.Split(strings.TrimSuffix(name, path.Ext(name)))
+	base = strings.TrimPrefix(base, e.Published.String()[:10]+"-")
+	if base == "index" {
+		return "/" + dir
+	}
+	return "/" + path.Join(dir, base) + "/"
+}
+
+// Comments are written by post once the posts have their slugs, see WriteSite.
+func (eleventyTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+// Write each post's comments, oldest thread first, into _data/comments/<slug>.json,
+// which templates find as comments[page.fileSlug].
+func (eleventyTarget) WriteSite(c *Converter) error {
+	var errs []error
+	for k, post := range c.exp.Entries {
+		if post.Path == "" || len(post.Children) == 0 {
+			continue
+		}
+		if err := c.writeJSON(path.Join("_data", "comments", postFileSlug(post)+".json"), c.dataComments(k)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write v into name as indented JSON.
+func (c *Converter) writeJSON(name string, v interface{}) error {
+	f, err := c.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	err = enc.Encode(v)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
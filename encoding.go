@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var xmlDeclEncoding = regexp.MustCompile(`^(<\?xml[^>]*encoding\s*=\s*["'])([^"']+)(["'])`)
+
+// How much of an export is looked at to tell its encoding and format.
+const sniffLen = 4096
+
+// Make an export UTF-8 as it's read, which is all the parsers read. UTF-16 and the legacy
+// 8-bit encodings old exports declare are transcoded; bytes in a UTF-8 export that aren't
+// valid UTF-8, usually Windows-1252 pasted into posts, are decoded as Windows-1252 and reported.
+func utf8Reader(name string, r io.Reader) io.Reader {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, _ := br.Peek(sniffLen)
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		// Zips are read as they are, their files are converted as they're parsed.
+		return br
+	}
+	if bytes.HasPrefix(head, []byte{0xff, 0xfe}) || bytes.HasPrefix(head, []byte{0xfe, 0xff}) {
+		log.Printf("%s is UTF-16, converting it to UTF-8.", name)
+		return setXMLEncoding(unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Reader(br))
+	}
+	if bytes.HasPrefix(head, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+		head = head[3:]
+	}
+	if m := xmlDeclEncoding.FindSubmatch(head); m != nil {
+		if cm := legacyCharmap(strings.ToLower(string(m[2]))); cm != nil {
+			log.Printf("%s is %s, converting it to UTF-8.", name, m[2])
+			return setXMLEncoding(cm.NewDecoder().Reader(br))
+		}
+	}
+	return &repairReader{name: name, r: br}
+}
+
+// The single-byte encodings old exports declare. Latin-1 is read as Windows-1252, as browsers
+// (and Blogger's editor) always have, since its C1 controls are really curly quotes and dashes.
+func legacyCharmap(enc string) *charmap.Charmap {
+	switch enc {
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252", "us-ascii":
+		return charmap.Windows1252
+	case "iso-8859-2", "latin2":
+		return charmap.ISO8859_2
+	case "iso-8859-15", "latin9":
+		return charmap.ISO8859_15
+	case "windows-1250", "cp1250":
+		return charmap.Windows1250
+	case "windows-1251", "cp1251":
+		return charmap.Windows1251
+	case "koi8-r":
+		return charmap.KOI8R
+	}
+	return nil
+}
+
+// Point the XML declaration at the encoding the document is now in.
+func setXMLEncoding(r io.Reader) io.Reader {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, _ := br.Peek(sniffLen)
+	loc := xmlDeclEncoding.FindIndex(head)
+	if loc == nil {
+		return br
+	}
+	decl := xmlDeclEncoding.ReplaceAll(head[:loc[1]], []byte("${1}UTF-8${3}"))
+	br.Discard(loc[1])
+	return io.MultiReader(bytes.NewReader(decl), br)
+}
+
+// Passes UTF-8 through, reading any byte that isn't as Windows-1252, and warns about
+// those bytes once the export has been read.
+type repairReader struct {
+	name    string
+	r       io.Reader
+	buf     []byte
+	in, out []byte
+	pos     int
+	bad     int
+	offsets []string
+	eof     bool
+}
+
+func (rr *repairReader) Read(p []byte) (int, error) {
+	for len(rr.out) == 0 {
+		if rr.eof {
+			return 0, io.EOF
+		}
+		if rr.buf == nil {
+			rr.buf = make([]byte, 32<<10)
+		}
+		n, err := rr.r.Read(rr.buf)
+		rr.in = append(rr.in, rr.buf[:n]...)
+		if err == io.EOF {
+			rr.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+		rr.repair()
+	}
+	n := copy(p, rr.out)
+	rr.out = rr.out[n:]
+	return n, nil
+}
+
+func (rr *repairReader) repair() {
+	i := 0
+	if utf8.Valid(rr.in) {
+		rr.out = append(rr.out, rr.in...)
+		i = len(rr.in)
+	}
+	for i < len(rr.in) {
+		if !rr.eof && !utf8.FullRune(rr.in[i:]) {
+			// The rest of the rune is still to be read.
+			break
+		}
+		r, size := utf8.DecodeRune(rr.in[i:])
+		if r == utf8.RuneError && size == 1 {
+			if rr.bad < 5 {
+				rr.offsets = append(rr.offsets, fmt.Sprint(rr.pos+i))
+			}
+			rr.bad++
+			rr.out = utf8.AppendRune(rr.out, charmap.Windows1252.DecodeByte(rr.in[i]))
+		} else {
+			rr.out = append(rr.out, rr.in[i:i+size]...)
+		}
+		i += size
+	}
+	rr.in = append(rr.in[:0], rr.in[i:]...)
+	rr.pos += i
+	if rr.eof && rr.bad > 0 {
+		more := ""
+		if rr.bad > len(rr.offsets) {
+			more = ", ..."
+		}
+		log.Printf("Warning: %s has %d bytes that aren't UTF-8 (at byte %s%s); they were read as Windows-1252, check those posts for garbled characters.", rr.name, rr.bad, strings.Join(rr.offsets, ", "), more)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A post or page as compared between exports, with hashes of what can be edited.
+type exportedPost struct {
+	kind, id, title, url string
+	published            time.Time
+	content, labels      [sha256.Size]byte
+}
+
+func (p exportedPost) String() string {
+	s := fmt.Sprintf("%s %q (%s)", p.kind, p.title, p.id)
+	if p.url != "" {
+		s += " " + p.url
+	}
+	return s
+}
+
+// The posts and pages of the export name, by ID, leaving out those in the trash or
+// deleted, which are gone from the blog as far as its readers are concerned.
+func exportedPosts(ctx context.Context, name string) (map[string]exportedPost, error) {
+	exp, err := loadExports(ctx, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	defer exp.Close()
+	posts := map[string]exportedPost{}
+	for _, e := range exp.Entries {
+		kind := e.Kind()
+		if (kind != "post" && kind != "page") || deletedReason(e) != "" {
+			continue
+		}
+		p := exportedPost{
+			kind: kind, id: e.ID, title: e.Title,
+			published: time.Time(e.Published),
+			content:   sha256.Sum256([]byte(entryContent(e))),
+			labels:    sha256.Sum256([]byte(strings.Join(e.Tags.Labels(), "\x00"))),
+		}
+		for _, l := range e.Links {
+			if strings.EqualFold(l.Rel, "alternate") {
+				p.url = l.Link
+			}
+		}
+		posts[e.ID] = p
+	}
+	return posts, nil
+}
+
+// Report the posts and pages added, edited and deleted between the exports oldName and
+// newName to w, a line each, oldest first: + for those added, - for those deleted, and
+// ~ for those edited, with what was. Returns how many differ.
+func diffExports(ctx context.Context, oldName, newName string, w io.Writer) (int, error) {
+	before, err := exportedPosts(ctx, oldName)
+	if err != nil {
+		return 0, err
+	}
+	after, err := exportedPosts(ctx, newName)
+	if err != nil {
+		return 0, err
+	}
+
+	type change struct {
+		post exportedPost
+		line string
+	}
+	var changes []change
+	added, edited, removed := 0, 0, 0
+	for id, p := range after {
+		old, ok := before[id]
+		if !ok {
+			changes = append(changes, change{p, "+ " + p.String()})
+			added++
+			continue
+		}
+		var what []string
+		if p.title != old.title {
+			what = append(what, "title")
+		}
+		if p.content != old.content {
+			what = append(what, "content")
+		}
+		if p.labels != old.labels {
+			what = append(what, "labels")
+		}
+		if p.url != old.url {
+			what = append(what, "URL")
+		}
+		if len(what) > 0 {
+			changes = append(changes, change{p, "~ " + p.String() + ": " + strings.Join(what, ", ")})
+			edited++
+		}
+	}
+	for id, p := range before {
+		if _, ok := after[id]; !ok {
+			changes = append(changes, change{p, "- " + p.String()})
+			removed++
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if a, b := changes[i].post.published, changes[j].post.published; !a.Equal(b) {
+			return a.Before(b)
+		}
+		return changes[i].post.id < changes[j].post.id
+	})
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c.line); err != nil {
+			return 0, err
+		}
+	}
+	log.Printf("%d posts and pages added, %d edited and %d deleted since %s.", added, edited, removed, oldName)
+	return len(changes), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+// Prefixes for the namespaces of extension elements, as they're usually written.
+var extensionPrefixes = map[string]string{
+	"http://www.georss.org/georss":             "georss",
+	"http://www.w3.org/2003/01/geo/wgs84_pos#": "geo",
+	"http://purl.org/dc/elements/1.1/":         "dc",
+	"http://purl.org/syndication/thread/1.0":   "thr",
+	"http://search.yahoo.com/mrss/":            "media",
+	"http://schemas.google.com/g/2005":         "gd",
+	"http://purl.org/atom/app#":                "app",
+	"http://www.w3.org/2007/app":               "app",
+	"http://www.w3.org/2005/Atom":              "",
+}
+
+// Extension elements Blogger keeps up to date itself, which say nothing the converted
+// post doesn't: its comment count, the thumbnail of its first image, when it was edited.
+var ignoredExtensions = map[string]bool{
+	"thr:total":           true,
+	"media:thumbnail":     true,
+	"app:edited":          true,
+	"gd:extendedProperty": true,
+}
+
+// The key a post's extension element is written under in blogger_extras, e.g.
+// georss:point.
+func extensionKey(name xml.Name) string {
+	if prefix := extensionPrefixes[name.Space]; prefix != "" {
+		return prefix + ":" + name.Local
+	}
+	return name.Local
+}
+
+// The value of an extension element: its text, or failing that its attribute, or its
+// attributes as name=value.
+func extensionValue(x Extension) string {
+	text := strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(x.Inner, " "))), " ")
+	if text != "" {
+		return text
+	}
+	var attrs []xml.Attr
+	for _, a := range x.Attrs {
+		if a.Name.Space != "xmlns" && a.Name.Local != "xmlns" {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) == 1 {
+		return attrs[0].Value
+	}
+	pairs := make([]string, len(attrs))
+	for i, a := range attrs {
+		pairs[i] = a.Name.Local + "=" + a.Value
+	}
+	return strings.Join(pairs, " ")
+}
+
+// A post's extension elements as blogger_extras front matter, or "" if it has none.
+// Elements repeated, such as categories, are listed.
+func extrasFrontMatter(e Entry) string {
+	toml := *format == "toml" || *target == "zola"
+	values := map[string][]string{}
+	for _, x := range e.Extensions {
+		key := extensionKey(x.XMLName)
+		if ignoredExtensions[key] {
+			continue
+		}
+		if v := extensionValue(x); v != "" {
+			values[key] = append(values[key], v)
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	if *target == "zola" {
+		b.WriteString("[extra.blogger_extras]")
+	} else if toml {
+		b.WriteString("[blogger_extras]")
+	} else {
+		b.WriteString("blogger_extras:")
+	}
+	for _, k := range keys {
+		v := fmt.Sprintf("%q", values[k][0])
+		if len(values[k]) > 1 {
+			v = "[" + hugo.QuoteList(values[k]) + "]"
+		}
+		if toml {
+			fmt.Fprintf(&b, "\n\t%q = %s", k, v)
+		} else {
+			fmt.Fprintf(&b, "\n  %q: %s", k, v)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"regexp"
+)
+
+var featuredKey = flag.String("featured", "featured", "front matter key set to true on the post the blog's Featured Post gadget showed, for a theme's hero section; empty to leave it out")
+
+// The Featured Post gadget in a Blogger theme, and the post it's set to show.
+var (
+	featuredWidget  = regexp.MustCompile(`(?s)<b:widget\b[^>]*\btype=['"]FeaturedPost['"][^>]*>(.*?)</b:widget>`)
+	featuredSetting = regexp.MustCompile(`<b:widget-setting\s+name=['"]postId['"]\s*>\s*(\d+)\s*</b:widget-setting>`)
+)
+
+// Mark the posts the theme's Featured Post gadgets show, going by their settings in
+// the theme and settings entries. A gadget set to show the latest post names none.
+func (c *Converter) markFeatured() {
+	if *featuredKey == "" {
+		return
+	}
+	ids := map[string]bool{}
+	for _, e := range c.exp.Entries {
+		if kind := e.Kind(); kind != "template" && kind != "settings" {
+			continue
+		}
+		for _, w := range featuredWidget.FindAllStringSubmatch(entryContent(e), -1) {
+			if m := featuredSetting.FindStringSubmatch(w[1]); m != nil {
+				ids[m[1]] = true
+			}
+		}
+	}
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if e.Kind() == "post" && ids[e.ID] {
+			e.Featured = true
+			log.Printf("%q is marked %s, as the Featured Post gadget showed it.", e.Title, *featuredKey)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// A plain Atom feed, from any platform. Only what a feed carries comes across:
+// there are no comments, drafts or pages, and long feeds are often truncated.
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string   `xml:"id"`
+	Title      string   `xml:"title"`
+	Published  string   `xml:"published"`
+	Updated    string   `xml:"updated"`
+	Content    atomText `xml:"content"`
+	Summary    atomText `xml:"summary"`
+	Author     Author   `xml:"author"`
+	Links      []Reply  `xml:"link"`
+	Categories []Tag    `xml:"category"`
+}
+
+type atomText = blogger.Text
+
+type rssFeed struct {
+	Title string    `xml:"channel>title"`
+	Items []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+}
+
+type feedImporter struct{}
+
+// Any Atom or RSS feed; the more specific importers are tried first.
+func (feedImporter) Detect(b []byte) bool {
+	root := rootElement(b)
+	return root == "feed" || root == "rss"
+}
+
+func (feedImporter) Import(b []byte) (Export, error) {
+	if rootElement(b) == "rss" {
+		return importRSS(b)
+	}
+	var f atomFeed
+	if err := xml.Unmarshal(b, &f); err != nil {
+		return Export{}, err
+	}
+	exp := Export{Title: f.Title}
+	for i, fe := range f.Entries {
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        feedID(i),
+				Published: feedDate(fe.Published, fe.Updated),
+				Updated:   feedDate(fe.Updated, fe.Published),
+				Title:     fe.Title,
+				Content:   fe.Content.String(),
+				Tags:      Tags{kindTag("post")},
+				Author:    fe.Author,
+			},
+		}
+		if e.Content == "" {
+			e.Content = fe.Summary.String()
+		}
+		for _, l := range fe.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				e.Links = []Reply{{Rel: "alternate", Link: l.Link}}
+				break
+			}
+		}
+		for _, c := range fe.Categories {
+			e.Tags = append(e.Tags, labelTag(c.Name))
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+func importRSS(b []byte) (Export, error) {
+	var f rssFeed
+	if err := xml.Unmarshal(b, &f); err != nil {
+		return Export{}, err
+	}
+	exp := Export{Title: f.Title}
+	for i, it := range f.Items {
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        feedID(i),
+				Published: feedDate(it.PubDate, it.Date),
+				Title:     it.Title,
+				Content:   it.Content,
+				Tags:      Tags{kindTag("post")},
+				Author:    Author{Name: it.Creator},
+			},
+		}
+		e.Updated = e.Published
+		if e.Content == "" {
+			e.Content = it.Description
+		}
+		if e.Author.Name == "" {
+			e.Author.Name = it.Author
+		}
+		if link := strings.TrimSpace(it.Link); link != "" {
+			e.Links = []Reply{{Rel: "alternate", Link: link}}
+		}
+		for _, c := range it.Categories {
+			e.Tags = append(e.Tags, labelTag(strings.TrimSpace(c)))
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+// Feed entries are numbered in feed order, as their own IDs are usually URLs.
+func feedID(i int) string {
+	return "feed.post-" + strconv.Itoa(i+1)
+}
+
+var feedDateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// The first of the given dates that parses, in UTC, or the zero date.
+func feedDate(values ...string) Date {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, layout := range feedDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return Date(t.UTC())
+			}
+		}
+	}
+	return Date{}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestAtomFeedImport(t *testing.T) {
+	const feed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Notes</title>
+<entry>
+<id>https://notes.example.com/hello/</id>
+<title>Hello</title>
+<published>2020-03-01T10:00:00+01:00</published>
+<updated>2020-03-02T10:00:00+01:00</updated>
+<link rel="alternate" href="https://notes.example.com/hello/"/>
+<category term="Go"/>
+<author><name>Ann</name></author>
+<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div></content>
+</entry>
+<entry>
+<id>https://notes.example.com/short/</id>
+<title>Summary only</title>
+<updated>2020-04-01T00:00:00Z</updated>
+<summary>Just this.</summary>
+</entry>
+</feed>`
+	testImport(t, "feed", feed, []string{
+		`post feed.post-1 "Hello" by Ann at 2020-03-01T09:00:00Z labels Go`,
+		// Dated by its update, as it has no published date.
+		`post feed.post-2 "Summary only" by  at 2020-04-01T00:00:00Z`,
+	})
+}
+
+func TestRSSFeedImport(t *testing.T) {
+	const feed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+<title>Notes</title>
+<item>
+<title>Hello</title>
+<link> https://notes.example.com/hello/ </link>
+<pubDate>Sun, 1 Mar 2020 10:00:00 +0100</pubDate>
+<dc:creator>Ann</dc:creator>
+<category> Go </category>
+<description>Summary</description>
+<content:encoded><![CDATA[<p>Hi</p>]]></content:encoded>
+</item>
+<item>
+<title>Dublin Core dated</title>
+<dc:date>2020-04-01T00:00:00Z</dc:date>
+<author>ann@example.com (Ann)</author>
+<description>Just this.</description>
+</item>
+</channel>
+</rss>`
+	testImport(t, "feed", feed, []string{
+		`post feed.post-1 "Hello" by Ann at 2020-03-01T09:00:00Z labels Go`,
+		`post feed.post-2 "Dublin Core dated" by ann@example.com (Ann) at 2020-04-01T00:00:00Z`,
+	})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var fixtureLength = flag.Int("fixture-length", 200, "with gen-fixture, how many characters of each entry's content to keep")
+
+// Make an anonymized copy of an export for gen-fixture, written to fixture, or to
+// standard output for -.
+func genFixtureFile(ctx context.Context, name, fixture string) error {
+	r, err := openInput(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	src, err := io.ReadAll(utf8Reader(name, r))
+	if err != nil {
+		return err
+	}
+	if fixture == "-" {
+		return genFixture(src, os.Stdout)
+	}
+	f, err := os.Create(fixture)
+	if err != nil {
+		return err
+	}
+	if err := genFixture(src, f); err != nil {
+		f.Close()
+		os.Remove(fixture)
+		return err
+	}
+	return f.Close()
+}
+
+// Write an anonymized copy of the Blogger export src to w, small enough to attach to a
+// bug report: the names, emails and profiles of authors are replaced, as are email
+// addresses anywhere else, and each entry's content is cut down to -fixture-length
+// characters. Everything else, IDs, dates, titles, labels and links, is copied as it
+// is, so the copy converts the way the original does.
+func genFixture(src []byte, w io.Writer) error {
+	if bytes.HasPrefix(src, []byte("PK\x03\x04")) {
+		return errors.New("gen-fixture works on Blogger XML exports; unzip the Takeout and give it the feed inside")
+	}
+	a := anonymizer{names: map[string]string{}, emails: map[string]string{}, profiles: map[string]string{}}
+	bw := bufio.NewWriter(w)
+	d := xml.NewDecoder(bytes.NewReader(src))
+	// Already UTF-8, whatever the declaration says.
+	d.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	var stack []string
+	feed := false
+	// The tokens are copied from src as they were, up to copied, apart from those replaced.
+	var start, copied int64
+tokens:
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		end := d.InputOffset()
+		replacement, replace := "", false
+		switch t := tok.(type) {
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				// It's been read as UTF-8, whatever it was in.
+				replacement, replace = xmlEncoding.ReplaceAllLiteralString(string(src[start:end]), `encoding="UTF-8"`), true
+			}
+		case xml.StartElement:
+			if !feed {
+				if t.Name.Local != "feed" {
+					break tokens
+				}
+				feed = true
+			}
+			if t.Name.Local == "image" && len(stack) > 0 && stack[len(stack)-1] == "author" {
+				replacement, replace = avatarSrc.ReplaceAllLiteralString(string(src[start:end]), ` src="`+defaultAvatar+`"`), true
+			}
+			stack = append(stack, t.Name.Local)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if text, ok := a.rework(stack, string(t)); ok {
+				var b strings.Builder
+				xml.EscapeText(&b, []byte(text))
+				replacement, replace = b.String(), true
+			}
+		}
+		if replace {
+			bw.Write(src[copied:start])
+			bw.WriteString(replacement)
+			copied = end
+		}
+		start = end
+	}
+	if !feed {
+		return errors.New("gen-fixture works on Blogger XML exports")
+	}
+	bw.Write(src[copied:])
+	return bw.Flush()
+}
+
+// The avatar Blogger shows for authors without a photo.
+const defaultAvatar = "https://img1.blogblog.com/img/b16-rounded.gif"
+
+var xmlEncoding = regexp.MustCompile(`encoding=['"][^'"]*['"]`)
+
+var avatarSrc = regexp.MustCompile(`\ssrc=['"][^'"]*['"]`)
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// Stand-ins for the people in an export, the same one for each time a person appears.
+type anonymizer struct {
+	names, emails, profiles map[string]string
+}
+
+// Text for an element with the path stack, and whether it differs from text.
+func (a *anonymizer) rework(stack []string, text string) (string, bool) {
+	if len(stack) >= 2 && stack[len(stack)-2] == "author" {
+		switch stack[len(stack)-1] {
+		case "name":
+			return a.standIn(a.names, text, "Author %d"), true
+		case "email":
+			return a.standIn(a.emails, text, "author%d@example.com"), true
+		case "uri":
+			return a.standIn(a.profiles, text, "https://www.blogger.com/profile/%d"), true
+		}
+	}
+	reworked := text
+	if len(stack) > 0 && (stack[len(stack)-1] == "content" || stack[len(stack)-1] == "summary") {
+		reworked = truncateHTML(reworked, *fixtureLength)
+	}
+	reworked = emailPattern.ReplaceAllStringFunc(reworked, func(email string) string {
+		return a.standIn(a.emails, email, "author%d@example.com")
+	})
+	return reworked, reworked != text
+}
+
+// The stand-in for s, numbered in the order they're first needed. Blogger's own
+// placeholders for anonymous commenters are kept.
+func (a *anonymizer) standIn(m map[string]string, s, format string) string {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "", "Anonymous", "Unknown", "noreply@blogger.com":
+		return s
+	}
+	if r, ok := m[s]; ok {
+		return r
+	}
+	r := fmt.Sprintf(format, len(m)+1)
+	m[s] = r
+	return r
+}
+
+// HTML cut down to at most n characters, without leaving half a tag at the end.
+func truncateHTML(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut := 0
+	for i := 0; i < n; i++ {
+		_, size := utf8.DecodeRuneInString(s[cut:])
+		cut += size
+	}
+	s = s[:cut]
+	if lt := strings.LastIndex(s, "<"); lt > strings.LastIndex(s, ">") {
+		s = s[:lt]
+	}
+	return s
+}
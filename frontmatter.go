@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+)
+
+var frontmatterOnly = flag.Bool("frontmatter-only", false, "for files that already exist in the output, rewrite only the front matter and keep their body")
+
+// Split a Markdown file into its front matter block (delimiters included) and body.
+// ok is false if the file doesn't start with YAML or TOML front matter.
+func splitFrontMatter(b []byte) (fm, body []byte, ok bool) {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	for _, d := range [][]byte{[]byte("---"), []byte("+++")} {
+		if !bytes.HasPrefix(b, d) {
+			continue
+		}
+		rest := b[len(d):]
+		nl := bytes.IndexByte(rest, '\n')
+		if nl < 0 || len(bytes.TrimSpace(rest[:nl])) != 0 {
+			continue
+		}
+		for i := len(d) + nl + 1; i < len(b); {
+			end := bytes.IndexByte(b[i:], '\n')
+			line := b[i:]
+			if end >= 0 {
+				line = b[i : i+end+1]
+			}
+			if bytes.Equal(bytes.TrimSpace(line), d) {
+				return b[:i+len(line)], b[i+len(line):], true
+			}
+			if end < 0 {
+				break
+			}
+			i += end + 1
+		}
+	}
+	return nil, b, false
+}
+
+// Replace the front matter of an existing output file with the one in generated,
+// keeping the existing body untouched. ok is false if there is no existing file.
+func (c *Converter) mergeFrontMatter(name string, generated []byte) (merged []byte, ok bool, err error) {
+	r, canRead := c.out.(ReadFS)
+	if !canRead {
+		return nil, false, errors.New("-frontmatter-only needs an output that can be read back, such as a directory")
+	}
+	existing, err := r.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	fm, _, _ := splitFrontMatter(generated)
+	_, body, _ := splitFrontMatter(existing)
+	return append(fm, body...), true, nil
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+var future = flag.String("future", "draft", "what to do with posts scheduled on Blogger for later, which come through as drafts dated in the future: draft to keep them drafts, publishDate to write them as published with a publishDate, which Hugo holds back until it's passed unless buildFuture is set, or skip to leave them out")
+
+// Whether a post was scheduled on Blogger to be published after now: a draft whose
+// publishing date is still to come.
+func isScheduled(e Entry, now time.Time) bool {
+	return e.Kind() == "post" && bool(e.Draft) && time.Time(e.Published).After(now)
+}
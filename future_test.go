@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// An entry of the given kind, post, page or comment.
+func entryOfKind(kind, title, content string) Entry {
+	var e Entry
+	e.Tags = blogger.Tags{{Scheme: blogger.KindScheme, Name: blogger.KindPrefix + kind}}
+	e.Title = title
+	e.Content = content
+	return e
+}
+
+func TestIsScheduled(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(kind string, draft bool, published time.Time) Entry {
+		e := entryOfKind(kind, "Hello", "<p>Hi</p>")
+		e.Draft = blogger.Draft(draft)
+		e.Published = blogger.Date(published)
+		return e
+	}
+	tests := []struct {
+		name string
+		e    Entry
+		want bool
+	}{
+		{"draft for later", entry("post", true, now.Add(time.Hour)), true},
+		{"draft from before", entry("post", true, now.Add(-time.Hour)), false},
+		{"draft for now", entry("post", true, now), false},
+		{"published for later", entry("post", false, now.Add(time.Hour)), false},
+		{"page draft for later", entry("page", true, now.Add(time.Hour)), false},
+		{"draft without a date", entry("post", true, time.Time{}), false},
+	}
+	for _, tt := range tests {
+		if got := isScheduled(tt.e, now); got != tt.want {
+			t.Errorf("%s: isScheduled = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
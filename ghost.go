@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// A Ghost export, from Settings > Labs > Export in Ghost. Versions 1 and later wrap it
+// in {"db": [...]}, older ones don't.
+type ghostExport struct {
+	DB   []ghostDB `json:"db"`
+	Data ghostData `json:"data"`
+}
+
+type ghostDB struct {
+	Data ghostData `json:"data"`
+}
+
+type ghostData struct {
+	Posts        []ghostPost     `json:"posts"`
+	Tags         []ghostTag      `json:"tags"`
+	PostsTags    []ghostPostLink `json:"posts_tags"`
+	Users        []ghostUser     `json:"users"`
+	PostsAuthors []ghostPostLink `json:"posts_authors"`
+	Settings     []struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	} `json:"settings"`
+}
+
+type ghostPost struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	HTML        string    `json:"html"`
+	Plaintext   string    `json:"plaintext"`
+	Status      string    `json:"status"`
+	Visibility  string    `json:"visibility"`
+	Type        string    `json:"type"`
+	Page        bool      `json:"page"`
+	AuthorID    string    `json:"author_id"`
+	CreatedAt   ghostTime `json:"created_at"`
+	UpdatedAt   ghostTime `json:"updated_at"`
+	PublishedAt ghostTime `json:"published_at"`
+}
+
+type ghostTag struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility"`
+}
+
+type ghostUser struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Website string `json:"website"`
+}
+
+// A row of posts_tags or posts_authors; the other ID is under tag_id or author_id.
+type ghostPostLink struct {
+	PostID    string `json:"post_id"`
+	TagID     string `json:"tag_id"`
+	AuthorID  string `json:"author_id"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type ghostImporter struct{}
+
+func (ghostImporter) Detect(b []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		return false
+	}
+	g, err := parseGhost(b)
+	return err == nil && len(g.Posts) > 0
+}
+
+func parseGhost(b []byte) (ghostData, error) {
+	var g ghostExport
+	if err := json.Unmarshal(b, &g); err != nil {
+		return ghostData{}, err
+	}
+	if len(g.DB) > 0 {
+		return g.DB[0].Data, nil
+	}
+	return g.Data, nil
+}
+
+func (ghostImporter) Import(b []byte) (Export, error) {
+	g, err := parseGhost(b)
+	if err != nil {
+		return Export{}, err
+	}
+	var exp Export
+	for _, s := range g.Settings {
+		if s.Key == "title" {
+			json.Unmarshal(s.Value, &exp.Title)
+		}
+	}
+	tags := map[string]ghostTag{}
+	for _, t := range g.Tags {
+		tags[t.ID] = t
+	}
+	users := map[string]ghostUser{}
+	for _, u := range g.Users {
+		users[u.ID] = u
+	}
+	sort.SliceStable(g.PostsTags, func(i, j int) bool { return g.PostsTags[i].SortOrder < g.PostsTags[j].SortOrder })
+	postTags := map[string][]string{}
+	for _, pt := range g.PostsTags {
+		// Internal tags (#name) are for theming, not readers.
+		if t, ok := tags[pt.TagID]; ok && t.Visibility != "internal" {
+			postTags[pt.PostID] = append(postTags[pt.PostID], html.UnescapeString(t.Name))
+		}
+	}
+	sort.SliceStable(g.PostsAuthors, func(i, j int) bool { return g.PostsAuthors[i].SortOrder < g.PostsAuthors[j].SortOrder })
+	postAuthors := map[string][]string{}
+	for _, pa := range g.PostsAuthors {
+		postAuthors[pa.PostID] = append(postAuthors[pa.PostID], pa.AuthorID)
+	}
+
+	for i, p := range g.Posts {
+		kind := "post"
+		if p.Type == "page" || p.Page {
+			kind = "page"
+		}
+		e := Entry{
+			Entry: blogger.Entry{
+				// Ghost IDs are hex object IDs, so posts are numbered in export order instead.
+				ID:        "ghost.post-" + strconv.Itoa(i+1),
+				Published: ghostDate(p.PublishedAt, p.CreatedAt),
+				Updated:   ghostDate(p.UpdatedAt, p.PublishedAt, p.CreatedAt),
+				Draft:     Draft(p.Status != "published"),
+				Title:     p.Title,
+				Content:   p.HTML,
+				Tags:      Tags{kindTag(kind)},
+			},
+			Slug: p.Slug,
+		}
+		if e.Content == "" {
+			e.Content = html.EscapeString(p.Plaintext)
+		}
+		if p.Visibility != "" && p.Visibility != "public" {
+			e.Hidden = "for " + p.Visibility + " only on Ghost"
+		}
+		authorIDs := postAuthors[p.ID]
+		if len(authorIDs) == 0 {
+			authorIDs = []string{p.AuthorID}
+		}
+		for _, id := range authorIDs {
+			if u, ok := users[id]; ok {
+				e.Authors = append(e.Authors, Author{Name: u.Name, Uri: u.Website})
+			}
+		}
+		if len(e.Authors) > 0 {
+			e.Author = e.Authors[0]
+		}
+		for _, t := range postTags[p.ID] {
+			e.Tags = append(e.Tags, labelTag(t))
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+// Ghost dates are ISO 8601 strings in UTC; 0.x exports used milliseconds since the epoch.
+type ghostTime time.Time
+
+func (t *ghostTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var ms int64
+	if err := json.Unmarshal(b, &ms); err == nil {
+		*t = ghostTime(time.UnixMilli(ms).UTC())
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.Parse(time.RFC3339Nano, s)
+	*t = ghostTime(v.UTC())
+	return err
+}
+
+// The first of the given times that is set.
+func ghostDate(values ...ghostTime) Date {
+	for _, v := range values {
+		if !time.Time(v).IsZero() {
+			return Date(v)
+		}
+	}
+	return Date{}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestGhostImport(t *testing.T) {
+	const export = `{"db": [{"data": {
+	"posts": [
+		{"id": "5f1a", "title": "Hello", "slug": "hello", "html": "<p>Hi</p>", "status": "published", "type": "post",
+			"created_at": "2020-02-01T00:00:00.000Z", "published_at": "2020-03-01T10:00:00.000Z"},
+		{"id": "5f1b", "title": "About", "slug": "about", "html": "<p>Us</p>", "status": "published", "page": true,
+			"published_at": 1577836800000},
+		{"id": "5f1c", "title": "Later", "slug": "later", "plaintext": "Notes & ideas", "status": "draft", "author_id": "u2",
+			"created_at": "2020-04-01T00:00:00.000Z"}
+	],
+	"tags": [
+		{"id": "t1", "name": "Go"},
+		{"id": "t2", "name": "#featured", "visibility": "internal"},
+		{"id": "t3", "name": "Web &amp; HTTP"}
+	],
+	"posts_tags": [
+		{"post_id": "5f1a", "tag_id": "t3", "sort_order": 1},
+		{"post_id": "5f1a", "tag_id": "t1", "sort_order": 0},
+		{"post_id": "5f1a", "tag_id": "t2", "sort_order": 2}
+	],
+	"users": [{"id": "u1", "name": "Ann"}, {"id": "u2", "name": "Bob"}],
+	"posts_authors": [{"post_id": "5f1a", "author_id": "u1"}, {"post_id": "5f1b", "author_id": "u1"}],
+	"settings": [{"key": "title", "value": "\"Notes\""}]
+}}]}`
+	testImport(t, "auto", export, []string{
+		`post ghost.post-1 "Hello" by Ann at 2020-03-01T10:00:00Z labels Go, Web & HTTP`,
+		`page ghost.post-2 "About" by Ann at 2020-01-01T00:00:00Z`,
+		// Dated when it was created, as it was never published.
+		`post ghost.post-3 "Later" by Bob at 2020-04-01T00:00:00Z draft`,
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var gitInit = flag.Bool("git-init", false, "once converted, make the target directory a git repository and commit each post and page on its own, under its author and published date, so the site's history and blame go back to the blog's")
+var gitCommand = flag.String("git", "git", "with -git-init, the git command to commit with")
+
+// Blogger's exports give no author's email but this.
+const bloggerEmail = "noreply@blogger.com"
+
+// Initialize a git repository in dir, or go on with the one there, and commit the
+// converted posts and pages into it oldest first, each as written by its author when it
+// was published. What else was written, such as comments and redirects, is committed
+// last. Returns how many commits were made.
+func (c *Converter) commitHistory(ctx context.Context, dir string) (int, error) {
+	git, err := exec.LookPath(*gitCommand)
+	if err != nil {
+		return 0, fmt.Errorf("-git-init needs git: %w", err)
+	}
+	run := func(env []string, args ...string) error {
+		cmd := exec.CommandContext(ctx, git, append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		var out bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &out, &out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("-git-init: git %s: %w\n%s", args[0], err, strings.TrimSpace(out.String()))
+		}
+		return nil
+	}
+	// Whether anything is staged to commit.
+	staged := func() (bool, error) {
+		err := exec.CommandContext(ctx, git, "-C", dir, "diff", "--cached", "--quiet").Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("-git-init: git diff: %w", err)
+		}
+		return false, nil
+	}
+	if err := run(nil, "init", "--quiet"); err != nil {
+		return 0, err
+	}
+
+	var entries []Entry
+	for _, e := range c.exp.Entries {
+		if e.Path != "" && (e.Kind() == "post" || e.Kind() == "page") {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return time.Time(entries[i].Published).Before(time.Time(entries[j].Published))
+	})
+	commits := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return commits, ctx.Err()
+		}
+		// A page bundle is committed with its resources.
+		files := filepath.FromSlash(e.Path)
+		if base := path.Base(e.Path); base == "index.md" || base == "_index.md" {
+			files = filepath.FromSlash(path.Dir(e.Path))
+		}
+		if err := run(nil, "add", "--", files); err != nil {
+			return commits, err
+		}
+		if ok, err := staged(); err != nil || !ok {
+			// Already committed, by an earlier run.
+			if err != nil {
+				return commits, err
+			}
+			continue
+		}
+		author := strings.TrimSpace(e.Author.Name)
+		if author == "" {
+			author = "Unknown"
+		}
+		date := time.Time(e.Published).Format(time.RFC3339)
+		env := []string{
+			"GIT_AUTHOR_NAME=" + author, "GIT_AUTHOR_EMAIL=" + bloggerEmail, "GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_NAME=" + author, "GIT_COMMITTER_EMAIL=" + bloggerEmail, "GIT_COMMITTER_DATE=" + date,
+		}
+		msg := fmt.Sprintf("Add %s %q", e.Kind(), e.Title)
+		if e.URL != "" {
+			msg += "\n\nPublished at " + e.URL
+		}
+		if err := run(env, "commit", "--quiet", "--no-verify", "-m", msg); err != nil {
+			return commits, err
+		}
+		commits++
+	}
+
+	if err := run(nil, "add", "--all"); err != nil {
+		return commits, err
+	}
+	if ok, err := staged(); err != nil || !ok {
+		return commits, err
+	}
+	// Committed by whoever ran the conversion, or failing a git identity, by it.
+	var env []string
+	if exec.CommandContext(ctx, git, "-C", dir, "var", "GIT_COMMITTER_IDENT").Run() != nil {
+		env = []string{"GIT_AUTHOR_NAME=blogger2hugo", "GIT_AUTHOR_EMAIL=" + bloggerEmail,
+			"GIT_COMMITTER_NAME=blogger2hugo", "GIT_COMMITTER_EMAIL=" + bloggerEmail}
+	}
+	if err := run(env, "commit", "--quiet", "--no-verify", "-m", "Add the rest of the converted blog"); err != nil {
+		return commits, err
+	}
+	return commits + 1, nil
+}
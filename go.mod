@@ -0,0 +1,11 @@
+module github.com/atulsingh0/blogger2hugo
+
+go 1.21
+
+require (
+	github.com/yuin/goldmark v1.7.8
+	go.starlark.net v0.0.0-20240123142251-f86470692795
+	golang.org/x/text v0.21.0
+)
+
+require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
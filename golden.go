@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "with golden, write the conversion into the golden directory instead of comparing it")
+
+// Compare what a conversion wrote to out with the golden files in dir, listing every
+// file that's new, missing or different. Returns how many didn't match.
+func compareGolden(out *MemFS, dir string) (int, error) {
+	golden, err := goldenNames(dir)
+	if err != nil {
+		return 0, err
+	}
+	mismatched := 0
+	for _, name := range out.Names() {
+		got, _ := out.ReadFile(name)
+		if !golden[name] {
+			log.Printf("new: %s", name)
+			mismatched++
+			continue
+		}
+		delete(golden, name)
+		want, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return mismatched, err
+		}
+		if !bytes.Equal(want, got) {
+			log.Printf("differs: %s, %s", name, firstDifference(want, got))
+			mismatched++
+		}
+	}
+	missing := make([]string, 0, len(golden))
+	for name := range golden {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		log.Printf("missing: %s", name)
+		mismatched++
+	}
+	return mismatched, nil
+}
+
+// Write what a conversion wrote to out into the golden directory dir. Golden files it
+// no longer writes are listed rather than removed, in case dir is more than golden files.
+func writeGolden(out *MemFS, dir string) error {
+	golden, err := goldenNames(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, name := range out.Names() {
+		b, _ := out.ReadFile(name)
+		w, err := DirFS(dir).Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		delete(golden, name)
+	}
+	log.Printf("Wrote %d golden files to %s.", len(out.Names()), dir)
+	if len(golden) > 0 {
+		stale := make([]string, 0, len(golden))
+		for name := range golden {
+			stale = append(stale, name)
+		}
+		sort.Strings(stale)
+		log.Printf("%d files in %s are no longer written by the conversion:", len(stale), dir)
+		for _, name := range stale {
+			log.Printf("\t%s", name)
+		}
+	}
+	return nil
+}
+
+// The files under dir, by slash-separated path relative to it.
+func goldenNames(dir string) (map[string]bool, error) {
+	names := map[string]bool{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		names[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return names, err
+}
+
+// Where want and got first differ, by line.
+func firstDifference(want, got []byte) string {
+	w, g := strings.Split(string(want), "\n"), strings.Split(string(got), "\n")
+	for i := 0; ; i++ {
+		switch {
+		case i >= len(w):
+			return fmt.Sprintf("line %d added: %q", i+1, clip(g[i]))
+		case i >= len(g):
+			return fmt.Sprintf("line %d removed: %q", i+1, clip(w[i]))
+		case w[i] != g[i]:
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, clip(w[i]), clip(g[i]))
+		}
+	}
+}
+
+// A line shortened for a report.
+func clip(line string) string {
+	if r := []rune(line); len(r) > 80 {
+		return string(r[:77]) + "..."
+	}
+	return line
+}
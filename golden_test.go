@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Convert each fixture as golden mode does, with the default flags, and compare it with
+// its golden files. Run with -update-golden to rewrite them after a change meant to
+// alter the output.
+func TestGolden(t *testing.T) {
+	for _, test := range []struct {
+		name, export string
+		lowMemory    bool
+	}{
+		{"story", "tests/data/story-blogger-backup.xml", false},
+		{"comments", "tests/data/comments-blogger-backup.xml", false},
+		// Spilling bodies to disk mustn't change what's written.
+		{"comments", "tests/data/comments-blogger-backup.xml", true},
+	} {
+		dir := "tests/golden/" + test.name
+		if test.lowMemory && *updateGolden {
+			continue
+		}
+		func() {
+			defer func(b bool) { *lowMemory = b }(*lowMemory)
+			*lowMemory = test.lowMemory
+			ctx := context.Background()
+			c := newConverter()
+			c.urlPrefix = contentSection(dir)
+			mem := &MemFS{}
+			c.out = mem
+			var err error
+			if c.exp, err = loadExports(ctx, []string{test.export}); err != nil {
+				t.Fatal(err)
+			}
+			defer c.exp.Close()
+			if err := c.convert(ctx, false, dir); err != nil {
+				t.Fatal(err)
+			}
+			if *updateGolden {
+				if err := writeGolden(mem, dir); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			n, err := compareGolden(mem, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n > 0 {
+				t.Errorf("%s (-low-memory=%t): %d files differ from the golden files in %s", test.export, test.lowMemory, n, dir)
+			}
+		}()
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+var hashbangJS = flag.String("hashbang-js", "", "write a script, relative to the target directory, that sends Dynamic Views links (/#!/2013/05/post.html) to the new permalinks; include it in your site's <head>")
+
+// Servers never see the part after #, so hash-bang links can only be redirected in the browser.
+const hashbangScript = `// Redirects Blogger Dynamic Views links, e.g. /#!/2013/05/post.html, to their new pages.
+(function () {
+  var redirects = %s;
+  var hash = window.location.hash;
+  if (hash.indexOf("#!") !== 0) {
+    return;
+  }
+  var p = hash.slice(2).replace(/^https?:\/\/[^\/]+/, "").split("?")[0];
+  if (p.charAt(0) !== "/") {
+    p = "/" + p;
+  }
+  var to = redirects[p] || redirects[decodeURI(p)];
+  if (to) {
+    window.location.replace(to);
+  } else if (p !== "/" && !/\.html$/.test(p)) {
+    window.location.replace(p);
+  }
+})();
+`
+
+func (c *Converter) writeHashbangJS() error {
+	m := map[string]string{}
+	for _, r := range c.collectRedirects() {
+		m[r.From] = r.To
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	f, err := c.create(path.Clean(filepath.ToSlash(*hashbangJS)))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, hashbangScript, b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Hexo's posts: _posts/slug.md in the site's source directory, or _drafts/slug.md for
+// drafts, and pages as slug/index.md, served at their Blogger URL through permalink.
+// Its front matter is YAML, and comments go into _data/comments.json.
+type hexoTarget struct{}
+
+var hexoTempl = `---
+title: "{{ .Title }}"
+date: {{ .Published }}
+updated: {{ .Updated }}{{ with quoteList .Tags.Labels }}
+tags: [{{ . }}]{{ end }}{{ if not .Draft }}
+permalink: "{{ hexoPermalink . }}"{{ end }}{{ if .Unlisted }}
+hidden: true{{ end }}{{ if .Featured }}
+{{ featuredKey }}: true{{ end }}{{ with .Views }}
+views: {{ . }}{{ end }}{{ with .BloggerID }}
+blogger_id: "{{ . }}"{{ end }}{{ with .URL }}
+blogger_url: "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }}: {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related: [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings: [{{ . }}]{{ end }}{{ with extras . }}
+{{ . }}{{ end }}{{ with .Extra }}
+{{.}}{{ end }}
+author: "{{ .Author.Name }}"{{ with .Enclosure }}
+enclosure:
+  url: "{{ .URL }}"{{ if .Length }}
+  length: {{ .Length }}{{ end }}
+  type: "{{ .Type }}"{{ end }}
+---
+
+{{ liquidRaw .Content }}
+`
+
+func (hexoTarget) Template() string { return hexoTempl }
+
+func (hexoTarget) PlacePost(c *Converter, e *Entry) error {
+	e.Slug = c.entrySlug(*e)
+	dir := "_posts"
+	if e.Draft {
+		dir = "_drafts"
+	}
+	name, err := c.claimPath(dir, e.Slug, *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (hexoTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := path.Clean(filepath.ToSlash(*pagesDir))
+	e.Slug = c.entrySlug(*e)
+	name, err := c.claimPath(dir, e.Slug, *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name, "index.md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// The post's Blogger URL, which its permalink keeps, or failing one Hexo's default
+// permalink, /2014/05/19/hello/, or a page's directory.
+func (hexoTarget) Permalink(c *Converter, e Entry, name string) string {
+	if p := bloggerPath(e.URL); p != "" && p != "/" {
+		return p
+	}
+	if e.Kind() == "page" {
+		return "/" + path.Dir(name) + "/"
+	}
+	return "/" + strings.ReplaceAll(e.Published.String()[:10], "-", "/") + "/" + e.Slug + "/"
+}
+
+// Comments are written by post once the posts have their slugs, see WriteSite.
+func (hexoTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+// Write the comments into _data/comments.json, by post slug, which themes find as
+// site.data.comments[page.slug].
+func (hexoTarget) WriteSite(c *Converter) error {
+	comments := map[string][]dataComment{}
+	for k, post := range c.exp.Entries {
+		if post.Path != "" && len(post.Children) > 0 {
+			comments[post.Slug] = c.dataComments(k)
+		}
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	return c.writeJSON(path.Join("_data", "comments.json"), comments)
+}
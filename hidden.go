@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"strings"
+)
+
+var hidden = flag.String("hidden", "keep", "what to do with posts that were private or hidden from search engines on Blogger: keep, skip, draft, or unlisted (build.list = never)")
+
+// The value of a blog-wide setting from the export, e.g. BLOG_READ_ACCESS_MODE.
+func (c *Converter) blogSetting(name string) (string, bool) {
+	for _, e := range c.exp.Entries {
+		if e.Kind() == "settings" && strings.HasSuffix(e.ID, ".settings."+name) {
+			return strings.TrimSpace(e.Content), true
+		}
+	}
+	return "", false
+}
+
+// Mark posts and pages that readers or search engines couldn't reach on Blogger,
+// and apply -hidden to them. Returns how many were found.
+func (c *Converter) markHidden() int {
+	blogWide := ""
+	if mode, ok := c.blogSetting("BLOG_READ_ACCESS_MODE"); ok && mode != "" && mode != "PUBLIC" {
+		blogWide = "the blog is private (" + mode + ")"
+	} else if searchable, ok := c.blogSetting("BLOG_SEARCHABLE"); ok && searchable == "false" {
+		blogWide = "the blog is hidden from search engines"
+	}
+	var rules []robotsRule
+	if enabled, _ := c.blogSetting("BLOG_CUSTOM_ROBOTS_TXT_ENABLED"); enabled == "true" {
+		txt, _ := c.blogSetting("BLOG_CUSTOM_ROBOTS_TXT")
+		rules = parseRobots(txt)
+	}
+
+	n := 0
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if kind := e.Kind(); kind != "post" && kind != "page" {
+			continue
+		}
+		if e.Hidden == "" {
+			e.Hidden = blogWide
+		}
+		if e.Hidden == "" && robotsDisallowed(rules, bloggerPath(e.URL)) {
+			e.Hidden = "robots.txt disallows " + bloggerPath(e.URL)
+		}
+		if e.Hidden == "" {
+			continue
+		}
+		n++
+		switch *hidden {
+		case "draft":
+			e.Draft = true
+		case "unlisted":
+			e.Unlisted = true
+		}
+	}
+	return n
+}
+
+// Whether a hidden entry is left out of the conversion entirely.
+func skipHidden(e Entry) bool {
+	return e.Hidden != "" && *hidden == "skip"
+}
+
+type robotsRule struct {
+	Allow  bool
+	Prefix string
+}
+
+// The Allow and Disallow rules of robots.txt that apply to every crawler (User-agent: *).
+func parseRobots(txt string) []robotsRule {
+	var rules []robotsRule
+	applies, inRules := false, false
+	sc := bufio.NewScanner(strings.NewReader(txt))
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.SplitN(sc.Text(), "#", 2)[0])
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(line[:colon]))
+		value := strings.TrimSpace(line[colon+1:])
+		switch field {
+		case "user-agent":
+			if inRules {
+				// A user-agent line after rules starts a new group.
+				applies, inRules = false, false
+			}
+			if value == "*" {
+				applies = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if applies && value != "" {
+				rules = append(rules, robotsRule{Allow: field == "allow", Prefix: value})
+			}
+		}
+	}
+	return rules
+}
+
+// Whether the longest rule matching p disallows it, as crawlers decide.
+func robotsDisallowed(rules []robotsRule, p string) bool {
+	if p == "" {
+		return false
+	}
+	best := robotsRule{Allow: true}
+	for _, r := range rules {
+		if strings.HasPrefix(p, strings.TrimSuffix(r.Prefix, "*")) && len(r.Prefix) >= len(best.Prefix) {
+			if len(r.Prefix) > len(best.Prefix) || r.Allow {
+				best = r
+			}
+		}
+	}
+	return !best.Allow
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Converting post HTML to plain Markdown, for -target=markdown and -markdown. What has
+// no Markdown of its own, such as embedded video, is kept as HTML.
+
+var markdownBodies = flag.Bool("markdown", false, "turn the HTML of each post and page into Markdown, rather than writing it as it is, and point links between posts at their new permalinks unless -internal-links says otherwise")
+
+// Elements that start a block of their own, ending the paragraph before them.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true,
+	"figure": true, "figcaption": true, "table": true, "tr": true, "center": true,
+	"dl": true, "dt": true, "dd": true, "address": true, "aside": true, "nav": true,
+}
+
+// Elements kept as HTML, as Markdown has nothing for them.
+var embedElements = map[string]bool{"iframe": true, "video": true, "audio": true, "embed": true, "object": true}
+
+// Inline elements and the Markdown they're wrapped in.
+var inlineMarkers = map[string]string{
+	"strong": "**", "b": "**", "em": "*", "i": "*", "code": "`", "tt": "`",
+	"del": "~~", "s": "~~", "strike": "~~",
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+// An inline element being converted: what it started as, and where its text starts.
+type mdInline struct {
+	name  string
+	href  string
+	start int
+}
+
+type mdList struct {
+	ordered bool
+	n       int
+}
+
+type mdWriter struct {
+	out strings.Builder
+	// The paragraph being written.
+	cur     strings.Builder
+	inlines []mdInline
+	lists   []mdList
+	// The marker of the list item the next paragraph starts.
+	item    string
+	quotes  int
+	heading int
+	pre     int
+	skip    int
+	// Whether the last block written was a list item, and how deep in quotes it was.
+	tight  bool
+	quoted int
+	// How a link to href is written, or "" to write it as a Markdown link.
+	link func(href, text string) string
+}
+
+// Convert HTML to Markdown. link, if set, can write links in a form of its own, such
+// as wikilinks.
+func htmlToMarkdown(src string, link func(href, text string) string) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(src))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	w := &mdWriter{link: link}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			w.start(t)
+		case xml.EndElement:
+			w.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			w.text(string(t))
+		}
+	}
+	w.flush()
+	return w.out.String() + "\n", nil
+}
+
+// A post's body as Markdown, with link writing links as htmlToMarkdown does. HTML that
+// can't be read is kept as it is.
+func markdownBody(e Entry, link func(href, text string) string) string {
+	md, err := htmlToMarkdown(e.Content, link)
+	if err != nil {
+		log.Printf("Kept the HTML of %q, which couldn't be turned into Markdown: %s", e.Title, err)
+		return e.Content
+	}
+	return md
+}
+
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (w *mdWriter) start(t xml.StartElement) {
+	name := strings.ToLower(t.Name.Local)
+	if w.skip > 0 || name == "script" || name == "style" {
+		w.skip++
+		return
+	}
+	switch {
+	case blockElements[name]:
+		w.flush()
+	case name == "br":
+		if w.pre > 0 {
+			w.cur.WriteString("\n")
+		} else {
+			w.cur.WriteString("\\\n")
+		}
+	case name == "hr":
+		w.flush()
+		w.block("---")
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		w.flush()
+		w.heading = int(name[1] - '0')
+	case name == "ul" || name == "ol":
+		w.flush()
+		w.lists = append(w.lists, mdList{ordered: name == "ol"})
+	case name == "li":
+		w.flush()
+		if n := len(w.lists); n > 0 {
+			l := &w.lists[n-1]
+			l.n++
+			w.item = "- "
+			if l.ordered {
+				w.item = fmt.Sprintf("%d. ", l.n)
+			}
+		}
+	case name == "blockquote":
+		w.flush()
+		w.quotes++
+	case name == "pre":
+		w.flush()
+		w.pre++
+	case name == "img":
+		w.cur.WriteString(fmt.Sprintf("![%s](%s)", markdownEscaper.Replace(attr(t, "alt")), attr(t, "src")))
+	case embedElements[name]:
+		var b strings.Builder
+		b.WriteString("<" + name)
+		for _, a := range t.Attr {
+			fmt.Fprintf(&b, " %s=%q", a.Name.Local, a.Value)
+		}
+		b.WriteString("></" + name + ">")
+		w.flush()
+		w.block(b.String())
+		// What's inside is the fallback for browsers without the element.
+		w.skip++
+	case name == "a":
+		w.inlines = append(w.inlines, mdInline{name: name, href: attr(t, "href"), start: w.cur.Len()})
+	case inlineMarkers[name] != "" && w.pre == 0:
+		w.inlines = append(w.inlines, mdInline{name: name, start: w.cur.Len()})
+	}
+}
+
+func (w *mdWriter) end(name string) {
+	if w.skip > 0 {
+		w.skip--
+		return
+	}
+	switch {
+	case blockElements[name], name == "li":
+		w.flush()
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		w.flush()
+	case name == "ul" || name == "ol":
+		w.flush()
+		if n := len(w.lists); n > 0 {
+			w.lists = w.lists[:n-1]
+		}
+		// A list after this one is a list of its own.
+		if len(w.lists) == 0 {
+			w.tight = false
+		}
+	case name == "blockquote":
+		w.flush()
+		if w.quotes > 0 {
+			w.quotes--
+		}
+	case name == "pre":
+		code := strings.Trim(w.cur.String(), "\n")
+		w.cur.Reset()
+		if w.pre > 0 {
+			w.pre--
+		}
+		if code != "" {
+			w.block("```\n" + code + "\n```")
+		}
+	case name == "a" || inlineMarkers[name] != "":
+		for i := len(w.inlines) - 1; i >= 0; i-- {
+			if w.inlines[i].name == name {
+				w.close(w.inlines[i])
+				w.inlines = w.inlines[:i]
+				break
+			}
+		}
+	}
+}
+
+// Wrap the text of an inline element that's ended in its Markdown, keeping the
+// spaces around it outside.
+func (w *mdWriter) close(in mdInline) {
+	s := w.cur.String()
+	if in.start > len(s) {
+		in.start = len(s)
+	}
+	inner := s[in.start:]
+	text := strings.TrimSpace(inner)
+	lead := inner[:len(inner)-len(strings.TrimLeft(inner, " \n"))]
+	trail := inner[len(strings.TrimRight(inner, " \n")):]
+	var md string
+	switch {
+	case in.name == "a" && (in.href == "" || strings.HasPrefix(in.href, "#") || strings.HasPrefix(strings.ToLower(in.href), "javascript:")):
+		md = text
+	case in.name == "a":
+		if w.link != nil {
+			md = w.link(in.href, text)
+		}
+		if md == "" {
+			if text == "" {
+				text = markdownEscaper.Replace(in.href)
+			}
+			md = fmt.Sprintf("[%s](%s)", text, strings.ReplaceAll(in.href, " ", "%20"))
+		}
+	case text == "":
+		md = ""
+	default:
+		m := inlineMarkers[in.name]
+		md = m + text + m
+	}
+	w.cur.Reset()
+	w.cur.WriteString(s[:in.start] + lead + md + trail)
+}
+
+func (w *mdWriter) text(s string) {
+	if w.skip > 0 {
+		return
+	}
+	if w.pre > 0 {
+		w.cur.WriteString(s)
+		return
+	}
+	if len(w.inlines) > 0 && w.inlines[len(w.inlines)-1].name == "code" {
+		w.cur.WriteString(strings.Join(strings.Fields(s), " "))
+		return
+	}
+	collapsed := strings.Join(strings.Fields(s), " ")
+	if collapsed == "" {
+		if s != "" {
+			w.cur.WriteString(" ")
+		}
+		return
+	}
+	if s[0] == ' ' || s[0] == '\n' || s[0] == '\t' || s[0] == '\r' {
+		collapsed = " " + collapsed
+	}
+	if last := s[len(s)-1]; last == ' ' || last == '\n' || last == '\t' || last == '\r' {
+		collapsed += " "
+	}
+	w.cur.WriteString(markdownEscaper.Replace(collapsed))
+}
+
+// End the paragraph being written, as a heading, list item or quote as it's in.
+func (w *mdWriter) flush() {
+	if w.pre > 0 {
+		return
+	}
+	lines := strings.Split(w.cur.String(), "\n")
+	w.cur.Reset()
+	// The inline elements still open go on in the next paragraph.
+	for i := range w.inlines {
+		w.inlines[i].start = 0
+	}
+	var kept []string
+	for _, l := range lines {
+		if l = strings.Join(strings.Fields(l), " "); l != "" && l != `\` {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) == 0 {
+		return
+	}
+	// A line break ending the paragraph breaks nothing.
+	if last := len(kept) - 1; strings.HasSuffix(kept[last], `\`) && !strings.HasSuffix(kept[last], `\\`) {
+		kept[last] = strings.TrimSuffix(kept[last], `\`)
+	}
+	text := strings.Join(kept, "\n")
+	if w.heading > 0 {
+		text = strings.Repeat("#", w.heading) + " " + strings.ReplaceAll(strings.TrimSuffix(text, `\`), "\\\n", " ")
+		w.heading = 0
+	}
+	if n := len(w.lists); n > 0 {
+		indent := strings.Repeat("   ", n-1)
+		marker := w.item
+		if marker == "" {
+			// More of the item, after a list nested in it.
+			marker = "   "
+		}
+		w.item = ""
+		w.write(indent+marker+strings.ReplaceAll(text, "\n", "\n"+indent+"   "), true)
+		return
+	}
+	w.block(text)
+}
+
+// Write a block of Markdown, quoted as it's in. List items are tight, without a blank
+// line between them.
+func (w *mdWriter) write(text string, tight bool) {
+	if w.quotes > 0 {
+		prefix := strings.Repeat("> ", w.quotes)
+		text = prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+	}
+	if w.out.Len() > 0 {
+		switch {
+		case tight && w.tight:
+			w.out.WriteString("\n")
+		case w.quotes > 0 && w.quoted > 0:
+			// Still in the same quote.
+			depth := w.quotes
+			if w.quoted < depth {
+				depth = w.quoted
+			}
+			w.out.WriteString("\n" + strings.TrimSpace(strings.Repeat("> ", depth)) + "\n")
+		default:
+			w.out.WriteString("\n\n")
+		}
+	}
+	w.out.WriteString(text)
+	w.tight = tight
+	w.quoted = w.quotes
+}
+
+func (w *mdWriter) block(text string) {
+	w.write(text, false)
+}
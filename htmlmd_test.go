@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"<p>Hello <b>bold</b> and <i>it</i></p><p>Two</p>", "Hello **bold** and *it*\n\nTwo\n"},
+		{"<h2>Head</h2>text", "## Head\n\ntext\n"},
+		{`<a href="https://example.com/">Example</a>`, "[Example](https://example.com/)\n"},
+		{"<ul><li>a</li><li>b</li></ul>", "- a\n- b\n"},
+		{"<ol><li>a</li><li>b</li></ol>", "1. a\n2. b\n"},
+		{"line<br>next", "line\\\nnext\n"},
+		{"<pre><code>x := 1\n</code></pre>", "```\nx := 1\n```\n"},
+		{"<code>x</code>", "`x`\n"},
+		{"<blockquote>quoted</blockquote>", "> quoted\n"},
+		// Entities unescaped, Markdown escaped.
+		{"a &amp; b *c*", "a & b \\*c\\*\n"},
+		// Closed by the end of the paragraph.
+		{"<p>bad <b>unclosed</p>", "bad **unclosed**\n"},
+		// Nothing in Markdown for it.
+		{`<iframe src="https://example.com/video"></iframe>`, "<iframe src=\"https://example.com/video\"></iframe>\n"},
+	}
+	for _, tt := range tests {
+		got, err := htmlToMarkdown(tt.in, nil)
+		if err != nil {
+			t.Errorf("htmlToMarkdown(%q): %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("htmlToMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLToMarkdownLink(t *testing.T) {
+	wikilink := func(href, text string) string { return "[[" + text + "]]" }
+	got, err := htmlToMarkdown(`See <a href="/2014/05/hello.html">Hello</a>.`, wikilink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "See [[Hello]].\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHTMLToMarkdownUnreadable(t *testing.T) {
+	if _, err := htmlToMarkdown(`<p title="unterminated>text</p>`, nil); err == nil {
+		t.Error("no error for HTML that can't be read")
+	}
+}
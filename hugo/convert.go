@@ -0,0 +1,123 @@
+// Package hugo converts Blogger posts for a Hugo site.
+package hugo
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// A post or static page, named and dated the way blogger2hugo writes it by default.
+type ConvertedPost struct {
+	Kind      string // post or page
+	ID        string // the Blogger post ID, e.g. 1234567890
+	Title     string
+	Slug      string
+	Path      string // the file it's written to, e.g. 2014-05-19-hello-world.md or about.md
+	URL       string // where the post was on Blogger
+	Published time.Time
+	Updated   time.Time
+	Draft     bool
+	Labels    []string
+	Author    string
+	Authors   []string          // every author, Author first; on team blogs there can be several
+	Params    map[string]string // further front matter, if any
+	Content   string            // the post's HTML
+}
+
+// Convert reads a Blogger export from r and passes each post and page to fn as soon as
+// it's decoded, so it can be indexed or written out without keeping the whole export in
+// memory. Comments, and the links between posts, need the whole export and are left out.
+// Registered transforms are run on each post first. An error from a transform, fn or
+// ctx stops the conversion and is returned.
+func Convert(ctx context.Context, r io.Reader, fn func(post ConvertedPost) error) error {
+	p := blogger.Parser{Entry: func(e blogger.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch e.Kind() {
+		case "post", "page":
+			post := convertEntry(e, SlugOptions{})
+			if err := ApplyTransforms(ctx, &post); err != nil {
+				return err
+			}
+			return fn(post)
+		}
+		return nil
+	}}
+	_, err := p.Parse(r)
+	return err
+}
+
+func convertEntry(e blogger.Entry, o SlugOptions) ConvertedPost {
+	c := ConvertedPost{
+		Kind:      e.Kind(),
+		ID:        PostID(e.ID),
+		Title:     e.Title,
+		Published: time.Time(e.Published),
+		Updated:   time.Time(e.Updated),
+		Draft:     bool(e.Draft),
+		Labels:    e.Tags.Labels(),
+		Author:    e.Author.Name,
+		Authors:   authorNames(e),
+		Content:   e.Content,
+	}
+	for _, l := range e.Links {
+		if strings.ToLower(l.Rel) == "alternate" {
+			c.URL = l.Link
+		}
+	}
+	name := c.Title
+	if c.URL != "" {
+		// Blogger truncates and strips stop-words from its slugs, so keep its version for URL fidelity.
+		name = strings.TrimSuffix(path.Base(c.URL), path.Ext(c.URL))
+	}
+	if !o.Transliterable(name) {
+		c.Slug = "post-" + c.ID
+	} else if c.Slug = o.Slug(name); strings.Trim(c.Slug, "-._") == "" {
+		c.Slug = o.FallbackSlug(c.Content, c.ID)
+	}
+	c.Path = c.Slug + ".md"
+	if c.Kind == "post" {
+		c.Path = c.Published.Format("2006-01-02") + "-" + c.Path
+	}
+	return c
+}
+
+// ApplyTo puts back into e what a Transform can change of the post: its title, labels,
+// draft flag, author and content. Params have no place in a Blogger entry.
+func (p ConvertedPost) ApplyTo(e *blogger.Entry) {
+	e.Title = p.Title
+	e.Draft = blogger.Draft(p.Draft)
+	e.Author.Name = p.Author
+	e.Content = p.Content
+	e.Tags = e.Tags.WithLabels(p.Labels)
+}
+
+func authorNames(e blogger.Entry) []string {
+	if len(e.Authors) == 0 {
+		return []string{e.Author.Name}
+	}
+	names := make([]string, len(e.Authors))
+	for i, a := range e.Authors {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// The number Blogger knows a post, page or comment by, from its entry ID.
+// E.g. tag:blogger.com,1999:blog-42.post-1234567890 -> 1234567890
+func PostID(id string) string {
+	index := strings.LastIndex(id, "post-")
+	if i := strings.LastIndex(id, "page-"); i > index {
+		index = i
+	}
+	if index < 0 {
+		return id
+	}
+	return id[index+5:]
+}
@@ -0,0 +1,143 @@
+package hugo
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// The TOML front matter and body the blogger2hugo command writes each post, page and
+// comment with under -format=toml, as a text/template executed on its entry.
+const TOMLTemplate = `+++
+title = "{{ .Title }}"{{ if slugInFrontMatter }}
+slug = "{{ .Slug }}"{{ end }}
+date = {{ .Published }}{{ if .Scheduled }}
+publishDate = {{ .Published }}{{ end }}
+updated = {{ .Updated }}{{ with quoteList .Tags.Labels }}
+{{ tagsKey }} = [{{ . }}]{{ end }}{{ if .Draft }}
+draft = true{{ end }}{{ if .Featured }}
+{{ featuredKey }} = true{{ end }}{{ with .Views }}
+views = {{ . }}{{ end }}{{ if not (len .Comments | eq 0) }}
+comments = [ {{range $i, $e := .Comments}}{{if $i}}, {{end}}{{$e}}{{end}} ]{{ end }}{{ with .Menu }}
+menu = "{{ . }}"{{ end }}{{ with .BloggerID }}
+blogger_id = "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }} = {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related = [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings = [{{ . }}]{{ end }}{{ with authors . }}
+authors = [{{ . }}]{{ end }}{{ with quoteList .Aliases }}
+aliases = [{{ . }}]{{ end }}
+blogimport = true {{ with .Extra }}
+{{.}}{{ end }}{{ if .Unlisted }}
+[build]
+	list = "never"{{ end }}{{ with .Enclosure }}
+[enclosure]
+	url = "{{ .URL }}"{{ if .Length }}
+	length = {{ .Length }}{{ end }}
+	type = "{{ .Type }}"{{ end }}{{ with extras . }}
+{{ . }}{{ end }}
+[author]
+	name = "{{ .Author.Name }}"
+	uri = "{{ .Author.Uri }}"
+[author.image]
+	source = "{{ .Author.Image.Source }}"
+	width = "{{ .Author.Image.Width }}"
+	height = "{{ .Author.Image.Height }}"
+
++++
+{{ .Content }}
+`
+
+// The same in YAML, the default.
+const YAMLTemplate = `---
+title: "{{ .Title }}"{{ if slugInFrontMatter }}
+slug: "{{ .Slug }}"{{ end }}
+date: {{ .Published }}{{ if .Scheduled }}
+publishDate: {{ .Published }}{{ end }}
+updated: {{ .Updated }}{{ with quoteList .Tags.Labels }}
+{{ tagsKey }}: [{{ . }}]{{ end }}{{ if .Draft }}
+draft: true{{ end }}{{ if .Featured }}
+{{ featuredKey }}: true{{ end }}{{ with .Views }}
+views: {{ . }}{{ end }}{{ with .Menu }}
+menu: {{ . }}{{ end }}{{ with .BloggerID }}
+blogger_id: "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }}: {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related: [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings: [{{ . }}]{{ end }}{{ with authors . }}
+authors: [{{ . }}]{{ end }}{{ with quoteList .Aliases }}
+aliases: [{{ . }}]{{ end }}{{ with extras . }}
+{{ . }}{{ end }}
+blogimport: true {{ with .Extra }}
+{{.}}{{ end }}{{ if .Unlisted }}
+build:
+  list: never{{ end }}
+author: "{{ .Author.Name }}"{{ with .Enclosure }}
+enclosure:
+  url: "{{ .URL }}"{{ if .Length }}
+  length: {{ .Length }}{{ end }}
+  type: "{{ .Type }}"{{ end }}
+---
+
+{{ .Content }}
+`
+
+// What the templates are executed on: a Blogger entry with what the blogger2hugo command
+// works out about it. Write leaves all but Slug at their zero values, as the command
+// does without the flags that set them.
+type templateEntry struct {
+	blogger.Entry
+	Slug      string
+	Scheduled bool
+	Featured  bool
+	Views     int64
+	Comments  []uint64
+	Menu      string
+	BloggerID string
+	Params    map[string]string
+	Related   []string
+	Warnings  []string
+	Aliases   []string
+	Extra     string
+	Unlisted  bool
+	Enclosure *struct {
+		URL    string
+		Length int64
+		Type   string
+	}
+}
+
+// The templates' functions as the command has them without flags: labels under tags,
+// no slug key, and no authors list or extras.
+var templateFuncs = template.FuncMap{
+	"quoteList":         QuoteList,
+	"tagsKey":           func() string { return "tags" },
+	"featuredKey":       func() string { return "featured" },
+	"slugInFrontMatter": func() bool { return false },
+	"authors":           func(templateEntry) string { return "" },
+	"extras":            func(templateEntry) string { return "" },
+}
+
+var templates = map[string]*template.Template{
+	"yaml": template.Must(template.New("").Funcs(templateFuncs).Parse(YAMLTemplate)),
+	"toml": template.Must(template.New("").Funcs(templateFuncs).Parse(TOMLTemplate)),
+}
+
+// Quote and join strings for an inline YAML or TOML list. E.g. Go, Web -> "Go", "Web"
+func QuoteList(l []string) string {
+	quoted := make([]string, len(l))
+	for i, s := range l {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Strip trailing whitespace and end lines with eol, since Blogger mixes CRLF and stray
+// spaces that pollute diffs of the converted posts.
+func NormalizeLines(b []byte, eol string) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return []byte(strings.Join(lines, eol))
+}
@@ -0,0 +1,117 @@
+package hugo
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// How slugs are made from titles and Blogger URLs.
+type SlugOptions struct {
+	// Convert non-Latin characters to ASCII, e.g. Привет мир -> privet-mir.
+	Transliterate bool
+	// Keep slugs as they are written: their case and combining marks, instead of
+	// lowercasing them and dropping the marks. Spaces still become hyphens, and
+	// punctuation other than . _ - is still dropped.
+	KeepUnicode bool
+	// Cut slugs down to at most this many characters at a word boundary; 0 means no limit.
+	MaxLength int
+}
+
+// Take a string with any characters and replace it so the string could be used in a path.
+// E.g. Social Media -> social-media
+//
+// The string is normalized to NFC first, so a title typed where text is decomposed,
+// as on macOS, gets the same slug as one typed elsewhere.
+func (o SlugOptions) Slug(s string) string {
+	s = norm.NFC.String(s)
+	if o.Transliterate {
+		s = transliterate(s)
+	}
+	s = strings.Replace(strings.TrimSpace(s), " ", "-", -1)
+	if !o.KeepUnicode {
+		s = strings.ToLower(s)
+	}
+	slug := o.sanitize(s)
+	if o.MaxLength > 0 {
+		slug = truncateSlug(slug, o.MaxLength)
+	}
+	return slug
+}
+
+// Shorten a slug to at most n characters, cutting at the last word boundary like Blogger does.
+// E.g. the-gift-of-the-magi (n=12) -> the-gift-of
+func truncateSlug(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	cut := r[:n]
+	if r[n] != '-' {
+		for i := len(cut) - 1; i > 0; i-- {
+			if cut[i] == '-' {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRight(string(cut), "-")
+}
+
+func (o SlugOptions) sanitize(s string) string {
+	source := []rune(s)
+	target := make([]rune, 0, len(source))
+
+	for _, r := range source {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' ||
+			(o.KeepUnicode && unicode.IsMark(r)) {
+			target = append(target, r)
+		}
+	}
+	return string(target)
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Build a slug for a post with an empty or symbol-only title from the first words of
+// its HTML content, or failing that its post ID. E.g. post-1234567890
+func (o SlugOptions) FallbackSlug(content, id string) string {
+	words := strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(content, " ")))
+	if len(words) > 6 {
+		words = words[:6]
+	}
+	if slug := o.Slug(strings.Join(words, " ")); strings.Trim(slug, "-._") != "" {
+		return slug
+	}
+	return "post-" + id
+}
+
+// Longest filename stem we generate, leaving room under Windows' 260 character
+// path limit for the target directory and collision suffixes.
+const maxNameLen = 180
+
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// Make a filename stem usable on Windows: no reserved device names, no trailing dots or
+// spaces, and short enough to stay within the path length limit.
+func SafeName(s string) string {
+	if len(s) > maxNameLen {
+		s = s[:maxNameLen]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+	}
+	s = strings.TrimRight(s, ". ")
+	if base := strings.ToLower(strings.SplitN(s, ".", 2)[0]); reservedNames[base] {
+		s = "_" + s
+	}
+	return s
+}
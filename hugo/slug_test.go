@@ -0,0 +1,58 @@
+package hugo
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		opts SlugOptions
+		in   string
+		want string
+	}{
+		{SlugOptions{}, "Social Media", "social-media"},
+		{SlugOptions{}, " Hello, World! ", "hello-world"},
+		{SlugOptions{}, "v1.2_beta", "v1.2_beta"},
+		{SlugOptions{}, "Привет мир", "привет-мир"},
+		{SlugOptions{Transliterate: true}, "Привет мир", "privet-mir"},
+		// Combining marks are dropped unless kept.
+		{SlugOptions{}, "नमस्ते", "नमसत"},
+		{SlugOptions{KeepUnicode: true}, "नमस्ते", "नमस्ते"},
+		// Decomposed, as typed on macOS, and normalized, with or without marks kept.
+		{SlugOptions{KeepUnicode: true}, "Cafe\u0301", "Caf\u00e9"},
+		{SlugOptions{}, "Cafe\u0301", "caf\u00e9"},
+		{SlugOptions{}, "Cafe\u0301 au lait", "caf\u00e9-au-lait"},
+		// Kept as written, case included, but for spaces and punctuation.
+		{SlugOptions{KeepUnicode: true}, "Straße Über", "Straße-Über"},
+		{SlugOptions{KeepUnicode: true}, "Привет, Мир!", "Привет-Мир"},
+		{SlugOptions{}, "Straße Über", "straße-über"},
+		{SlugOptions{MaxLength: 12}, "The Gift of the Magi", "the-gift-of"},
+		{SlugOptions{MaxLength: 50}, "The Gift of the Magi", "the-gift-of-the-magi"},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Slug(tt.in); got != tt.want {
+			t.Errorf("%+v.Slug(%q) = %q, want %q", tt.opts, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"the-gift-of-the-magi", 12, "the-gift-of"},
+		{"the-gift-of-the-magi", 20, "the-gift-of-the-magi"},
+		{"the-gift-of-the-magi", 11, "the-gift-of"},
+		// Cut just before a hyphen.
+		{"the-gift-of-the-magi", 8, "the-gift"},
+		// No word boundary to cut at.
+		{"supercalifragilistic", 5, "super"},
+		// Counted in characters, not bytes.
+		{"привет-мир", 8, "привет"},
+	}
+	for _, tt := range tests {
+		if got := truncateSlug(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncateSlug(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package hugo
+
+import (
+	"context"
+	"sync"
+)
+
+// A Transform reworks a post or page before it's written, for cleanups particular to
+// a site: fixing up its HTML, renaming labels, adding front matter. Title, Labels,
+// Draft, Author, Params and Content are written as a Transform leaves them; the other
+// fields say which post it is.
+type Transform interface {
+	Transform(ctx context.Context, post *ConvertedPost) error
+}
+
+// A TransformFunc is a func used as a Transform.
+type TransformFunc func(ctx context.Context, post *ConvertedPost) error
+
+func (f TransformFunc) Transform(ctx context.Context, post *ConvertedPost) error {
+	return f(ctx, post)
+}
+
+var (
+	transformsMu sync.RWMutex
+	transforms   []Transform
+)
+
+// RegisterTransform adds t to the transforms run on every post and page, by Convert and
+// by the blogger2hugo command, in the order they're registered. To build the command
+// with a transform of your own, register it from an init func in a file added to it.
+func RegisterTransform(t Transform) {
+	transformsMu.Lock()
+	transforms = append(transforms, t)
+	transformsMu.Unlock()
+}
+
+// ApplyTransforms runs the registered transforms on post in turn, stopping at the first
+// that fails.
+func ApplyTransforms(ctx context.Context, post *ConvertedPost) error {
+	transformsMu.RLock()
+	ts := transforms
+	transformsMu.RUnlock()
+	for _, t := range ts {
+		if err := t.Transform(ctx, post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package hugo
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Convert a string to ASCII for use in a slug.
+// E.g. Привет мир -> Privet mir, Größe -> Groesse, 北京 -> bei-jing
+// Characters with no known transliteration are dropped; see Transliterable.
+func transliterate(s string) string {
+	var b strings.Builder
+	prevHan := false
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			prevHan = false
+			continue
+		}
+		if unicode.Is(unicode.Han, r) {
+			if py, ok := pinyin[r]; ok {
+				if prevHan {
+					b.WriteByte('-')
+				}
+				b.WriteString(py)
+				prevHan = true
+			}
+			continue
+		}
+		prevHan = false
+		if t, ok := translitTable[r]; ok {
+			b.WriteString(t)
+		} else if unicode.IsSpace(r) {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// Transliterable reports whether s can be made a slug without losing any of its Han
+// characters, which the pinyin table only has the common ones of. A slug missing some
+// of a title's words reads as a different title, so callers fall back to the post ID
+// instead. Without Transliterate, nothing is transliterated and nothing is lost.
+func (o SlugOptions) Transliterable(s string) bool {
+	if !o.Transliterate {
+		return true
+	}
+	for _, r := range s {
+		if _, ok := pinyin[r]; !ok && unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return true
+}
+
+var translitTable = map[rune]string{
+	// Latin-1 and Latin Extended-A
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "Ae", 'Å': "A", 'Æ': "AE", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ð': "D", 'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "Oe", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "Ue", 'Ý': "Y", 'Þ': "Th", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "ae", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ð': "d", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "oe", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "ue", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Ā': "A", 'ā': "a", 'Ă': "A", 'ă': "a", 'Ą': "A", 'ą': "a", 'Ć': "C", 'ć': "c",
+	'Č': "C", 'č': "c", 'Ď': "D", 'ď': "d", 'Đ': "D", 'đ': "d", 'Ē': "E", 'ē': "e",
+	'Ė': "E", 'ė': "e", 'Ę': "E", 'ę': "e", 'Ě': "E", 'ě': "e", 'Ğ': "G", 'ğ': "g",
+	'Ī': "I", 'ī': "i", 'Į': "I", 'į': "i", 'İ': "I", 'ı': "i", 'Ł': "L", 'ł': "l",
+	'Ń': "N", 'ń': "n", 'Ň': "N", 'ň': "n", 'Ō': "O", 'ō': "o", 'Ő': "O", 'ő': "o",
+	'Œ': "OE", 'œ': "oe", 'Ř': "R", 'ř': "r", 'Ś': "S", 'ś': "s", 'Ş': "S", 'ş': "s",
+	'Š': "S", 'š': "s", 'Ţ': "T", 'ţ': "t", 'Ť': "T", 'ť': "t", 'Ū': "U", 'ū': "u",
+	'Ů': "U", 'ů': "u", 'Ű': "U", 'ű': "u", 'Ų': "U", 'ų': "u", 'Ÿ': "Y", 'Ź': "Z",
+	'ź': "z", 'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z",
+
+	// Cyrillic
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "Yo", 'Ж': "Zh",
+	'З': "Z", 'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O",
+	'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U", 'Ф': "F", 'Х': "Kh", 'Ц': "Ts",
+	'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch", 'Ъ': "", 'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "Yu",
+	'Я': "Ya",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	'Є': "Ye", 'є': "ye", 'І': "I", 'і': "i", 'Ї': "Yi", 'ї': "yi", 'Ґ': "G", 'ґ': "g",
+	'Ў': "U", 'ў': "u", 'Ђ': "Dj", 'ђ': "dj", 'Ј': "J", 'ј': "j", 'Љ': "Lj", 'љ': "lj",
+	'Њ': "Nj", 'њ': "nj", 'Ћ': "C", 'ћ': "c", 'Џ': "Dz", 'џ': "dz",
+
+	// Greek
+	'Α': "A", 'Β': "V", 'Γ': "G", 'Δ': "D", 'Ε': "E", 'Ζ': "Z", 'Η': "I", 'Θ': "Th",
+	'Ι': "I", 'Κ': "K", 'Λ': "L", 'Μ': "M", 'Ν': "N", 'Ξ': "X", 'Ο': "O", 'Π': "P",
+	'Ρ': "R", 'Σ': "S", 'Τ': "T", 'Υ': "Y", 'Φ': "F", 'Χ': "Ch", 'Ψ': "Ps", 'Ω': "O",
+	'Ά': "A", 'Έ': "E", 'Ή': "I", 'Ί': "I", 'Ό': "O", 'Ύ': "Y", 'Ώ': "O", 'Ϊ': "I",
+	'Ϋ': "Y",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o", 'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o", 'ύ': "y", 'ώ': "o",
+	'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+}
+
+// Toneless pinyin for common CJK characters. This is not a full dictionary;
+// titles with characters missing here fall back to the post ID.
+var pinyin = map[rune]string{
+	'一': "yi", '二': "er", '三': "san", '四': "si", '五': "wu", '六': "liu", '七': "qi", '八': "ba",
+	'九': "jiu", '十': "shi", '百': "bai", '千': "qian", '万': "wan", '零': "ling",
+	'的': "de", '是': "shi", '不': "bu", '了': "le", '在': "zai", '有': "you", '和': "he", '这': "zhe",
+	'我': "wo", '你': "ni", '他': "ta", '她': "ta", '们': "men", '人': "ren", '个': "ge", '中': "zhong",
+	'国': "guo", '大': "da", '小': "xiao", '上': "shang", '下': "xia", '来': "lai", '去': "qu", '到': "dao",
+	'说': "shuo", '时': "shi", '年': "nian", '月': "yue", '日': "ri", '天': "tian", '地': "di", '出': "chu",
+	'生': "sheng", '活': "huo", '会': "hui", '要': "yao", '也': "ye", '就': "jiu", '能': "neng", '对': "dui",
+	'多': "duo", '少': "shao", '好': "hao", '新': "xin", '老': "lao", '家': "jia", '心': "xin", '爱': "ai",
+	'学': "xue", '习': "xi", '工': "gong", '作': "zuo", '文': "wen", '字': "zi", '书': "shu", '话': "hua",
+	'语': "yu", '言': "yan", '事': "shi", '情': "qing", '美': "mei", '食': "shi", '水': "shui", '火': "huo",
+	'山': "shan", '风': "feng", '雨': "yu", '花': "hua", '春': "chun", '夏': "xia", '秋': "qiu", '冬': "dong",
+	'东': "dong", '西': "xi", '南': "nan", '北': "bei", '京': "jing", '海': "hai", '城': "cheng", '市': "shi",
+	'旅': "lu", '行': "xing", '游': "you", '记': "ji", '博': "bo", '客': "ke", '网': "wang", '电': "dian",
+	'影': "ying", '音': "yin", '乐': "le", '歌': "ge", '画': "hua", '照': "zhao", '片': "pian", '图': "tu",
+	'朋': "peng", '友': "you", '母': "mu", '父': "fu", '子': "zi", '女': "nv", '男': "nan", '孩': "hai",
+	'猫': "mao", '狗': "gou", '路': "lu", '车': "che", '门': "men", '口': "kou", '手': "shou", '头': "tou",
+	'开': "kai", '始': "shi", '第': "di", '次': "ci", '最': "zui", '后': "hou", '前': "qian", '今': "jin",
+	'明': "ming", '昨': "zuo", '早': "zao", '晚': "wan", '夜': "ye", '见': "jian", '看': "kan", '想': "xiang",
+	'知': "zhi", '道': "dao", '光': "guang", '世': "shi", '界': "jie", '本': "ben", '自': "zi", '己': "ji",
+	'长': "chang", '高': "gao", '白': "bai", '黑': "hei", '红': "hong", '绿': "lv", '蓝': "lan", '木': "mu",
+	'茶': "cha", '酒': "jiu", '饭': "fan", '菜': "cai", '鱼': "yu", '肉': "rou", '谢': "xie", '快': "kuai",
+	'東': "dong", '國': "guo", '書': "shu", '話': "hua", '語': "yu", '愛': "ai", '學': "xue", '電': "dian",
+	'車': "che", '門': "men", '長': "chang", '記': "ji", '風': "feng", '們': "men", '這': "zhe", '說': "shuo",
+	'時': "shi", '會': "hui", '對': "dui", '見': "jian", '開': "kai", '後': "hou", '網': "wang", '樂': "le",
+}
@@ -0,0 +1,42 @@
+package hugo
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello World", "Hello World"},
+		{"Привет мир", "Privet mir"},
+		{"Größe", "Groesse"},
+		{"Crème brûlée", "Creme brulee"},
+		{"北京", "bei-jing"},
+		{"北京 2008", "bei-jing 2008"},
+		// Nothing known for it, so dropped.
+		{"☃ snow", " snow"},
+	}
+	for _, tt := range tests {
+		if got := transliterate(tt.in); got != tt.want {
+			t.Errorf("transliterate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransliterable(t *testing.T) {
+	tests := []struct {
+		o    SlugOptions
+		in   string
+		want bool
+	}{
+		{SlugOptions{Transliterate: true}, "北京 2008", true},
+		{SlugOptions{Transliterate: true}, "Привет ☃", true},
+		// 龘 has no pinyin in the table, so the slug would lose it.
+		{SlugOptions{Transliterate: true}, "北京龘", false},
+		{SlugOptions{}, "北京龘", true},
+	}
+	for _, tt := range tests {
+		if got := tt.o.Transliterable(tt.in); got != tt.want {
+			t.Errorf("%+v.Transliterable(%q) = %t, want %t", tt.o, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package hugo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Where Write puts the files it writes, by slash-separated name.
+type Output interface {
+	Create(name string) (io.WriteCloser, error)
+}
+
+// How Write writes a Hugo site's content. The zero Options write YAML front matter into
+// the current directory, as the blogger2hugo command does by default.
+type Options struct {
+	// The content section to write into, e.g. content/posts, when Output isn't set.
+	Dir string
+	// Where to write instead of Dir, e.g. into a zip.
+	Output Output
+	// The front matter format: yaml, the default, or toml.
+	Format string
+	// Where static pages go, relative to Dir; Dir itself if empty.
+	PagesDir string
+	// Leave the YYYY-MM-DD- prefix off post filenames.
+	NoDatePrefix bool
+	Slug         SlugOptions
+}
+
+// Write writes the posts and pages of exp as the blogger2hugo command writes them by
+// default: named as Convert names them, through YAMLTemplate or TOMLTemplate.
+// Registered transforms are run on each first. A post whose filename is taken by
+// another gets its Blogger post ID appended. Comments, and the links between posts, are left to the blogger2hugo
+// command. An error from ctx or a transform stops the writing; posts that can't be
+// written are skipped, and their errors returned together at the end.
+func Write(ctx context.Context, exp *blogger.Export, opts Options) error {
+	format := opts.Format
+	if format == "" {
+		format = "yaml"
+	}
+	if templates[format] == nil {
+		return fmt.Errorf("hugo: unknown front matter format %q", opts.Format)
+	}
+	out := opts.Output
+	if out == nil {
+		out = dirOutput(opts.Dir)
+	}
+	claimed := map[string]bool{}
+	var errs []error
+	for _, e := range exp.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		kind := e.Kind()
+		if kind != "post" && kind != "page" {
+			continue
+		}
+		post := convertEntry(e, opts.Slug)
+		dir, name := "", post.Slug
+		if kind == "page" {
+			dir = path.Clean(filepath.ToSlash(opts.PagesDir))
+		} else if !opts.NoDatePrefix {
+			name = post.Published.Format("2006-01-02") + "-" + name
+		}
+		name = SafeName(name)
+		// Keyed case-insensitively, as NTFS and APFS tell names apart only by more than case.
+		if key := strings.ToLower(path.Join(dir, name)); claimed[key] {
+			name += "-" + post.ID
+		}
+		claimed[strings.ToLower(path.Join(dir, name))] = true
+		post.Path = path.Join(dir, name+".md")
+		if err := ApplyTransforms(ctx, &post); err != nil {
+			return err
+		}
+		if err := writePost(out, e, post, format); err != nil {
+			errs = append(errs, fmt.Errorf("writing %s: %w", post.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func writePost(out Output, e blogger.Entry, p ConvertedPost, format string) error {
+	te := templateEntry{Entry: e, Slug: p.Slug, Params: p.Params}
+	p.ApplyTo(&te.Entry)
+	var b bytes.Buffer
+	if err := templates[format].Execute(&b, te); err != nil {
+		return err
+	}
+	w, err := out.Create(p.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(NormalizeLines(b.Bytes(), "\n")); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Writes files into the directory tree at the given path.
+type dirOutput string
+
+func (d dirOutput) Create(name string) (io.WriteCloser, error) {
+	full := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(full)
+}
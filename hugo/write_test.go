@@ -0,0 +1,76 @@
+package hugo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Files written into memory, by name.
+type memOutput map[string]*bytes.Buffer
+
+func (m memOutput) Create(name string) (io.WriteCloser, error) {
+	b := &bytes.Buffer{}
+	m[name] = b
+	return nopCloser{b}, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// The front matter of a written file, up to its closing ---.
+func frontMatterOf(b []byte) string {
+	s := string(b)
+	if i := strings.Index(s, "\n---\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// Write names posts and pages and writes their front matter as the blogger2hugo command
+// does with its default flags, as recorded in the command's golden files. Their bodies
+// differ where the command reworks the HTML, e.g. giving named anchors an id.
+func TestWriteMatchesCommand(t *testing.T) {
+	for _, name := range []string{"story", "comments"} {
+		f, err := os.Open(filepath.Join("..", "tests", "data", name+"-blogger-backup.xml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp, err := blogger.Parse(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := memOutput{}
+		if err := Write(context.Background(), exp, Options{Output: out}); err != nil {
+			t.Fatal(err)
+		}
+		if len(out) == 0 {
+			t.Fatalf("%s: nothing written", name)
+		}
+		golden, err := filepath.Glob(filepath.Join("..", "tests", "golden", name, "*.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != len(golden) {
+			t.Errorf("%s: wrote %d posts and pages, the command %d", name, len(out), len(golden))
+		}
+		for path, got := range out {
+			want, err := os.ReadFile(filepath.Join("..", "tests", "golden", name, filepath.FromSlash(path)))
+			if err != nil {
+				t.Errorf("%s: wrote %s, which the command doesn't: %s", name, path, err)
+				continue
+			}
+			if g, w := frontMatterOf(got.Bytes()), frontMatterOf(want); g != w {
+				t.Errorf("%s: %s has front matter\n%s\nwant\n%s", name, path, g, w)
+			}
+		}
+	}
+}
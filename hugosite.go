@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var hugoSite = flag.String("hugo-site", "", "Hugo site to convert into; its config sets the content directory, permalinks, taxonomies and front matter format")
+var format = flag.String("format", "yaml", "front matter format: yaml or toml")
+
+type siteConfig struct {
+	File       string
+	BaseURL    string
+	ContentDir string
+	Permalinks map[string]string
+	Taxonomies map[string]string
+}
+
+// Config files Hugo looks for, in its order of precedence.
+var siteConfigFiles = []string{"hugo.toml", "hugo.json", "config.toml", "config.json", "config/_default/hugo.toml", "config/_default/config.toml"}
+
+func loadSiteConfig(dir string) (*siteConfig, error) {
+	for _, name := range siteConfigFiles {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var values map[string]string
+		if path.Ext(name) == ".json" {
+			values, err = flattenJSON(b)
+		} else {
+			values, err = parseTOML(b)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		c := &siteConfig{File: name, ContentDir: "content", Permalinks: map[string]string{}, Taxonomies: map[string]string{}}
+		// In key order, so that of contentDir and contentdir the same one wins every time.
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := values[k]
+			switch {
+			case strings.EqualFold(k, "contentDir"):
+				c.ContentDir = v
+			case strings.EqualFold(k, "baseURL"):
+				c.BaseURL = v
+			case strings.HasPrefix(k, "permalinks."):
+				c.Permalinks[strings.TrimPrefix(k, "permalinks.")] = v
+			case strings.HasPrefix(k, "taxonomies."):
+				c.Taxonomies[strings.TrimPrefix(k, "taxonomies.")] = v
+			}
+		}
+		return c, nil
+	}
+	for _, name := range []string{"hugo.yaml", "hugo.yml", "config.yaml", "config.yml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil, fmt.Errorf("%s: YAML site configs are not supported, only TOML and JSON", name)
+		}
+	}
+	return nil, fmt.Errorf("no Hugo config found in %s", dir)
+}
+
+// Point the conversion at a Hugo site, returning the directory posts should be written to.
+// Flags given explicitly on the command line win over the site's config.
+func (c *Converter) applySiteConfig(site *siteConfig, section string) string {
+	if !flagGiven("format") && strings.HasSuffix(site.File, ".toml") {
+		*format = "toml"
+	}
+	if !flagGiven("base-url") && site.BaseURL != "" && site.BaseURL != "/" {
+		*baseURL = site.BaseURL
+	}
+	if p, ok := site.Permalinks[section]; ok {
+		c.permalinkPattern = p
+		c.slugInFrontMatter = strings.Contains(p, ":slug")
+		if !flagGiven("no-date-prefix") && strings.Contains(p, ":year") {
+			// The date is already in the URL, so keep it out of the slug.
+			*noDatePrefix = true
+		}
+	}
+	if len(site.Taxonomies) > 0 {
+		if plural, ok := site.Taxonomies["tag"]; ok {
+			c.tagsKey = plural
+		} else if plural, ok := site.Taxonomies["label"]; ok {
+			c.tagsKey = plural
+		} else {
+			singular := make([]string, 0, len(site.Taxonomies))
+			for s := range site.Taxonomies {
+				singular = append(singular, s)
+			}
+			sort.Strings(singular)
+			c.tagsKey = site.Taxonomies[singular[0]]
+		}
+	}
+	return filepath.Join(*hugoSite, filepath.FromSlash(site.ContentDir), section)
+}
+
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// Expand a Hugo permalink pattern for an entry written to name.
+// E.g. /:year/:month/:slug/ -> /2014/05/hello-world/
+func (c *Converter) expandPermalink(pattern string, e Entry, name string) string {
+	if !strings.HasPrefix(pattern, "/") {
+		pattern = "/" + pattern
+	}
+	d := time.Time(e.Published)
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if base == "index" {
+		// A page bundle, named by its directory.
+		base = path.Base(path.Dir(name))
+	}
+	section := strings.Trim(c.urlPrefix, "/")
+	r := strings.NewReplacer(
+		":yearday", strconv.Itoa(d.YearDay()),
+		":year", d.Format("2006"),
+		":monthname", strings.ToLower(d.Format("January")),
+		":month", d.Format("01"),
+		":day", d.Format("02"),
+		":slug", e.Slug,
+		":title", c.makeSlug(e.Title),
+		":contentbasename", base,
+		":filename", base,
+		":sections", section,
+		":section", section,
+	)
+	return r.Replace(pattern)
+}
+
+// Read the subset of TOML a Hugo config needs: tables, and keys with string, number
+// or boolean values. Keys come back flattened, e.g. permalinks.posts.
+func parseTOML(b []byte) (map[string]string, error) {
+	values := map[string]string{}
+	table := ""
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key := strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+		if table != "" {
+			key = table + "." + key
+		}
+		val := strings.TrimSpace(line[eq+1:])
+		switch {
+		case strings.HasPrefix(val, `"`):
+			s, err := strconv.Unquote(val[:strings.LastIndex(val, `"`)+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+			val = s
+		case strings.HasPrefix(val, "'"):
+			val = strings.SplitN(val[1:], "'", 2)[0]
+		default:
+			val = strings.TrimSpace(strings.SplitN(val, "#", 2)[0])
+		}
+		values[key] = val
+	}
+	return values, sc.Err()
+}
+
+func flattenJSON(b []byte) (map[string]string, error) {
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				walk(prefix+k+".", v)
+			case string:
+				values[prefix+k] = v
+			default:
+				values[prefix+k] = fmt.Sprint(v)
+			}
+		}
+	}
+	walk("", v)
+	return values, nil
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var bundleImages = flag.Bool("images", false, "download the Blogger-hosted images of each post and page at their original size and write it as a page bundle, <slug>/index.md beside its images, pointing the body at the copies")
+
+// How many images are downloaded at once.
+const imageDownloaders = 8
+
+// How many times an image is asked for before it's given up on, when the server
+// fails or is busy rather than saying it's gone.
+const imageAttempts = 3
+
+// An image of a page bundle, by the file name it's written as, or if it wasn't, why it
+// couldn't be.
+type bundledImage struct {
+	name string
+	err  error
+}
+
+// The size Blogger serves an image at: a path segment before its file name, such as
+// s320, s1600-h or w640-h480-rw, or the end of its URL after =, such as =s72-c.
+var (
+	imageSizeSegment = regexp.MustCompile(`^[swh]\d+(?:-[a-z0-9]+)*$`)
+	imageSizeSuffix  = regexp.MustCompile(`=[swh]\d+(?:-[a-z0-9]+)*$`)
+)
+
+// The URL of a Blogger-hosted image at its original size, which every size of it is
+// served from with s0 in place of the size.
+// E.g. https://1.bp.blogspot.com/-a/b/c/d/s320/photo.jpg -> https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg
+func originalImageURL(u *url.URL) string {
+	o := *u
+	o.RawPath = ""
+	o.Fragment = ""
+	segments := strings.Split(o.Path, "/")
+	if n := len(segments); n >= 3 && imageSizeSegment.MatchString(segments[n-2]) {
+		segments[n-2] = "s0"
+	} else if loc := imageSizeSuffix.FindStringIndex(segments[n-1]); loc != nil {
+		segments[n-1] = segments[n-1][:loc[0]] + "=s0"
+	}
+	o.Path = strings.Join(segments, "/")
+	return o.String()
+}
+
+// The file name an image at u is saved as in a bundle, without its extension if the
+// URL has none, and the extension.
+func (c *Converter) imageFileName(u *url.URL) (string, string) {
+	base := path.Base(u.Path)
+	if i := strings.LastIndex(base, "="); i > 0 {
+		base = base[:i]
+	}
+	ext := strings.ToLower(path.Ext(base))
+	if len(ext) > 6 {
+		// Part of the image's ID, not an extension.
+		ext = ""
+	}
+	stem := c.makeSlug(strings.TrimSuffix(base, path.Ext(base)))
+	if strings.Trim(stem, "-._") == "" || len(stem) > 60 {
+		// Blogger's newer URLs end in a long ID rather than the name uploaded.
+		stem = "image"
+	}
+	return stem, ext
+}
+
+// Extensions for the image types Blogger serves, for URLs without one.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg", "image/png": ".png", "image/gif": ".gif", "image/webp": ".webp",
+	"image/svg+xml": ".svg", "image/bmp": ".bmp", "image/heic": ".heic", "image/avif": ".avif",
+}
+
+// Download the Blogger-hosted images in the posts and pages, at their original size,
+// into the directory of the page bundle each is written as. Images a Takeout holds are
+// copied from it instead. What became of each is kept for writePost to point the body
+// at; those that couldn't be had stay linked where they are.
+func (c *Converter) downloadImages(ctx context.Context) {
+	type download struct {
+		dir, url, written, stem, ext string
+		media                        *zip.File
+		image                        *bundledImage
+	}
+	var downloads []download
+	c.bundledImages = map[string]map[string]*bundledImage{}
+	// File names taken in each bundle, which are unique whatever the extension.
+	stems := map[string]map[string]bool{}
+	for _, e := range c.exp.Entries {
+		if e.Path == "" || skipHidden(e) {
+			continue
+		}
+		// Translations share the bundle, and so its images.
+		dir := path.Dir(e.Path)
+		images := c.bundledImages[dir]
+		if images == nil {
+			images = map[string]*bundledImage{}
+			c.bundledImages[dir] = images
+			stems[dir] = map[string]bool{"index": true, "_index": true}
+		}
+		for _, m := range linkAttrPattern.FindAllStringSubmatch(entryContent(e), -1) {
+			raw := linkAttrURL(m)
+			u, err := url.Parse(raw)
+			if err != nil || !bloggerMediaHost(u.Host) {
+				continue
+			}
+			original := originalImageURL(u)
+			if images[original] != nil {
+				continue
+			}
+			d := download{dir: dir, url: original, written: raw, image: &bundledImage{}}
+			if f, ok := c.exp.Media[path.Base(u.Path)]; ok {
+				name := path.Base(f.Name)
+				d.media, d.stem, d.ext = f, strings.TrimSuffix(name, path.Ext(name)), path.Ext(name)
+			} else {
+				d.stem, d.ext = c.imageFileName(u)
+			}
+			stem := d.stem
+			for n := 2; stems[dir][strings.ToLower(d.stem)]; n++ {
+				d.stem = fmt.Sprintf("%s-%d", stem, n)
+			}
+			stems[dir][strings.ToLower(d.stem)] = true
+			images[original] = d.image
+			downloads = append(downloads, d)
+		}
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	var wg sync.WaitGroup
+	queue := make(chan download)
+	for i := 0; i < imageDownloaders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				var name string
+				var err error
+				if d.media != nil {
+					name = d.stem + d.ext
+					c.mediaMu.Lock()
+					err = c.copyZipFile(d.media, path.Join(d.dir, name))
+					c.mediaMu.Unlock()
+				} else {
+					name, err = c.downloadImage(ctx, client, d.url, d.dir, d.stem, d.ext)
+					if err != nil && d.written != d.url && ctx.Err() == nil {
+						// Some sizes are all there is.
+						if n, werr := c.downloadImage(ctx, client, d.written, d.dir, d.stem, d.ext); werr == nil {
+							name, err = n, nil
+						}
+					}
+				}
+				if ctx.Err() != nil {
+					// Not downloaded, rather than failed.
+					continue
+				}
+				if err != nil {
+					log.Printf("Failed downloading %s: %s", d.written, err)
+					d.image.err = err
+					continue
+				}
+				d.image.name = name
+			}
+		}()
+	}
+queue:
+	for _, d := range downloads {
+		select {
+		case queue <- d:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(queue)
+	wg.Wait()
+	failed := 0
+	for _, d := range downloads {
+		if d.image.name == "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Printf("%d of %d images could not be downloaded and are linked on Blogger's servers.", failed, len(downloads))
+	}
+}
+
+// Download the image at u into the bundle directory dir as stem and its extension, or
+// the extension of its type, returning the name it's saved as. The image is held in a
+// scratch file until it's all in, then written out.
+func (c *Converter) downloadImage(ctx context.Context, client *http.Client, u, dir, stem, ext string) (string, error) {
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		resp, err = request(ctx, client, "GET", u)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			break
+		}
+		busy := false
+		if err == nil {
+			resp.Body.Close()
+			busy = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+			err = fmt.Errorf("downloading %s: %s", u, resp.Status)
+		} else {
+			var ne net.Error
+			busy = errors.As(err, &ne) && ne.Timeout()
+		}
+		if !busy || attempt == imageAttempts || ctx.Err() != nil {
+			return "", err
+		}
+		select {
+		case <-time.After(time.Duration(attempt) * time.Second):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	defer resp.Body.Close()
+	if ext == "" {
+		typ, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		ext = imageExtensions[typ]
+		if ext == "" {
+			ext = ".jpg"
+		}
+	}
+	f, err := newScratch()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", fmt.Errorf("downloading %s: %w", u, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	name := stem + ext
+	c.mediaMu.Lock()
+	defer c.mediaMu.Unlock()
+	w, err := c.create(path.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return "", err
+	}
+	return name, w.Close()
+}
+
+// Point the entry's Blogger-hosted images at the copies downloadImages put in its
+// bundle. Also returns the images left on Blogger's servers.
+func (c *Converter) useBundledImages(e Entry) (string, []string) {
+	images := c.bundledImages[path.Dir(e.Path)]
+	var missed []string
+	seen := map[string]bool{}
+	content := linkAttrPattern.ReplaceAllStringFunc(e.Content, func(attr string) string {
+		m := linkAttrPattern.FindStringSubmatch(attr)
+		raw := linkAttrURL(m)
+		u, err := url.Parse(raw)
+		if err != nil || !bloggerMediaHost(u.Host) {
+			return attr
+		}
+		img := images[originalImageURL(u)]
+		if img == nil || img.name == "" {
+			if !seen[raw] {
+				seen[raw] = true
+				why := "it wasn't downloaded"
+				if img != nil && img.err != nil {
+					why = img.err.Error()
+				}
+				missed = append(missed, fmt.Sprintf("image %s couldn't be downloaded, so is still on Blogger's servers: %s", raw, why))
+			}
+			return attr
+		}
+		// Relative to the page, which is served from the bundle's directory.
+		local := (&url.URL{Path: img.name}).String()
+		return strings.Replace(attr, m[1][1:len(m[1])-1], html.EscapeString(local), 1)
+	})
+	return content, missed
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestOriginalImageURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://1.bp.blogspot.com/-a/b/c/d/s320/photo.jpg", "https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg"},
+		{"https://1.bp.blogspot.com/-a/b/c/d/s1600-h/photo.jpg", "https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg"},
+		{"https://blogger.googleusercontent.com/img/b/R29v/w640-h480-rw/photo.jpg", "https://blogger.googleusercontent.com/img/b/R29v/s0/photo.jpg"},
+		{"https://blogger.googleusercontent.com/img/a/AVvXsEh=s72-c", "https://blogger.googleusercontent.com/img/a/AVvXsEh=s0"},
+		{"https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg", "https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg"},
+		// Without a size, it's the original already.
+		{"https://1.bp.blogspot.com/-a/b/c/d/photo.jpg", "https://1.bp.blogspot.com/-a/b/c/d/photo.jpg"},
+		{"https://1.bp.blogspot.com/-a/b/c/d/s320/photo.jpg#top", "https://1.bp.blogspot.com/-a/b/c/d/s0/photo.jpg"},
+		{"https://1.bp.blogspot.com/-a/b/c/d/s320/my%20photo.jpg", "https://1.bp.blogspot.com/-a/b/c/d/s0/my%20photo.jpg"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := originalImageURL(u); got != tt.want {
+			t.Errorf("originalImageURL(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+var inputFormat = flag.String("input-format", "auto", "format of the export: auto (detect it), blogger, takeout (a Google Takeout zip), wordpress, ghost, medium (the export zip), tumblr, livejournal, movabletype (also TypePad), feed (any Atom or RSS feed), or ir (written by export-ir)")
+
+// An Importer reads a blog export into the Blogger shaped Export the rest of the
+// conversion works on. Posts carry their kind and labels as Blogger categories,
+// IDs ending in post-<number>, and comments link to their post the way Blogger's do.
+type Importer interface {
+	// Whether b looks like an export this importer reads.
+	Detect(b []byte) bool
+	Import(b []byte) (Export, error)
+}
+
+// A zipImporter reads exports that come as a zip straight from the zip, so what's
+// bundled in it, often gigabytes of images, isn't read into memory.
+type zipImporter interface {
+	DetectZip(z *zip.Reader) bool
+	ImportZip(z *zip.Reader) (Export, error)
+}
+
+// Importers by -input-format, tried in this order when detecting.
+var importerNames = []string{"blogger", "takeout", "ir", "wordpress", "ghost", "medium", "tumblr", "livejournal", "movabletype", "feed"}
+var importers = map[string]Importer{
+	"blogger":     bloggerImporter{},
+	"takeout":     takeoutImporter{},
+	"ir":          irImporter{},
+	"wordpress":   wxrImporter{},
+	"ghost":       ghostImporter{},
+	"medium":      mediumImporter{},
+	"tumblr":      tumblrImporter{},
+	"livejournal": ljImporter{},
+	"movabletype": mtImporter{},
+	"feed":        feedImporter{},
+}
+
+// Read an export from r, named name in messages. Blogger exports are decoded as they're
+// read and zips are read in place; the other formats are read whole first.
+func importExport(ctx context.Context, name string, r io.Reader) (Export, error) {
+	br := bufio.NewReaderSize(utf8Reader(name, r), sniffLen)
+	head, _ := br.Peek(sniffLen)
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		z, f, err := openZip(r, br)
+		if err != nil {
+			return Export{}, err
+		}
+		exp, err := importZip(z)
+		if f != nil {
+			if err != nil {
+				f.Close()
+				return Export{}, err
+			}
+			exp.files = append(exp.files, f)
+		}
+		return exp, err
+	}
+	if (*inputFormat == "auto" || *inputFormat == "blogger") && streamable(head) {
+		return decodeBlogger(ctx, br)
+	}
+	b, err := io.ReadAll(br)
+	if err != nil {
+		return Export{}, err
+	}
+	if *inputFormat != "auto" {
+		return importers[*inputFormat].Import(b)
+	}
+	for _, name := range importerNames {
+		if importers[name].Detect(b) {
+			return importers[name].Import(b)
+		}
+	}
+	return Export{}, fmt.Errorf("unrecognised export format, use -input-format to pick one")
+}
+
+// Open a zip export to read in place: a local file is opened again, one already in
+// memory is read from there, anything else (standard input, a download) is first copied
+// to a scratch file. It stays open for the conversion, as bundled media are copied from
+// it while posts are written; there's nothing to close for one in memory.
+func openZip(r, rest io.Reader) (*zip.Reader, io.Closer, error) {
+	if ra, ok := r.(interface {
+		io.ReaderAt
+		Size() int64
+	}); ok {
+		z, err := zip.NewReader(ra, ra.Size())
+		return z, nil, err
+	}
+	var f scratchFile
+	if in, ok := r.(*os.File); ok {
+		if info, err := in.Stat(); err == nil && info.Mode().IsRegular() {
+			if f, err = os.Open(in.Name()); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if f == nil {
+		var err error
+		if f, err = newScratch(); err != nil {
+			return nil, nil, err
+		}
+		if _, err := io.Copy(f, rest); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err == nil {
+		var z *zip.Reader
+		if z, err = zip.NewReader(f, size); err == nil {
+			return z, f, nil
+		}
+	}
+	f.Close()
+	return nil, nil, err
+}
+
+func importZip(z *zip.Reader) (Export, error) {
+	if *inputFormat != "auto" {
+		zi, ok := importers[*inputFormat].(zipImporter)
+		if !ok {
+			return Export{}, fmt.Errorf("-input-format %s exports aren't zips", *inputFormat)
+		}
+		return zi.ImportZip(z)
+	}
+	for _, name := range importerNames {
+		if zi, ok := importers[name].(zipImporter); ok && zi.DetectZip(z) {
+			return zi.ImportZip(z)
+		}
+	}
+	return Export{}, fmt.Errorf("unrecognised export format, use -input-format to pick one")
+}
+
+// Whether an export starting with head is a Blogger export in the classic format, which can
+// be decoded entry by entry. Takeout's feeds declare their namespace on the root element.
+func streamable(head []byte) bool {
+	return rootElement(head) == "feed" && bytes.Contains(head, []byte("blogger.com")) && !bytes.Contains(head, []byte(takeoutNS))
+}
+
+// The name of the first element of an XML document, e.g. feed or rss.
+func rootElement(b []byte) string {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local
+		}
+	}
+}
+
+type bloggerImporter struct{}
+
+func (bloggerImporter) Detect(b []byte) bool {
+	return rootElement(b) == "feed" && bytes.Contains(b, []byte("blogger.com"))
+}
+
+func (bloggerImporter) Import(b []byte) (Export, error) {
+	if bytes.Contains(b, []byte(takeoutNS)) {
+		return importTakeoutFeed(b)
+	}
+	return decodeBlogger(context.Background(), bytes.NewReader(b))
+}
+
+func decodeBlogger(ctx context.Context, r io.Reader) (Export, error) {
+	var e Export
+	var spill spillFile
+	p := newParser()
+	p.Entry = func(be blogger.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := Entry{Entry: be}
+		if err := spill.keep(&entry); err != nil {
+			return err
+		}
+		e.Entries = append(e.Entries, entry)
+		return nil
+	}
+	be, err := p.Parse(r)
+	if spill.f != nil {
+		if err != nil {
+			spill.f.Close()
+		} else {
+			e.files = append(e.files, spill.f)
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return Export{}, err
+		}
+		return Export{}, recoverHint(err)
+	}
+	e.Title = be.Title
+	return e, nil
+}
+
+// Most platforms number posts and comments separately, but the converter looks both
+// up by one ID, so importers move comment IDs above any post ID.
+const commentIDBase = 1 << 40
+
+// Categories giving an imported entry its Blogger kind and labels.
+func kindTag(kind string) Tag {
+	return Tag{Name: kindPrefix + kind, Scheme: blogger.KindScheme}
+}
+
+func labelTag(name string) Tag {
+	return Tag{Name: name, Scheme: blogger.LabelScheme}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// One line for each entry of an import: its kind, ID, title, author and date, and
+// whether it's a draft, its labels and what it replies to.
+func summarize(exp Export) []string {
+	var lines []string
+	for _, e := range exp.Entries {
+		line := fmt.Sprintf("%s %s %q by %s at %s", e.Kind(), e.ID, e.Title, e.Author.Name, time.Time(e.Published).UTC().Format(time.RFC3339))
+		if e.Draft {
+			line += " draft"
+		}
+		var labels []string
+		for _, t := range e.Tags {
+			if t.Scheme == "http://www.blogger.com/atom/ns#" {
+				labels = append(labels, t.Name)
+			}
+		}
+		if len(labels) > 0 {
+			line += " labels " + strings.Join(labels, ", ")
+		}
+		if e.Source.Source != "" {
+			line += " on " + e.Source.Source
+		}
+		for _, l := range e.Links {
+			if l.Rel == "related" {
+				line += " replying to " + l.Link
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Import b as the given -input-format and compare its summary with want.
+func testImport(t *testing.T, format, b string, want []string) {
+	t.Helper()
+	defer func(f string) { *inputFormat = f }(*inputFormat)
+	*inputFormat = format
+	exp, err := importExport(context.Background(), "export", strings.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summarize(exp); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+const testBloggerExport = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:thr='http://purl.org/syndication/thread/1.0'>
+<title type='text'>Stories</title>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-100</id>
+<published>2014-05-19T23:36:00.000-07:00</published>
+<updated>2014-05-20T01:00:00.000-07:00</updated>
+<category scheme='http://schemas.google.com/g/2005#kind' term='http://schemas.google.com/blogger/2008/kind#post'/>
+<category scheme='http://www.blogger.com/atom/ns#' term='O. Henry'/>
+<title type='text'>The Gift of the Magi</title>
+<content type='html'>&lt;p&gt;One dollar and eighty-seven cents.&lt;/p&gt;</content>
+<author><name>Della</name></author>
+</entry>
+</feed>`
+
+func TestImportDetect(t *testing.T) {
+	testImport(t, "auto", testBloggerExport, []string{
+		`post tag:blogger.com,1999:blog-1.post-100 "The Gift of the Magi" by Della at 2014-05-20T06:36:00Z labels O. Henry`,
+	})
+	testImport(t, "auto", testWXR, testWXRWant)
+	defer func(f string) { *inputFormat = f }(*inputFormat)
+	*inputFormat = "auto"
+	if _, err := importExport(context.Background(), "export", strings.NewReader("<html><body>Not an export</body></html>")); err == nil {
+		t.Error("no error for a document that isn't an export")
+	}
+}
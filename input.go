@@ -0,0 +1,175 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var inputHeader = flag.String("input-header", "", "with an http(s) URL as the xmlfile, a header to send when downloading it, e.g. \"Authorization: Bearer <token>\"")
+
+// Open the export to convert: a file, - for standard input, or an http(s) URL,
+// whose body is read as it downloads.
+func openInput(ctx context.Context, name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	if isURL(name) {
+		return download(ctx, name)
+	}
+	return os.Open(name)
+}
+
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// A request that ctx can cancel.
+func request(ctx context.Context, client *http.Client, method, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+func download(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if *inputHeader != "" {
+		k, v, ok := strings.Cut(*inputHeader, ":")
+		if !ok {
+			return nil, fmt.Errorf("-input-header should look like Name: value, got %q", *inputHeader)
+		}
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", u, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Split the arguments into exports and the target directory, which is last. Where the
+// target directory is optional, a last argument that is an export isn't taken for it.
+// Without a target directory, "." is returned.
+func splitArgs(args []string, dirOptional bool) (inputs []string, dir string, dirGiven bool) {
+	if len(args) == 0 || len(args) == 1 && !dirOptional {
+		return args, ".", false
+	}
+	last := args[len(args)-1]
+	if len(args) == 1 || dirOptional && isInput(last) {
+		return args, ".", false
+	}
+	return args[:len(args)-1], last, true
+}
+
+func isInput(name string) bool {
+	if name == "-" || isURL(name) {
+		return true
+	}
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Read every export and merge them. An entry found in more than one (the same Blogger
+// post in exports taken months apart) is kept once, in its most recently updated version.
+func loadExports(ctx context.Context, inputs []string) (Export, error) {
+	var merged Export
+	seen := map[string]int{}
+	replaced := 0
+	for i, name := range inputs {
+		var e Export
+		var err error
+		if i == 0 && *bloggerAPI != "" {
+			e, err = fetchBloggerAPI(ctx)
+		} else {
+			var r io.ReadCloser
+			if r, err = openInput(ctx, name); err == nil {
+				e, err = importExport(ctx, name, r)
+				r.Close()
+			}
+		}
+		if err != nil {
+			merged.Close()
+			return Export{}, fmt.Errorf("%s: %w", name, err)
+		}
+		merged.files = append(merged.files, e.files...)
+		for name, f := range e.Media {
+			if _, dup := merged.Media[name]; !dup {
+				if merged.Media == nil {
+					merged.Media = map[string]*zip.File{}
+				}
+				merged.Media[name] = f
+			}
+		}
+		if merged.PageViews == nil {
+			merged.PageViews = e.PageViews
+		}
+		if i == 0 {
+			merged.Title = e.Title
+		} else {
+			offsetIDs(e, uint64(i)*inputIDStride)
+		}
+		for _, entry := range e.Entries {
+			k, ok := seen[entry.ID]
+			if !ok {
+				seen[entry.ID] = len(merged.Entries)
+				merged.Entries = append(merged.Entries, entry)
+				continue
+			}
+			if time.Time(entry.Updated).After(time.Time(merged.Entries[k].Updated)) {
+				merged.Entries[k] = entry
+				replaced++
+			}
+		}
+	}
+	if len(inputs) > 1 {
+		log.Printf("Merged %d exports into %d entries, %d updated by a later export.", len(inputs), len(merged.Entries), replaced)
+	}
+	return merged, nil
+}
+
+// Other platforms number their posts from 1, so the numbers of later exports are moved
+// up by this much a file to keep them apart. Blogger's IDs are unique already.
+const inputIDStride = 1 << 44
+
+func offsetIDs(e Export, off uint64) {
+	shift := func(s string) string {
+		i := strings.LastIndexAny(s, "-/")
+		n, err := strconv.ParseUint(s[i+1:], 10, 64)
+		if err != nil {
+			return s
+		}
+		return s[:i+1] + strconv.FormatUint(n+off, 10)
+	}
+	for k := range e.Entries {
+		entry := &e.Entries[k]
+		if strings.HasPrefix(entry.ID, "tag:blogger.com") {
+			continue
+		}
+		entry.ID = shift(entry.ID)
+		if entry.Source.Source != "" {
+			entry.Source.Source = shift(entry.Source.Source)
+		}
+		for l := range entry.Links {
+			if entry.Links[l].Rel == "related" {
+				entry.Links[l].Link = shift(entry.Links[l].Link)
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// The intermediate representation export-ir writes and import-ir converts: every entry
+// as it is once the exports are read and merged, before anything is worked out for
+// Hugo. It's versioned, so the IR written by one release can be told from another's.
+const (
+	irFormat  = "blogger2hugo-ir"
+	irVersion = 1
+)
+
+type irFile struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Title   string    `json:"title"`
+	Entries []irEntry `json:"entries"`
+}
+
+// An entry in the IR. Its kind and labels are broken out of Blogger's categories so they
+// can be edited as they are; categories holds any others. Coauthors are any authors
+// after the first, on team blogs.
+type irEntry struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Published  time.Time         `json:"published"`
+	Updated    time.Time         `json:"updated"`
+	Draft      bool              `json:"draft,omitempty"`
+	Title      string            `json:"title"`
+	Slug       string            `json:"slug,omitempty"`
+	Labels     []string          `json:"labels,omitempty"`
+	Categories []irCategory      `json:"categories,omitempty"`
+	Author     irAuthor          `json:"author"`
+	Coauthors  []irAuthor        `json:"coauthors,omitempty"`
+	InReplyTo  *irLink           `json:"in_reply_to,omitempty"`
+	Links      []irLink          `json:"links,omitempty"`
+	Hidden     string            `json:"hidden,omitempty"`
+	Deleted    string            `json:"deleted,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Extensions []irExtension     `json:"extensions,omitempty"`
+	Content    string            `json:"content"`
+}
+
+// An element of an entry from another app's namespace, kept as it was.
+type irExtension struct {
+	Namespace string            `json:"namespace,omitempty"`
+	Name      string            `json:"name"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	Inner     string            `json:"inner,omitempty"`
+}
+
+type irCategory struct {
+	Term   string `json:"term"`
+	Scheme string `json:"scheme"`
+}
+
+type irAuthor struct {
+	Name  string   `json:"name"`
+	URI   string   `json:"uri,omitempty"`
+	Image *irImage `json:"image,omitempty"`
+}
+
+type irImage struct {
+	Src    string `json:"src"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+type irLink struct {
+	Rel    string `json:"rel"`
+	Href   string `json:"href"`
+	Source string `json:"source,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Length string `json:"length,omitempty"`
+}
+
+// Read and merge the exports in inputs and write them to name as IR, or to standard
+// output for -.
+func exportIR(ctx context.Context, inputs []string, name string) error {
+	exp, err := loadExports(ctx, inputs)
+	if err != nil {
+		return err
+	}
+	defer exp.Close()
+	if len(exp.Media) > 0 {
+		log.Printf("The %d images and videos bundled with the export aren't kept in the IR; converted from it, posts link to Blogger's copies.", len(exp.Media))
+	}
+	w := io.Writer(os.Stdout)
+	var f *os.File
+	if name != "-" {
+		if f, err = os.Create(name); err != nil {
+			return err
+		}
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	err = writeIR(exp, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(name)
+		}
+	}
+	if err == nil && name != "-" {
+		log.Printf("Wrote %d entries to %s.", len(exp.Entries), name)
+	}
+	return err
+}
+
+func writeIR(exp Export, w io.Writer) error {
+	ir := irFile{Format: irFormat, Version: irVersion, Title: exp.Title, Entries: make([]irEntry, 0, len(exp.Entries))}
+	for _, e := range exp.Entries {
+		content, err := readContent(e)
+		if err != nil {
+			return fmt.Errorf("entry %s: %w", e.ID, err)
+		}
+		ie := irEntry{
+			ID:        e.ID,
+			Kind:      e.Kind(),
+			Published: time.Time(e.Published),
+			Updated:   time.Time(e.Updated),
+			Draft:     bool(e.Draft),
+			Title:     e.Title,
+			Slug:      e.Slug,
+			Labels:    e.Tags.Labels(),
+			Hidden:    e.Hidden,
+			Deleted:   e.Deleted,
+			Params:    e.Params,
+			Content:   content,
+		}
+		for _, t := range e.Tags {
+			if t.Scheme != blogger.KindScheme && t.Scheme != blogger.LabelScheme {
+				ie.Categories = append(ie.Categories, irCategory{t.Name, t.Scheme})
+			}
+		}
+		ie.Author = toIRAuthor(e.Author)
+		for i, a := range e.Authors {
+			if i > 0 {
+				ie.Coauthors = append(ie.Coauthors, toIRAuthor(a))
+			}
+		}
+		if s := e.Source; s != (Reply{}) {
+			ie.InReplyTo = &irLink{s.Rel, s.Link, s.Source, s.Type, s.Length}
+		}
+		for _, l := range e.Links {
+			ie.Links = append(ie.Links, irLink{l.Rel, l.Link, l.Source, l.Type, l.Length})
+		}
+		for _, x := range e.Extensions {
+			ix := irExtension{Namespace: x.XMLName.Space, Name: x.XMLName.Local, Inner: x.Inner}
+			for _, a := range x.Attrs {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				if ix.Attrs == nil {
+					ix.Attrs = map[string]string{}
+				}
+				ix.Attrs[a.Name.Local] = a.Value
+			}
+			ie.Extensions = append(ie.Extensions, ix)
+		}
+		ir.Entries = append(ir.Entries, ie)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ir)
+}
+
+func toIRAuthor(a Author) irAuthor {
+	ia := irAuthor{Name: a.Name, URI: a.Uri}
+	if a.Image.Source != "" {
+		ia.Image = &irImage{a.Image.Source, a.Image.Width, a.Image.Height}
+	}
+	return ia
+}
+
+func fromIRAuthor(ia irAuthor) Author {
+	a := Author{Name: ia.Name, Uri: ia.URI}
+	if img := ia.Image; img != nil {
+		a.Image = Image{Width: img.Width, Height: img.Height, Source: img.Src}
+	}
+	return a
+}
+
+type irImporter struct{}
+
+func (irImporter) Detect(b []byte) bool {
+	if len(b) > 512 {
+		b = b[:512]
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) && bytes.Contains(b, []byte(`"`+irFormat+`"`))
+}
+
+func (irImporter) Import(b []byte) (Export, error) {
+	var ir irFile
+	if err := json.Unmarshal(b, &ir); err != nil {
+		return Export{}, err
+	}
+	if ir.Format != irFormat {
+		return Export{}, fmt.Errorf("not blogger2hugo IR, whose format is %q", irFormat)
+	}
+	if ir.Version > irVersion {
+		return Export{}, fmt.Errorf("IR version %d is newer than this blogger2hugo reads (%d)", ir.Version, irVersion)
+	}
+	var exp Export
+	exp.Title = ir.Title
+	for i, ie := range ir.Entries {
+		if ie.ID == "" {
+			return Export{}, fmt.Errorf("entry %d has no id", i+1)
+		}
+		var e Entry
+		e.ID = ie.ID
+		e.Published = Date(ie.Published)
+		e.Updated = Date(ie.Updated)
+		e.Draft = Draft(ie.Draft)
+		e.Title = ie.Title
+		e.Slug = ie.Slug
+		e.Content = ie.Content
+		if ie.Kind != "" {
+			e.Tags = Tags{kindTag(ie.Kind)}
+		}
+		for _, l := range ie.Labels {
+			e.Tags = append(e.Tags, labelTag(l))
+		}
+		for _, c := range ie.Categories {
+			e.Tags = append(e.Tags, Tag{Name: c.Term, Scheme: c.Scheme})
+		}
+		e.Author = fromIRAuthor(ie.Author)
+		if len(ie.Coauthors) > 0 {
+			e.Authors = []Author{e.Author}
+			for _, a := range ie.Coauthors {
+				e.Authors = append(e.Authors, fromIRAuthor(a))
+			}
+		}
+		if r := ie.InReplyTo; r != nil {
+			e.Source = Reply{Rel: r.Rel, Link: r.Href, Source: r.Source, Type: r.Type, Length: r.Length}
+		}
+		for _, l := range ie.Links {
+			e.Links = append(e.Links, Reply{Rel: l.Rel, Link: l.Href, Source: l.Source, Type: l.Type, Length: l.Length})
+		}
+		for _, ix := range ie.Extensions {
+			x := Extension{XMLName: xml.Name{Space: ix.Namespace, Local: ix.Name}, Inner: ix.Inner}
+			names := make([]string, 0, len(ix.Attrs))
+			for name := range ix.Attrs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				x.Attrs = append(x.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: ix.Attrs[name]})
+			}
+			e.Extensions = append(e.Extensions, x)
+		}
+		e.Hidden = ie.Hidden
+		e.Deleted = ie.Deleted
+		e.Params = ie.Params
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
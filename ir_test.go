@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// Convert exp into memory with the default flags.
+func convertToMem(t *testing.T, exp Export) *MemFS {
+	t.Helper()
+	c := newConverter()
+	mem := &MemFS{}
+	c.out = mem
+	c.exp = exp
+	if err := c.convert(context.Background(), false, ""); err != nil {
+		t.Fatal(err)
+	}
+	return mem
+}
+
+// An export written as IR and read back converts to the same files.
+func TestIRRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	const fixture = "tests/data/comments-blogger-backup.xml"
+	exp, err := loadExports(ctx, []string{fixture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ir bytes.Buffer
+	if err := writeIR(exp, &ir); err != nil {
+		t.Fatal(err)
+	}
+	back, err := importExport(ctx, "blog.json", bytes.NewReader(ir.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, err = loadExports(ctx, []string{fixture}); err != nil {
+		t.Fatal(err)
+	}
+	want, got := convertToMem(t, exp), convertToMem(t, back)
+	if w, g := strings.Join(want.Names(), " "), strings.Join(got.Names(), " "); w != g {
+		t.Fatalf("converted from IR, wrote %s, want %s", g, w)
+	}
+	for _, name := range want.Names() {
+		w, _ := want.ReadFile(name)
+		g, _ := got.ReadFile(name)
+		if !bytes.Equal(w, g) {
+			t.Errorf("%s converted from IR: %s", name, firstDifference(w, g))
+		}
+	}
+}
+
+func TestIRVersion(t *testing.T) {
+	_, err := (irImporter{}).Import([]byte(`{"format": "blogger2hugo-ir", "version": 99, "entries": []}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("IR version 99: got %v, want an error saying it's newer", err)
+	}
+	if _, err := (irImporter{}).Import([]byte(`{"format": "blogger2hugo-ir", "version": 1, "entries": [{"title": "No ID"}]}`)); err == nil {
+		t.Error("IR entry without an id: got no error")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Jekyll's posts: _posts/YYYY-MM-DD-slug.md, or _drafts/slug.md for drafts, and
+// pages wherever they're put in the site. Its front matter is always YAML.
+type jekyllTarget struct{}
+
+var jekyllTempl = `---
+layout: {{ if eq .Kind "page" }}page{{ else }}post{{ end }}
+title: "{{ .Title }}"
+date: {{ .Published }}
+last_modified_at: {{ .Updated }}{{ with quoteList .Tags.Labels }}
+tags: [{{ . }}]{{ end }}{{ if .Featured }}
+{{ featuredKey }}: true{{ end }}{{ with .Views }}
+views: {{ . }}{{ end }}{{ if .Unlisted }}
+hidden: true{{ end }}{{ with .BloggerID }}
+blogger_id: "{{ . }}"{{ end }}{{ with .URL }}
+blogger_orig_url: "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }}: {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related: [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings: [{{ . }}]{{ end }}{{ with extras . }}
+{{ . }}{{ end }}{{ with .Extra }}
+{{.}}{{ end }}
+author: "{{ .Author.Name }}"{{ with .Enclosure }}
+enclosure:
+  url: "{{ .URL }}"{{ if .Length }}
+  length: {{ .Length }}{{ end }}
+  type: "{{ .Type }}"{{ end }}
+---
+
+{{ liquidRaw .Content }}
+`
+
+func (jekyllTarget) Template() string { return jekyllTempl }
+
+func (jekyllTarget) PlacePost(c *Converter, e *Entry) error {
+	prefix := e.Published.String()[:10] + "-"
+	dir := "_posts"
+	if e.Draft {
+		// Jekyll dates drafts itself, from when the file was last changed.
+		dir, prefix = "_drafts", ""
+	}
+	name, err := c.claimPath(dir, prefix+c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = strings.TrimPrefix(name, prefix)
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (jekyllTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := path.Clean(filepath.ToSlash(*pagesDir))
+	name, err := c.claimPath(dir, c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// Jekyll's default permalinks: /2014/05/19/hello.html for _posts/2014-05-19-hello.md,
+// and /about.html for about.md.
+func (jekyllTarget) Permalink(c *Converter, e Entry, name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	dir, base := path.Split(name)
+	if dir == "_posts/" || dir == "_drafts/" {
+		date := e.Published.String()[:10]
+		base = strings.TrimPrefix(base, date+"-")
+		return "/" + strings.ReplaceAll(date, "-", "/") + "/" + base + ".html"
+	}
+	if base == "index" {
+		return "/" + dir
+	}
+	return "/" + name + ".html"
+}
+
+// Comments are written by post once the posts have their slugs, see WriteSite.
+func (jekyllTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+// Write each post's comments into _data/comments/<slug>/, one file a comment, the way
+// Staticman lays them out and the themes that show its comments read them.
+func (jekyllTarget) WriteSite(c *Converter) error {
+	var errs []error
+	for k, post := range c.exp.Entries {
+		if post.Path == "" || len(post.Children) == 0 {
+			continue
+		}
+		slug := postFileSlug(post)
+		for _, i := range c.treeSort(k) {
+			if err := c.writeJekyllComment(slug, post, c.exp.Entries[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Converter) writeJekyllComment(slug string, post, e Entry) error {
+	name := path.Join("_data", "comments", slug, "c"+e.ID+".yml")
+	f, err := c.create(name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "_id: %q\nname: %q\n", e.ID, strings.TrimSpace(e.Author.Name))
+	if e.Author.Uri != "" {
+		fmt.Fprintf(f, "url: %q\n", e.Author.Uri)
+	}
+	if e.Reply != 0 && strconv.FormatUint(e.Reply, 10) != post.ID {
+		fmt.Fprintf(f, "replying_to: \"%d\"\n", e.Reply)
+	}
+	_, err = fmt.Fprintf(f, "date: %s\nmessage: %q\n", e.Published, entryContent(e))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Wrap content that has what Liquid would take for its tags in a raw block, so Jekyll
+// shows it as written. Eleventy's Liquid and Hexo's Nunjucks read the same block.
+func liquidRaw(content string) string {
+	if strings.Contains(content, "{{") || strings.Contains(content, "{%") {
+		return "{% raw %}" + content + "{% endraw %}"
+	}
+	return content
+}
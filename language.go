@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"html"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var multilingual = flag.String("multilingual", "", "for blogs written in more than one language: detect each post's language and lay them out for a multilingual Hugo site, as suffix (hello.hi.md beside hello.md) or dir (a content directory per language, e.g. content/hi/posts, for a target directory under content)")
+var mainLanguage = flag.String("language", "", "with -multilingual, the blog's main language, e.g. en, which posts are taken to be in unless they're clearly in another; by default from the blog's settings")
+
+// The blog's main language, from -language or its settings, e.g. en_GB -> en.
+func (c *Converter) blogLanguage() string {
+	if *mainLanguage != "" {
+		return strings.ToLower(*mainLanguage)
+	}
+	if locale, _ := c.blogSetting("BLOG_LOCALE"); locale != "" {
+		lang, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(locale, "_", "-")), "-")
+		return lang
+	}
+	return "en"
+}
+
+// Languages written in a script of their own, by the script.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Gurmukhi, "pa"},
+	{unicode.Gujarati, "gu"},
+	{unicode.Tamil, "ta"},
+	{unicode.Telugu, "te"},
+	{unicode.Kannada, "kn"},
+	{unicode.Malayalam, "ml"},
+	{unicode.Sinhala, "si"},
+	{unicode.Thai, "th"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+}
+
+// The commonest short words of languages written in the Latin alphabet.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "was", "for", "with", "this", "you", "are", "on"},
+	"es": {"el", "la", "de", "que", "y", "los", "las", "en", "un", "una", "por", "con", "para", "es", "del"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "un", "une", "que", "pour", "dans", "pas", "qui", "du"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "ich", "sich", "auf", "von"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "não", "com"},
+	"it": {"il", "la", "di", "che", "e", "un", "una", "per", "non", "sono", "del", "della", "gli", "con", "è"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "ik", "er"},
+	"id": {"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "tidak", "dari", "ada", "saya", "akan", "ke", "juga", "bisa"},
+}
+
+var stopWordLanguage = func() map[string][]string {
+	m := map[string][]string{}
+	for lang, words := range stopWords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+// The language a post or page is written in: the one its script is used for, or for the
+// Latin alphabet the one whose common words it uses most. Where that isn't clear, as
+// for a short post, it's taken to be in main.
+func detectLanguage(e Entry, main string) string {
+	text := e.Title + " " + html.UnescapeString(tagPattern.ReplaceAllString(entryContent(e), " "))
+	scripts := map[string]int{}
+	latin, letters := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				scripts[s.lang]++
+				break
+			}
+		}
+	}
+	if letters < 20 {
+		return main
+	}
+	best, bestCount := "", 0
+	for _, s := range scriptLanguages {
+		if n := scripts[s.lang]; n > bestCount {
+			best, bestCount = s.lang, n
+		}
+	}
+	// Japanese mixes kana into Han.
+	if best == "zh" && scripts["ja"] > 0 {
+		best = "ja"
+	}
+	if bestCount > latin {
+		return best
+	}
+
+	hits := map[string]int{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, lang := range stopWordLanguage[w] {
+			hits[lang]++
+		}
+	}
+	langs := make([]string, 0, len(hits))
+	for lang := range hits {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if hits[langs[i]] != hits[langs[j]] {
+			return hits[langs[i]] > hits[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	if len(langs) == 0 || langs[0] == main || hits[langs[0]] < 5 {
+		return main
+	}
+	if len(langs) > 1 && hits[langs[0]] < 2*hits[langs[1]] && hits[main] > 0 {
+		return main
+	}
+	return langs[0]
+}
+
+// Where an entry in sub, relative to the target directory, is written under
+// -multilingual=dir: the same section in its language's content directory, e.g.
+// for content/posts, ../hi/posts.
+func (c *Converter) languageDir(e Entry, sub string) string {
+	if e.Lang == "" || *multilingual != "dir" {
+		return sub
+	}
+	return path.Join(c.languageRoot(e.Lang), sub)
+}
+
+// Where a file of no particular language, such as a comment or section page, relative
+// to the target directory, is written: under -multilingual=dir, in the main language's
+// content directory, the only one Hugo reads it from.
+func (c *Converter) mainLanguageDir(name string) string {
+	if *multilingual != "dir" {
+		return name
+	}
+	return path.Join(c.languageRoot(c.language), name)
+}
+
+// The target directory's counterpart in the content directory for lang.
+func (c *Converter) languageRoot(lang string) string {
+	section := strings.Trim(c.urlPrefix, "/")
+	up := ""
+	if section != "" {
+		up = strings.Repeat("../", strings.Count(section, "/")+1)
+	}
+	return path.Join(up, lang, section)
+}
+
+// The language suffix of an entry's filename under -multilingual=suffix, e.g. .hi, for
+// those not in the main language.
+func (c *Converter) languageSuffix(e Entry) string {
+	if e.Lang == "" || e.Lang == c.language || *multilingual != "suffix" {
+		return ""
+	}
+	return "." + e.Lang
+}
+
+// An entry's file name as it would be without -multilingual, for working out its
+// permalink and where it is in its language's content.
+func (c *Converter) unlocalized(e Entry, name string) string {
+	switch {
+	case e.Lang == "":
+	case *multilingual == "suffix":
+		ext := path.Ext(name)
+		return strings.TrimSuffix(strings.TrimSuffix(name, ext), "."+e.Lang) + ext
+	case *multilingual == "dir":
+		return strings.TrimPrefix(name, c.languageRoot(e.Lang)+"/")
+	}
+	return name
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Limits on what an export can make the converter read into memory, so a corrupt or
+// hostile one, e.g. uploaded to serve, fails instead of using it all up.
+var maxEntrySize = flag.Int("max-entry-size", 64, "the largest an entry of a Blogger export can be, in MB (0 means no limit)")
+var maxDepth = flag.Int("max-depth", 256, "how deeply the elements of a Blogger export can nest (0 means no limit)")
+var maxUnzipped = flag.Int("max-unzipped", 1024, "the largest a file read from a zip export can unpack to, in MB (0 means no limit)")
+
+// A Parser for Blogger exports, per -recover and the limits.
+func newParser() blogger.Parser {
+	return blogger.Parser{
+		Recover:      *recoverFlag,
+		Strict:       *strict,
+		MaxEntrySize: int64(*maxEntrySize) << 20,
+		MaxDepth:     *maxDepth,
+	}
+}
+
+// Read a file of a zip export into memory, if it unpacks to no more than -max-unzipped.
+// The zip reader fails a file that unpacks to more than the size it's listed with.
+func readZipFile(f *zip.File) ([]byte, error) {
+	if limit := uint64(*maxUnzipped) << 20; limit > 0 && f.UncompressedSize64 > limit {
+		return nil, fmt.Errorf("%s unpacks to %d MB, more than -max-unzipped allows", f.Name, f.UncompressedSize64>>20)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
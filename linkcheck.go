@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var checkLinks = flag.Bool("check-links", false, "request every external link and add its HTTP status to -link-report")
+var archiveDeadLinks = flag.Bool("archive-dead-links", false, "with -check-links, point dead links at their copy on web.archive.org")
+
+// How many links are checked at once.
+const linkCheckers = 8
+
+// Find every external link in the posts and pages and check them concurrently.
+func (c *Converter) checkAllLinks(ctx context.Context) {
+	seen := map[string]bool{}
+	var urls []string
+	for _, e := range c.exp.Entries {
+		if e.Path == "" {
+			continue
+		}
+		for _, m := range linkAttrPattern.FindAllStringSubmatch(entryContent(e), -1) {
+			raw := linkAttrURL(m)
+			if u, err := url.Parse(raw); err == nil && (u.Scheme == "http" || u.Scheme == "https") &&
+				!c.blogHosts[normalizeHost(u.Host)] && !seen[raw] {
+				seen[raw] = true
+				urls = append(urls, raw)
+			}
+		}
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < linkCheckers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range queue {
+				status := checkLink(ctx, client, u)
+				if ctx.Err() != nil {
+					// Not checked, rather than failed.
+					continue
+				}
+				mu.Lock()
+				c.linkStatus[u] = status
+				mu.Unlock()
+			}
+		}()
+	}
+queue:
+	for _, u := range urls {
+		select {
+		case queue <- u:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// HEAD a link, falling back to GET for servers that don't allow HEAD.
+func checkLink(ctx context.Context, client *http.Client, u string) string {
+	resp, err := request(ctx, client, "HEAD", u)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = request(ctx, client, "GET", u)
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return "timeout"
+	}
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	resp.Body.Close()
+	return strconv.Itoa(resp.StatusCode)
+}
+
+// Whether a checked link is gone for good: 404 or 410. Errors, timeouts and other
+// statuses, server errors included, may pass, so they're only reported.
+func isDead(status string) bool {
+	code, _ := strconv.Atoi(status)
+	return code == http.StatusNotFound || code == http.StatusGone
+}
+
+// Point the entry's dead links at the Wayback Machine's copy.
+func (c *Converter) archiveLinks(e Entry) string {
+	return linkAttrPattern.ReplaceAllStringFunc(e.Content, func(attr string) string {
+		m := linkAttrPattern.FindStringSubmatch(attr)
+		raw := linkAttrURL(m)
+		if status, ok := c.linkStatus[raw]; !ok || !isDead(status) {
+			return attr
+		}
+		return strings.Replace(attr, m[1][1:len(m[1])-1], html.EscapeString("https://web.archive.org/web/"+raw), 1)
+	})
+}
@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+// A dead link's archive URL is escaped back into the attribute, as the link was.
+func TestArchiveLinks(t *testing.T) {
+	c := newConverter()
+	c.linkStatus["http://gone.example.com/a?b=1&c=2"] = "404"
+	c.linkStatus["http://gone.example.com/it's"] = "410"
+	c.linkStatus["http://fine.example.com/"] = "200"
+	var e Entry
+	e.Content = `<a href="http://gone.example.com/a?b=1&amp;c=2">a</a> <a href='http://gone.example.com/it&#39;s'>b</a> <a href="http://fine.example.com/">c</a>`
+	want := `<a href="https://web.archive.org/web/http://gone.example.com/a?b=1&amp;c=2">a</a> <a href='https://web.archive.org/web/http://gone.example.com/it&#39;s'>b</a> <a href="http://fine.example.com/">c</a>`
+	if got := c.archiveLinks(e); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"html"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var linkReport = flag.String("link-report", "", "write a CSV inventory of every external link in the posts (post, file, domain, URL, and status with -check-links), relative to the target directory")
+
+type externalLink struct {
+	Post   string
+	Path   string
+	Domain string
+	URL    string
+}
+
+var linkAttrPattern = regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*("[^"]*"|'[^']*')`)
+
+// The URL of a linkAttrPattern match, unquoted and unescaped.
+func linkAttrURL(m []string) string {
+	return html.UnescapeString(strings.TrimSpace(m[1][1 : len(m[1])-1]))
+}
+
+// The links in an entry's content that point off the blog.
+func (c *Converter) externalLinksIn(e Entry) []externalLink {
+	var links []externalLink
+	for _, m := range linkAttrPattern.FindAllStringSubmatch(e.Content, -1) {
+		raw := linkAttrURL(m)
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || c.blogHosts[normalizeHost(u.Host)] {
+			continue
+		}
+		if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+		links = append(links, externalLink{e.Title, e.Path, strings.ToLower(u.Hostname()), raw})
+	}
+	return links
+}
+
+func (c *Converter) writeLinkReport() error {
+	f, err := c.create(path.Clean(filepath.ToSlash(*linkReport)))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"post", "path", "domain", "url", "status"})
+	for _, l := range c.externalLinks {
+		w.Write([]string{l.Post, l.Path, l.Domain, l.URL, c.linkStatus[l.URL]})
+	}
+	w.Flush()
+	err = w.Error()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
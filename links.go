@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var internalLinks = flag.String("internal-links", "", "rewrite links between posts of this blog to their new location: permalink, or relref (a Hugo shortcode)")
+
+// Fragments Blogger links comments by, which aren't anchors in the post itself.
+var commentFragment = regexp.MustCompile(`^(c\d+|comment-.*|comments)$`)
+
+var hrefPattern = regexp.MustCompile(`(?i)(\bhref\s*=\s*)("[^"]*"|'[^']*')`)
+
+func (c *Converter) indexLinks() {
+	for _, e := range c.exp.Entries {
+		if e.Path == "" || e.URL == "" {
+			continue
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			continue
+		}
+		// A URL without a host, e.g. from an IR file, mustn't make every relative link
+		// look like one to the blog.
+		if u.Host != "" {
+			c.blogHosts[normalizeHost(u.Host)] = true
+		}
+		c.linkTargets[linkKey(u)] = e
+	}
+}
+
+// Blogger serves blogs on country domains too, e.g. foo.blogspot.co.uk is foo.blogspot.com.
+func normalizeHost(host string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if i := strings.Index(host, ".blogspot."); i >= 0 {
+		host = host[:i] + ".blogspot.com"
+	}
+	return host
+}
+
+// Links to a post are keyed by host and path only, so ?m=1 and ?showComment= variants match too.
+func linkKey(u *url.URL) string {
+	return normalizeHost(u.Host) + bloggerPath(u.String())
+}
+
+// Rewrite the entry's links to other posts of the same blog per -internal-links,
+// returning the links that didn't match a converted post or anchor too.
+func (c *Converter) rewriteLinks(e Entry) (string, []string) {
+	var unresolved []string
+	content := hrefPattern.ReplaceAllStringFunc(e.Content, func(attr string) string {
+		m := hrefPattern.FindStringSubmatch(attr)
+		quote, href := m[2][:1], m[2][1:len(m[2])-1]
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err != nil || u.Host == "" || !c.blogHosts[normalizeHost(u.Host)] {
+			return attr
+		}
+		target, ok := c.linkTargets[linkKey(u)]
+		if !ok {
+			if path.Ext(u.Path) == ".html" {
+				unresolved = append(unresolved, fmt.Sprintf("%s links to %s", e.Title, href))
+			}
+			return attr
+		}
+		var dest string
+		switch *internalLinks {
+		case "relref":
+			if target.Lang != e.Lang {
+				dest = fmt.Sprintf(`{{< relref path="%s" lang="%s" >}}`, c.contentPath(c.unlocalized(target, target.Path)), target.Lang)
+			} else {
+				dest = fmt.Sprintf(`{{< relref "%s" >}}`, c.contentPath(c.unlocalized(target, target.Path)))
+			}
+		default:
+			dest = absURL(c.permalink(target, target.Path))
+		}
+		if u.Fragment != "" {
+			if !hasAnchor(entryContent(target), u.Fragment) && !commentFragment.MatchString(u.Fragment) {
+				unresolved = append(unresolved, fmt.Sprintf("%s links to %s, which has no #%s anchor", e.Title, href, u.Fragment))
+			}
+			dest += "#" + u.Fragment
+		}
+		return m[1] + quote + dest + quote
+	})
+	return content, unresolved
+}
+
+// The path of a file relative to the content directory, as relref wants it.
+func (c *Converter) contentPath(name string) string {
+	return path.Join("/", strings.Trim(c.urlPrefix, "/"), name)
+}
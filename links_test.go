@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// -internal-links rewrites absolute links to the blog, and leaves relative ones be,
+// even when an entry's own URL has no host.
+func TestRewriteLinks(t *testing.T) {
+	defer func(s string) { *internalLinks = s }(*internalLinks)
+	*internalLinks = "permalink"
+	exp, err := loadExports(context.Background(), []string{"tests/data/comments-blogger-backup.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close()
+	const relative = ` <a href="/2020/01/first-snow.html">root</a> <a href="first-snow.html">here</a> <a href="/p/about.html">about</a>`
+	for i := range exp.Entries {
+		e := &exp.Entries[i]
+		switch e.Title {
+		case "Ice on the lake":
+			e.Content += relative
+		case "About us":
+			// As an IR file may have it.
+			for j := range e.Links {
+				if e.Links[j].Rel == "alternate" {
+					e.Links[j].Link = "/p/about.html"
+				}
+			}
+		}
+	}
+	b, err := convertToMem(t, exp).ReadFile("2020-02-20-ice-on-lake.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `<a href="/2020-01-15-first-snow/">the first snow</a>`) {
+		t.Errorf("absolute link to the blog not rewritten:\n%s", b)
+	}
+	if !strings.Contains(string(b), relative) {
+		t.Errorf("relative links rewritten:\n%s", b)
+	}
+}
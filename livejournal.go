@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// A LiveJournal XML dump: entries as exported by export.bml or ljdump, optionally
+// followed by the comments from export_comments.bml in the same <livejournal> root.
+type ljExport struct {
+	Entries  []ljEntry   `xml:"entry"`
+	Comments []ljComment `xml:"comments>comment"`
+	Users    []struct {
+		ID   string `xml:"id,attr"`
+		User string `xml:"user,attr"`
+	} `xml:"usermaps>usermap"`
+}
+
+type ljEntry struct {
+	ItemID    uint64   `xml:"itemid"`
+	EventTime string   `xml:"eventtime"`
+	LogTime   string   `xml:"logtime"`
+	Subject   string   `xml:"subject"`
+	Event     string   `xml:"event"`
+	Security  string   `xml:"security"`
+	URL       string   `xml:"url"`
+	Poster    string   `xml:"poster"`
+	Tags      string   `xml:"taglist"`
+	Props     []string `xml:"props>taglist"`
+}
+
+type ljComment struct {
+	ID       uint64 `xml:"id,attr"`
+	PosterID string `xml:"posterid,attr"`
+	ItemID   uint64 `xml:"jitemid,attr"`
+	ParentID uint64 `xml:"parentid,attr"`
+	State    string `xml:"state,attr"`
+	Subject  string `xml:"subject"`
+	Body     string `xml:"body"`
+	Date     string `xml:"date"`
+}
+
+type ljImporter struct{}
+
+func (ljImporter) Detect(b []byte) bool {
+	return rootElement(b) == "livejournal"
+}
+
+func (ljImporter) Import(b []byte) (Export, error) {
+	var lj ljExport
+	if err := xml.Unmarshal(b, &lj); err != nil {
+		return Export{}, err
+	}
+	users := map[string]string{}
+	for _, u := range lj.Users {
+		users[u.ID] = u.User
+	}
+	var exp Export
+	posts := map[uint64]bool{}
+	for _, le := range lj.Entries {
+		posts[le.ItemID] = true
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        "livejournal.post-" + strconv.FormatUint(le.ItemID, 10),
+				Published: ljDate(le.EventTime),
+				Updated:   ljDate(le.LogTime),
+				Title:     le.Subject,
+				Content:   autoParagraphs(le.Event),
+				Tags:      Tags{kindTag("post")},
+				Author:    Author{Name: le.Poster},
+			},
+		}
+		if time.Time(e.Updated).Before(time.Time(e.Published)) {
+			e.Updated = e.Published
+		}
+		if le.URL != "" {
+			e.Links = []Reply{{Rel: "alternate", Link: le.URL}}
+		}
+		// Friends-only posts are "usemask"; only public ones were open to everyone.
+		if le.Security != "" && le.Security != "public" {
+			e.Hidden = le.Security + " on LiveJournal"
+		}
+		for _, t := range strings.Split(strings.Join(append(le.Props, le.Tags), ","), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				e.Tags = append(e.Tags, labelTag(t))
+			}
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	for _, lc := range lj.Comments {
+		// Deleted and screened comments, and comments on entries outside this dump.
+		if lc.State == "D" || lc.State == "S" || !posts[lc.ItemID] {
+			continue
+		}
+		name := users[lc.PosterID]
+		if name == "" {
+			name = "Anonymous"
+		}
+		c := Entry{
+			Entry: blogger.Entry{
+				ID:        "livejournal.post-" + strconv.FormatUint(commentIDBase+lc.ID, 10),
+				Published: ljDate(lc.Date),
+				Title:     name,
+				Content:   autoParagraphs(lc.Body),
+				Tags:      Tags{kindTag("comment")},
+				Author:    Author{Name: name},
+				Source:    Reply{Source: "livejournal/" + strconv.FormatUint(lc.ItemID, 10)},
+			},
+		}
+		c.Updated = c.Published
+		if lc.ParentID != 0 {
+			c.Links = []Reply{{Rel: "related", Link: "livejournal/" + strconv.FormatUint(commentIDBase+lc.ParentID, 10)}}
+		}
+		exp.Entries = append(exp.Entries, c)
+	}
+	return exp, nil
+}
+
+// Entry times are "2004-04-20 13:42:00" in the poster's time; comment dates are RFC 3339.
+func ljDate(s string) Date {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02 15:04:05", s); err == nil {
+		return Date(t)
+	}
+	return feedDate(s)
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+const testLJ = `<?xml version="1.0" encoding="utf-8"?>
+<livejournal>
+<entry>
+<itemid>12</itemid>
+<eventtime>2004-04-20 13:42:00</eventtime>
+<logtime>2004-04-20 13:40:00</logtime>
+<subject>Spring</subject>
+<event>It's warm.
+
+Finally.</event>
+<security>public</security>
+<url>https://ann.livejournal.com/3072.html</url>
+<poster>ann</poster>
+<props><taglist>weather, spring</taglist></props>
+</entry>
+<entry>
+<itemid>13</itemid>
+<eventtime>2004-04-21 08:00:00</eventtime>
+<subject>Friends only</subject>
+<event>Secret.</event>
+<security>usemask</security>
+<poster>ann</poster>
+</entry>
+<usermaps><usermap id="7" user="bob"/></usermaps>
+<comments>
+<comment id="100" posterid="7" jitemid="12"><body>Lucky you.</body><date>2004-04-20T15:00:00Z</date></comment>
+<comment id="101" jitemid="12" parentid="100"><body>Indeed.</body><date>2004-04-20T16:00:00Z</date></comment>
+<comment id="102" posterid="7" jitemid="12" state="D"><body></body><date>2004-04-20T17:00:00Z</date></comment>
+<comment id="103" posterid="7" jitemid="99"><body>Elsewhere.</body><date>2004-04-20T18:00:00Z</date></comment>
+</comments>
+</livejournal>`
+
+func TestLiveJournalImport(t *testing.T) {
+	testImport(t, "auto", testLJ, []string{
+		`post livejournal.post-12 "Spring" by ann at 2004-04-20T13:42:00Z labels weather, spring`,
+		`post livejournal.post-13 "Friends only" by ann at 2004-04-21T08:00:00Z`,
+		`comment livejournal.post-1099511627876 "bob" by bob at 2004-04-20T15:00:00Z on livejournal/12`,
+		`comment livejournal.post-1099511627877 "Anonymous" by Anonymous at 2004-04-20T16:00:00Z on livejournal/12 replying to livejournal/1099511627876`,
+	})
+	exp, err := ljImporter{}.Import([]byte(testLJ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exp.Entries[0].Content; got != "<p>It's warm.</p>\n<p>Finally.</p>" {
+		t.Errorf("content = %q", got)
+	}
+	if exp.Entries[0].Hidden != "" || exp.Entries[1].Hidden == "" {
+		t.Errorf("hidden = %q and %q, want only the friends-only post hidden", exp.Entries[0].Hidden, exp.Entries[1].Hidden)
+	}
+}
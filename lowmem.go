@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+var lowMemory = flag.Bool("low-memory", false, "for very large Blogger exports: keep post bodies in a temporary file until they're written, instead of in memory")
+var maxMemory = flag.Int("max-memory", 0, "for very large Blogger exports: a budget, in MB, for the post, page and comment bodies each export holds in memory, past which the rest wait in a temporary file as with -low-memory; the entries themselves stay in memory (0 means no limit)")
+
+// Where post bodies wait under -low-memory, one file for each export read.
+type spillFile struct {
+	f   scratchFile
+	end int64
+	// Bytes of post, page and comment bodies left in memory, up to -max-memory.
+	inMemory int64
+}
+
+// Where an entry's body is in the spill file.
+type spilled struct {
+	file     *spillFile
+	Off, Len int64
+}
+
+// Keep a newly read entry's body in memory while -max-memory allows, and in the spill
+// file once it doesn't, or always under -low-memory. Only posts, pages and comments
+// are spilled, so only theirs count.
+func (s *spillFile) keep(e *Entry) error {
+	if !spillable(*e) {
+		return nil
+	}
+	e.budget = s
+	if !*lowMemory && s.fits(int64(len(e.Content))) {
+		s.inMemory += int64(len(e.Content))
+		return nil
+	}
+	return s.stash(e)
+}
+
+// Whether n more bytes of bodies can be held in memory.
+func (s *spillFile) fits(n int64) bool {
+	return *maxMemory <= 0 || s.inMemory+n <= int64(*maxMemory)<<20
+}
+
+// Move an entry's body out of memory into the spill file.
+func (s *spillFile) stash(e *Entry) error {
+	if !spillable(*e) {
+		return nil
+	}
+	if s.f == nil {
+		var err error
+		if s.f, err = newScratch(); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.WriteAt([]byte(e.Content), s.end)
+	if err != nil {
+		return err
+	}
+	e.spill = &spilled{s, s.end, int64(n)}
+	e.Content = ""
+	s.end += int64(n)
+	return nil
+}
+
+// Whether an entry's body can wait in the spill file: posts', pages' and comments'.
+// The settings and template are read whole.
+func spillable(e Entry) bool {
+	switch e.Kind() {
+	case "post", "page", "comment":
+		return true
+	}
+	return false
+}
+
+// An entry's body, from memory or the spill file.
+func readContent(e Entry) (string, error) {
+	if e.spill == nil {
+		return e.Content, nil
+	}
+	b := make([]byte, e.spill.Len)
+	if _, err := e.spill.file.f.ReadAt(b, e.spill.Off); err != nil {
+		return "", fmt.Errorf("reading back the body: %w", err)
+	}
+	return string(b), nil
+}
+
+// As readContent, for the passes that only look into bodies; a body that can't be
+// read back fails when its post is written.
+func entryContent(e Entry) string {
+	content, _ := readContent(e)
+	return content
+}
+
+// Replace an entry's body, keeping it in the spill file if that's where it was, in
+// the same place if it fits there. A body kept in memory is still counted against
+// -max-memory, and moves to the spill file if it grows past it.
+func setContent(e *Entry, content string) {
+	if sp := e.spill; sp != nil {
+		if int64(len(content)) <= sp.Len {
+			if n, err := sp.file.f.WriteAt([]byte(content), sp.Off); err == nil {
+				sp.Len = int64(n)
+				return
+			}
+		}
+		e.Content = content
+		if err := sp.file.stash(e); err != nil {
+			// Kept in memory instead.
+			e.spill = nil
+			sp.file.inMemory += int64(len(content))
+		}
+		return
+	}
+	old := int64(len(e.Content))
+	e.Content = content
+	s := e.budget
+	if s == nil {
+		return
+	}
+	s.inMemory -= old
+	if s.fits(int64(len(content))) || s.stash(e) != nil {
+		s.inMemory += int64(len(content))
+	}
+}
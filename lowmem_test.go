@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Read the story fixture as a stream with -low-memory, and check its bodies are in the
+// spill file and read back as they'd be read into memory.
+func TestLowMemory(t *testing.T) {
+	const fixture = "tests/data/story-blogger-backup.xml"
+	want, err := loadExports(context.Background(), []string{fixture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(lm bool) { *lowMemory = lm }(*lowMemory)
+	*lowMemory = true
+	got, err := loadExports(context.Background(), []string{fixture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Entries) != len(want.Entries) {
+		t.Fatalf("got %d entries, want %d", len(got.Entries), len(want.Entries))
+	}
+	spilled := 0
+	for i, e := range got.Entries {
+		if e.spill != nil {
+			spilled++
+			if e.Content != "" {
+				t.Errorf("%s: body kept in memory as well as spilled", e.ID)
+			}
+		}
+		if entryContent(e) != entryContent(want.Entries[i]) {
+			t.Errorf("%s: body read back differs", e.ID)
+		}
+	}
+	if spilled == 0 {
+		t.Fatal("no bodies spilled to disk")
+	}
+
+	// A body rewritten stays in the spill file.
+	for i := range got.Entries {
+		e := &got.Entries[i]
+		if e.spill == nil {
+			continue
+		}
+		defer e.spill.file.f.Close()
+		setContent(e, "<p>Rewritten</p>")
+		if e.spill == nil || e.Content != "" {
+			t.Errorf("%s: rewritten body moved into memory", e.ID)
+		}
+		if c := entryContent(*e); c != "<p>Rewritten</p>" {
+			t.Errorf("%s: rewritten body read back as %q", e.ID, c)
+		}
+		break
+	}
+}
+
+// An entry of the given kind with a body n bytes long.
+func sizedEntry(kind string, n int) Entry {
+	var e Entry
+	e.ID = kind
+	e.Tags = blogger.Tags{{Name: blogger.KindPrefix + kind, Scheme: blogger.KindScheme}}
+	e.Content = strings.Repeat("x", n)
+	return e
+}
+
+// Past -max-memory, bodies go to the spill file, and the ones that fit stay in memory.
+func TestMaxMemory(t *testing.T) {
+	defer func(mm int) { *maxMemory = mm }(*maxMemory)
+	*maxMemory = 1
+	var s spillFile
+	defer func() {
+		if s.f != nil {
+			s.f.Close()
+		}
+	}()
+	for _, test := range []struct {
+		kind    string
+		size    int
+		spilled bool
+	}{
+		// The template is neither spilled nor counted.
+		{"template", 2 << 20, false},
+		{"post", 600 << 10, false},
+		{"comment", 600 << 10, true},
+		{"page", 300 << 10, false},
+		{"post", 200 << 10, true},
+	} {
+		e := sizedEntry(test.kind, test.size)
+		if err := s.keep(&e); err != nil {
+			t.Fatal(err)
+		}
+		if spilled := e.spill != nil; spilled != test.spilled {
+			t.Errorf("%d KB %s body: spilled %t, want %t", test.size>>10, test.kind, spilled, test.spilled)
+		}
+		if len(entryContent(e)) != test.size {
+			t.Errorf("%d KB %s body read back as %d bytes", test.size>>10, test.kind, len(entryContent(e)))
+		}
+	}
+}
+
+// Rewriting a body reuses its place in the spill file when it fits, and a body in
+// memory that grows is counted, and spilled once it's past -max-memory.
+func TestSetContent(t *testing.T) {
+	defer func(mm int) { *maxMemory = mm }(*maxMemory)
+	*maxMemory = 1
+	var s spillFile
+	defer func() {
+		if s.f != nil {
+			s.f.Close()
+		}
+	}()
+	small, big := sizedEntry("post", 600<<10), sizedEntry("post", 600<<10)
+	for _, e := range []*Entry{&small, &big} {
+		if err := s.keep(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if small.spill != nil || big.spill == nil {
+		t.Fatalf("spilled %t and %t, want false and true", small.spill != nil, big.spill != nil)
+	}
+
+	end := s.end
+	setContent(&big, "<p>Shorter</p>")
+	if s.end != end {
+		t.Errorf("shorter body appended to the spill file, which grew from %d to %d bytes", end, s.end)
+	}
+	if c := entryContent(big); c != "<p>Shorter</p>" {
+		t.Errorf("shorter body read back as %q", c)
+	}
+	longer := strings.Repeat("y", 700<<10)
+	setContent(&big, longer)
+	if s.end != end+int64(len(longer)) {
+		t.Errorf("longer body: spill file is %d bytes, want %d", s.end, end+int64(len(longer)))
+	}
+	if entryContent(big) != longer {
+		t.Error("longer body read back differs")
+	}
+
+	setContent(&small, strings.Repeat("z", 300<<10))
+	if small.spill != nil || s.inMemory != 300<<10 {
+		t.Errorf("shrunk body: spilled %t, %d bytes counted in memory, want false and %d", small.spill != nil, s.inMemory, 300<<10)
+	}
+	grown := strings.Repeat("z", 1100<<10)
+	setContent(&small, grown)
+	if small.spill == nil || s.inMemory != 0 {
+		t.Errorf("body grown past -max-memory: spilled %t, %d bytes counted in memory, want true and 0", small.spill != nil, s.inMemory)
+	}
+	if entryContent(small) != grown {
+		t.Error("grown body read back differs")
+	}
+}
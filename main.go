@@ -1,227 +1,432 @@
 package main
 
 import (
-	"encoding/xml"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
-	"text/template"
+	"syscall"
 	"time"
-	"unicode"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+	"github.com/atulsingh0/blogger2hugo/hugo"
 )
 
-type Date time.Time
+// The export's own types, as the conversion works on them.
+type (
+	Date   = blogger.Date
+	Draft  = blogger.Draft
+	Reply  = blogger.Reply
+	Image  = blogger.Image
+	Author = blogger.Author
+	Tag    = blogger.Tag
+	Tags   = blogger.Tags
 
-func (d Date) String() string {
-	return time.Time(d).Format("2006-01-02T15:04:05Z")
-}
+	Extension = blogger.Extension
+)
 
-func (d *Date) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
-	var v string
-	dec.DecodeElement(&v, &start)
-	t, err := time.Parse("2006-01-02T15:04:05.000-07:00", v)
-	if err != nil {
-		return err
-	}
-	*d = Date(t)
-	return nil
+type Export struct {
+	Title   string
+	Entries []Entry
+	// Images and videos bundled with the export, e.g. in a Takeout, by file name, to use
+	// instead of the copies on Blogger's servers.
+	Media map[string]*zip.File
+	// The blog's page views by time range, as the Blogger API counts them.
+	PageViews map[string]int64
+	// What the export is read from as it's converted, e.g. a zip's bundled media.
+	files []io.Closer
 }
 
-type Draft bool
-
-func (d *Draft) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
-	var v string
-	dec.DecodeElement(&v, &start)
-	switch v {
-	case "yes":
-		*d = true
-		return nil
-	case "no":
-		*d = false
-		return nil
+// Close the files the export is still read from.
+func (e Export) Close() error {
+	var errs []error
+	for _, f := range e.files {
+		errs = append(errs, f.Close())
 	}
-	return fmt.Errorf("Unknown value for draft boolean: %s", v)
-}
-
-type Reply struct {
-	Rel    string `xml:"rel,attr"`
-	Link   string `xml:"href,attr"`
-	Source string `xml:"source,attr"`
-}
-
-type Image struct {
-	Width  int    `xml:"width,attr"`
-	Height int    `xml:"height,attr"`
-	Source string `xml:"src,attr"`
-}
-
-type Author struct {
-	Name  string `xml:"name"`
-	Uri   string `xml:"uri"`
-	Image Image  `xml:"image"`
-}
-
-type Export struct {
-	XMLName xml.Name `xml:"feed"`
-	Entries []Entry  `xml:"entry"`
+	return errors.Join(errs...)
 }
 
+// An entry of the export with what the conversion works out about it.
 type Entry struct {
-	ID        string  `xml:"id"`
-	Published Date    `xml:"published"`
-	Updated   Date    `xml:"updated"`
-	Draft     Draft   `xml:"control>draft"`
-	Title     string  `xml:"title"`
-	Content   string  `xml:"content"`
-	Tags      Tags    `xml:"category"`
-	Author    Author  `xml:"author"`
-	Source    Reply   `xml:"in-reply-to"`
-	Links     []Reply `xml:"link"`
+	blogger.Entry
 	Reply     uint64
 	Children  []int
 	Comments  []uint64
 	Slug      string
-	Extra     string
+	URL       string
+	Path      string
+	Menu      string
+	BloggerID string
+	Hidden    string
+	Unlisted  bool
+	// Why it looks deleted on Blogger, e.g. it's in the trash.
+	Deleted string
+	Params  map[string]string
+	Extra   string
+	spill   *spilled
+	// The spill file of its export, whose -max-memory budget its body counts against.
+	budget *spillFile
+	// The slug was given by -script, so it's used whatever -slug-source says.
+	slugSet bool
+	// The language it's written in, under -multilingual.
+	Lang string
+	// The permalinks of the posts its related links name.
+	Related []string
+	// Shown by the blog's Featured Post gadget.
+	Featured bool
+	// The audio attached to it, for podcasts.
+	Enclosure *enclosure
+	// Scheduled on Blogger for later, and written with a publishDate under -future=publishDate.
+	Scheduled bool
+	// Its page views on Blogger, from -pageviews.
+	Views int64
+	// The ID of the copy of it converted instead, under -duplicates=skip.
+	Duplicate string
+	// What was lost converting it, under -migration-warnings=frontmatter.
+	Warnings []string
+	// The Blogger paths redirected to it, under -aliases.
+	Aliases []string
 }
 
-type Tag struct {
-	Name   string `xml:"term,attr"`
-	Scheme string `xml:"scheme,attr"`
-}
+const kindPrefix = blogger.KindPrefix
 
-type Tags []Tag
-type EntrySet []int
+var timeout = flag.Duration("timeout", 0, "stop after this long, e.g. 30m, as if interrupted (0 means no limit)")
+var slugSource = flag.String("slug-source", "blogger", "where post filenames come from: blogger (the original post URL) or title")
+var translit = flag.Bool("transliterate", false, "convert non-Latin characters in generated slugs to ASCII")
+var collision = flag.String("on-collision", "id", "what to do when two posts map to the same filename: id (append the post ID), counter, or fail")
+var pagesDir = flag.String("pages-dir", "", "directory to write Blogger static pages to, relative to the target directory")
+var pagesMenu = flag.String("pages-menu", "", "add static pages to this Hugo menu, e.g. main")
+var lineEndings = flag.String("line-endings", "lf", "line endings for generated files: lf, crlf, or keep (which also keeps trailing whitespace)")
+var draftsDir = flag.String("drafts-dir", "", "write drafts to this directory, relative to the target directory, instead of alongside published posts")
+var bloggerID = flag.String("blogger-id", "", "include each post's Blogger ID in its front matter (frontmatter), its filename (filename), or both")
+var noDatePrefix = flag.Bool("no-date-prefix", false, "leave the YYYY-MM-DD- prefix off post filenames")
+var maxSlugLen = flag.Int("max-slug-length", 0, "truncate generated slugs to this many characters at a word boundary (0 means no limit)")
+var slugUnicode = flag.String("slug-unicode", "sanitize", "how to treat Unicode in slugs: sanitize, or keep (decode percent-encoding, and keep case and combining marks; spaces still become hyphens and other punctuation is dropped)")
 
-func (t Tags) TomlString() string {
-	names := []string{}
-	for _, t := range t {
-		if t.Scheme == "http://www.blogger.com/atom/ns#" {
-			names = append(names, fmt.Sprintf("%q", t.Name))
-		}
-	}
-	return strings.Join(names, ", ")
-}
-
-var tomlTempl = `+++
-title = "{{ .Title }}"{{ if not (eq .Title .Slug) }}
-slug = "{{ .Slug }}"{{end}}
-date = {{ .Published }}
-updated = {{ .Updated }}{{ with .Tags.TomlString }}
-tags = [{{ . }}]{{ end }}{{ if .Draft }}
-draft = true{{ end }}{{ if not (len .Comments | eq 0) }}
-comments = [ {{range $i, $e := .Comments}}{{if $i}}, {{end}}{{$e}}{{end}} ]{{ end }}
-blogimport = true {{ with .Extra }}
-{{.}}{{ end }}
-[author]
-	name = "{{ .Author.Name }}"
-	uri = "{{ .Author.Uri }}"
-[author.image]
-	source = "{{ .Author.Image.Source }}"
-	width = "{{ .Author.Image.Width }}"
-	height = "{{ .Author.Image.Height }}"
-
-+++
-{{ .Content }}
-`
-
-var yamlTempl = `---
-title: "{{ .Title }}"
-date: {{ .Published }}
-updated: {{ .Updated }}{{ with .Tags.TomlString }}
-tags: [{{ . }}]{{ end }}{{ if .Draft }}
-draft: true{{ end }}
-blogimport: true {{ with .Extra }}
-{{.}}{{ end }}
-author: "{{ .Author.Name }}"
----
-
-{{ .Content }}
-`
-
-var t = template.Must(template.New("").Parse(yamlTempl))
-var exp = Export{}
-
-func (s EntrySet) Len() int {
-	return len(s)
-}
-func (s EntrySet) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
-}
-func (s EntrySet) Less(i, j int) bool {
-	return time.Time(exp.Entries[s[i]].Published).Before(time.Time(exp.Entries[s[j]].Published))
-}
-
-func treeSort(i int) (list []int) {
-	sort.Sort(EntrySet(exp.Entries[i].Children))
-	for _, v := range exp.Entries[i].Children {
+func (c *Converter) treeSort(i int) (list []int) {
+	children := c.exp.Entries[i].Children
+	sort.Slice(children, func(a, b int) bool {
+		return time.Time(c.exp.Entries[children[a]].Published).Before(time.Time(c.exp.Entries[children[b]].Published))
+	})
+	for _, v := range children {
 		list = append(list, v)
-		list = append(list, treeSort(v)...)
+		list = append(list, c.treeSort(v)...)
 	}
 	return
 }
 
+// Set by builds that aren't run from a command line, e.g. in a browser, to run instead.
+var platformMain func()
+
 func main() {
 	log.SetFlags(0)
 
 	var extra = flag.String("extra", "", "additional metadata to set in frontmatter")
 	flag.Parse()
+	if platformMain != nil {
+		platformMain()
+		return
+	}
+
+	// Ctrl-C or -timeout stops the run between files, still reporting what was done.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	interrupted, failed := false, false
+	// Deferred first so it runs last, once the output is closed.
+	defer func() {
+		if interrupted || failed {
+			os.Exit(1)
+		}
+	}()
+
+	if err := checkFlags(); err != nil {
+		log.Fatal(err)
+	}
 
 	args := flag.Args()
+	verifyMode := len(args) > 0 && args[0] == "verify"
+	if verifyMode {
+		args = args[1:]
+	}
+	goldenMode := len(args) > 0 && args[0] == "golden"
+	if goldenMode {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "gen-fixture" {
+		if len(args) != 3 {
+			log.Fatalf("Usage: %s [options] gen-fixture <xmlfile> <fixture>", os.Args[0])
+		}
+		if err := genFixtureFile(ctx, args[1], args[2]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "diff-exports" {
+		if len(args) != 3 || *bloggerAPI != "" {
+			log.Fatalf("Usage: %s [options] diff-exports <old xmlfile> <new xmlfile>", os.Args[0])
+		}
+		n, err := diffExports(ctx, args[1], args[2], os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Like diff, exits 1 if the exports differ.
+		failed = n > 0
+		return
+	}
+	if len(args) > 0 && args[0] == "export-ir" {
+		inputs := args[1:]
+		if *bloggerAPI != "" {
+			inputs = append([]string{*bloggerAPI}, inputs...)
+		}
+		if len(inputs) < 2 {
+			log.Fatalf("Usage: %s [options] export-ir <xmlfile> [<xmlfile>...] <irfile>", os.Args[0])
+		}
+		if err := exportIR(ctx, inputs[:len(inputs)-1], inputs[len(inputs)-1]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "import-ir" {
+		// The rest is an ordinary conversion, of IR files.
+		args = args[1:]
+		*inputFormat = "ir"
+	}
+	if len(args) > 0 && args[0] == "serve" {
+		addr := "localhost:8080"
+		if len(args) > 1 {
+			addr = args[1]
+		}
+		if *format != "yaml" && *format != "toml" {
+			log.Fatalf("Unknown value for -format: %s", *format)
+		}
+		serve(ctx, addr, *extra)
+		return
+	}
+	if *bloggerAPI != "" {
+		// The blog stands in for the xmlfile argument.
+		args = append([]string{*bloggerAPI}, args...)
+	}
+
+	var site *siteConfig
+	if *hugoSite != "" {
+		var err error
+		if site, err = loadSiteConfig(*hugoSite); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	if len(args) != 2 {
-		log.Printf("Usage: %s [options] <xmlfile> <targetdir>", os.Args[0])
+	inputs, dir, dirGiven := splitArgs(args, *outputArchive != "" || site != nil)
+	if len(inputs) == 0 || (!dirGiven && *outputArchive == "" && site == nil) {
+		log.Printf("Usage: %s [options] <xmlfile> [<xmlfile>...] <targetdir>", os.Args[0])
+		log.Println("       (use - as the xmlfile to read it from standard input)")
+		log.Printf("       %s [options] -output-archive <archive> <xmlfile> [targetdir]", os.Args[0])
+		log.Printf("       %s [options] -hugo-site <sitedir> <xmlfile> [targetdir]", os.Args[0])
+		log.Printf("       %s [options] verify <xmlfile> <targetdir>", os.Args[0])
+		log.Printf("       %s [options] golden <xmlfile> <goldendir>", os.Args[0])
+		log.Printf("       %s [options] gen-fixture <xmlfile> <fixture>", os.Args[0])
+		log.Printf("       %s [options] diff-exports <old xmlfile> <new xmlfile>", os.Args[0])
+		log.Printf("       %s [options] export-ir <xmlfile> [<xmlfile>...] <irfile>", os.Args[0])
+		log.Printf("       %s [options] import-ir <irfile> [<irfile>...] <targetdir>", os.Args[0])
+		log.Printf("       %s [options] serve [address]", os.Args[0])
+		log.Printf("       %s [options] -blogger-api <blog URL> -api-key <key> <targetdir>", os.Args[0])
 		log.Println("options:")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	dir := args[1]
+	c := flagConverter()
+	c.extra = *extra
+	c.urlPrefix = contentSection(dir)
+	if site != nil {
+		section := "posts"
+		if dirGiven {
+			section = filepath.Base(dir)
+		}
+		siteDir := c.applySiteConfig(site, section)
+		if !dirGiven {
+			dir = siteDir
+		}
+		c.urlPrefix = "/" + section
+		// The site's config can change -format.
+		c.setTemplate(c.target.Template())
+	}
+
+	switch *format {
+	case "yaml", "toml":
+	default:
+		log.Fatalf("Unknown value for -format: %s", *format)
+	}
+
+	if goldenMode {
+		// Written in memory, to compare with the golden files.
+		c.out = &MemFS{}
+	} else if *outputArchive != "" {
+		f, err := os.Create(*outputArchive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		a, err := newArchive(f, *outputArchive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		prefix := dir
+		if site != nil {
+			// Inside the archive the paths are the site's.
+			if rel, err := filepath.Rel(*hugoSite, dir); err == nil {
+				prefix = rel
+			}
+		}
+		if err := a.setPrefix(prefix); err != nil {
+			log.Fatal(err)
+		}
+		c.out = a
+		defer func() {
+			if err := a.Close(); err != nil {
+				log.Fatal(err)
+			}
+			if err := f.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	} else {
+		info, err := os.Stat(dir)
 
-	info, err := os.Stat(dir)
+		if os.IsNotExist(err) && !verifyMode {
+			if *target == "hugo" {
+				err = os.MkdirAll(path.Join(dir, "comments"), 0755)
+			} else {
+				err = os.MkdirAll(dir, 0755)
+			}
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(path.Join(dir, "comments"), 0755)
+		info, err = os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			log.Fatal("Second argument is not a directory.")
+		}
+		c.out = DirFS(dir)
+		if *stateFileName != "" && !verifyMode {
+			st, err := openStateDir(DirFS(dir), *stateFileName)
+			if err != nil {
+				log.Fatal(err)
+			}
+			c.out = st
+		}
 	}
+
+	var err error
+	start := time.Now()
+	c.exp, err = loadExports(ctx, inputs)
 	if err != nil {
 		log.Fatal(err)
 	}
+	c.timings.add("parse", time.Since(start))
 
-	info, err = os.Stat(dir)
-	if err != nil || !info.IsDir() {
-		log.Fatal("Second argument is not a directory.")
+	if len(c.exp.Entries) < 1 {
+		log.Fatal("No blog entries found!")
 	}
 
-	b, err := ioutil.ReadFile(args[0])
+	err = c.convert(ctx, verifyMode, dir)
+	interrupted = ctx.Err() != nil
 	if err != nil {
-		log.Fatal(err)
+		failed = true
+		log.Printf("Some of the conversion failed:\n%s", err)
 	}
-
-	err = xml.Unmarshal(b, &exp)
-	if err != nil {
-		log.Fatal(err)
+	if st, ok := c.out.(*stateDir); ok {
+		if st.unchanged > 0 {
+			log.Printf("Left %d files that hadn't changed as they were.", st.unchanged)
+		}
+		if err := st.save(*stateFileName, interrupted); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *validateBuild && !verifyMode && !goldenMode && !interrupted {
+		n, err := c.validateSiteBuild(ctx, dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n > 0 {
+			failed = true
+		}
+	}
+	if *gitInit && !verifyMode && !goldenMode && !interrupted {
+		n, err := c.commitHistory(ctx, dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Committed the conversion into %s in %d commits.", dir, n)
+	}
+	if goldenMode && !interrupted {
+		mem := c.out.(*MemFS)
+		if *updateGolden {
+			err = writeGolden(mem, dir)
+		} else {
+			var n int
+			if n, err = compareGolden(mem, dir); n > 0 {
+				failed = true
+				log.Printf("%d files differ from the golden files in %s.", n, dir)
+			} else if err == nil {
+				log.Printf("The conversion matches the golden files in %s.", dir)
+			}
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
+}
 
-	if len(exp.Entries) < 1 {
-		log.Fatal("No blog entries found!")
+// Convert the loaded export into the output, or with verifyMode check the posts already
+// converted into dir against the live blog. Every post is tried, and what failed is returned.
+func (c *Converter) convert(ctx context.Context, verifyMode bool, dir string) error {
+	begin := time.Now()
+	// What failed, reported once everything else is done.
+	var errs []error
+
+	var sc *script
+	if *scriptFile != "" {
+		var err error
+		if sc, err = loadScript(*scriptFile); err != nil {
+			return err
+		}
 	}
 
 	postmap := make(map[uint64]int)
+	// Entries that are neither content nor the theme and settings, e.g. kinds Blogger added later.
+	unknownKinds := map[string][]string{}
+	// What -strict stops at.
+	var strictErrs []error
 
 	// Go through and create a map of all entries so we can refer to them later by ID number
-	for k := range exp.Entries {
+	for k := range c.exp.Entries {
+		normalizeTitle(&c.exp.Entries[k])
 		isTemplate := false
-		for _, tag := range exp.Entries[k].Tags {
+		for _, tag := range c.exp.Entries[k].Tags {
 			if tag.Scheme == "http://schemas.google.com/g/2005#kind" {
 				switch tag.Name {
 				case "http://schemas.google.com/blogger/2008/kind#comment":
 					fallthrough
+				case "http://schemas.google.com/blogger/2008/kind#page":
+					fallthrough
 				case "http://schemas.google.com/blogger/2008/kind#post":
 				default:
 					isTemplate = true
@@ -230,33 +435,99 @@ func main() {
 			}
 		}
 		if isTemplate {
+			if kind := c.exp.Entries[k].Kind(); kind != "template" && kind != "settings" {
+				unknownKinds[kind] = append(unknownKinds[kind], fmt.Sprintf("%q (%s)", c.exp.Entries[k].Title, c.exp.Entries[k].ID))
+				strictErrs = append(strictErrs, &blogger.EntryError{ID: c.exp.Entries[k].ID, Err: fmt.Errorf("unknown kind of entry %q", kind)})
+			}
 			continue
 		}
-		if index := strings.LastIndex(exp.Entries[k].ID, "post-"); index >= 0 {
-			exp.Entries[k].ID = exp.Entries[k].ID[index+5:]
-
-			if id, err := strconv.ParseUint(exp.Entries[k].ID, 10, 64); err == nil {
-				postmap[id] = k
+		if id := hugo.PostID(c.exp.Entries[k].ID); id != c.exp.Entries[k].ID {
+			c.exp.Entries[k].ID = id
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				postmap[n] = k
 			} else {
-				fmt.Println("Can't parse " + exp.Entries[k].ID)
+				log.Printf("Can't read the ID of %s %q, %s; comments on it won't be found.", c.exp.Entries[k].Kind(), c.exp.Entries[k].Title, c.exp.Entries[k].ID)
+				strictErrs = append(strictErrs, &blogger.EntryError{ID: c.exp.Entries[k].ID, Err: fmt.Errorf("can't read the ID: %w", err)})
 			}
 		}
-		for _, link := range exp.Entries[k].Links {
+		for _, link := range c.exp.Entries[k].Links {
 			switch strings.ToLower(link.Rel) {
 			case "related":
-				exp.Entries[k].Reply, _ = strconv.ParseUint(path.Base(link.Link), 10, 64)
+				c.exp.Entries[k].Reply, _ = strconv.ParseUint(path.Base(link.Link), 10, 64)
 			case "alternate":
+				c.exp.Entries[k].URL = link.Link
 			case "replies":
-				exp.Entries[k].Slug = strings.Replace(path.Base(link.Link), path.Ext(link.Link), "", -1)
+				c.exp.Entries[k].Slug = strings.Replace(path.Base(link.Link), path.Ext(link.Link), "", -1)
 			}
 		}
+		if c.exp.Entries[k].Slug == "" && c.exp.Entries[k].URL != "" {
+			c.exp.Entries[k].Slug = strings.TrimSuffix(path.Base(c.exp.Entries[k].URL), path.Ext(c.exp.Entries[k].URL))
+		}
+	}
+
+	if *strict && len(strictErrs) > 0 {
+		return fmt.Errorf("-strict: %w", errors.Join(strictErrs...))
+	}
+
+	if filled := c.fillDates(); len(filled) > 0 {
+		log.Printf("%d posts, pages and comments had a date missing or that couldn't be read, and were given:", len(filled))
+		for _, f := range filled {
+			log.Printf("\t%s", f)
+		}
+	}
+	c.markFeatured()
+	if gone := c.markDeleted(); len(gone) > 0 {
+		verb := "Left out"
+		if *deleted == "keep" {
+			verb = "Kept"
+		}
+		log.Printf("%s %d deleted posts, pages and comments:", verb, len(gone))
+		for _, g := range gone {
+			log.Printf("\t%s", g)
+		}
+	}
+	dups, kept := c.markDuplicates()
+	if len(dups) > 0 {
+		verb := "converted too; use -duplicates=skip to convert only one of each"
+		if *duplicates == "skip" {
+			verb = "left out for the one converted"
+		}
+		log.Printf("%d copies of posts and pages, as from publishing twice, were %s:", len(dups), verb)
+		for _, d := range dups {
+			log.Printf("\t%s", d)
+		}
+	}
+	// Their comments go to the copy converted.
+	for id, k := range postmap {
+		if keep, ok := kept[k]; ok {
+			postmap[id] = keep
+		}
+	}
+	if n := c.markHidden(); n > 0 && *hidden == "keep" {
+		log.Printf("%d posts and pages were private or hidden from search engines on the old blog; use -hidden to keep them from going public.", n)
+	}
+
+	if len(c.domainRewrites) > 0 {
+		start := time.Now()
+		c.rewriteDomains(ctx)
+		c.timings.add("download", time.Since(start))
+	}
+
+	if *multilingual != "" {
+		c.language = c.blogLanguage()
 	}
 
 	// Build comment heirarchy
-	for k, entry := range exp.Entries {
+	for k, entry := range c.exp.Entries {
+		if ctx.Err() != nil {
+			break
+		}
 		for _, tag := range entry.Tags {
 			if tag.Name == "http://schemas.google.com/blogger/2008/kind#comment" &&
 				tag.Scheme == "http://schemas.google.com/g/2005#kind" {
+				if skipDeleted(entry) {
+					break
+				}
 				parent := entry.Reply
 				if parent == 0 {
 					parent, _ = strconv.ParseUint(path.Base(entry.Source.Source), 10, 64)
@@ -265,94 +536,670 @@ func main() {
 					fmt.Println("Skipping deleted comment " + entry.ID)
 					break
 				}
-				if i, ok := postmap[parent]; ok {
-					exp.Entries[i].Children = append(exp.Entries[i].Children, k)
-				} else {
-					panic(strconv.Itoa(k) + " entry did not exist")
+				i, ok := postmap[parent]
+				if !ok {
+					errs = append(errs, &blogger.EntryError{ID: entry.ID, Err: fmt.Errorf("comment on post %d, which isn't in the export", parent)})
+					break
+				}
+				c.exp.Entries[i].Children = append(c.exp.Entries[i].Children, k)
+				if !verifyMode && !skipHidden(c.exp.Entries[i]) && !skipDeleted(c.exp.Entries[i]) {
+					start := time.Now()
+					if err := c.target.WriteComment(c, entry); err != nil {
+						errs = append(errs, &blogger.EntryError{ID: entry.ID, Err: err})
+					}
+					c.timings.add("write", time.Since(start))
 				}
-				writeComment(entry, dir)
 				break
 			}
 		}
 	}
 
+	// Place every post and page before writing any, so links between them can be rewritten.
+	var err error
+	languages := map[string]int{}
+	siteName := c.titleSiteName()
+	var scheduled []string
+	now := time.Now()
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if skipHidden(*e) || skipDeleted(*e) || e.Duplicate != "" {
+			continue
+		}
+		kind := e.Kind()
+		if sc != nil && (kind == "post" || kind == "page") {
+			if err := sc.run(ctx, e); err != nil {
+				errs = append(errs, &blogger.EntryError{ID: e.ID, Err: fmt.Errorf("-script on %s %q: %w", kind, e.Title, err)})
+				continue
+			}
+		}
+		if isScheduled(*e, now) {
+			scheduled = append(scheduled, fmt.Sprintf("%q (%s), for %s", e.Title, e.ID, e.Published))
+			switch *future {
+			case "skip":
+				continue
+			case "publishDate":
+				e.Draft, e.Scheduled = false, true
+			}
+		}
+		if len(titleCleanups) > 0 && (kind == "post" || kind == "page") {
+			e.Title = cleanTitle(e.Title, siteName)
+		}
+		if *multilingual != "" && (kind == "post" || kind == "page") {
+			e.Lang = detectLanguage(*e, c.language)
+			languages[e.Lang]++
+		}
+		switch kind {
+		case "post":
+			err = c.target.PlacePost(c, e)
+		case "page":
+			err = c.target.PlacePage(c, e)
+		default:
+			continue
+		}
+		if err != nil {
+			// Left without a Path, it isn't written.
+			errs = append(errs, &blogger.EntryError{ID: e.ID, Err: fmt.Errorf("placing %s %q: %w", e.Kind(), e.Title, err)})
+		}
+	}
+	if len(scheduled) > 0 {
+		verb := "were kept as drafts; use -future=publishDate to have Hugo publish them then"
+		switch *future {
+		case "publishDate":
+			verb = "were written to be published then"
+		case "skip":
+			verb = "were left out"
+		}
+		log.Printf("%d posts scheduled on Blogger for later %s:", len(scheduled), verb)
+		for _, s := range scheduled {
+			log.Printf("\t%s", s)
+		}
+	}
+	if len(languages) > 0 {
+		c.languages = make([]string, 0, len(languages))
+		for lang := range languages {
+			c.languages = append(c.languages, lang)
+		}
+		sort.Strings(c.languages)
+		counts := make([]string, len(c.languages))
+		for i, lang := range c.languages {
+			counts[i] = fmt.Sprintf("%s %d", lang, languages[lang])
+		}
+		log.Printf("Posts and pages by language: %s.", strings.Join(counts, ", "))
+	}
+	if verifyMode {
+		if err := c.verifySite(ctx, dir); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	c.indexLinks()
+	c.redirectDuplicates(kept)
+	if *aliases {
+		c.addAliases()
+	}
+	c.resolveRelated()
+	if *pageViews != "" {
+		if err := c.applyPageViews(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	audioStart := time.Now()
+	c.findEnclosures(ctx)
+	if *downloadAudio {
+		c.timings.add("download", time.Since(audioStart))
+	}
+	if *checkLinks {
+		start := time.Now()
+		c.checkAllLinks(ctx)
+		c.timings.add("download", time.Since(start))
+	}
+	if *bundleImages {
+		start := time.Now()
+		c.downloadImages(ctx)
+		c.timings.add("download", time.Since(start))
+	}
+
 	count := 0
 	drafts := 0
-	for k, entry := range exp.Entries {
-		isPost := false
-		for _, tag := range entry.Tags {
-			if tag.Name == "http://schemas.google.com/blogger/2008/kind#post" &&
-				tag.Scheme == "http://schemas.google.com/g/2005#kind" {
-				isPost = true
-				break
-			}
+	pages := 0
+	skipped := 0
+	var jobs []postJob
+	for k, entry := range c.exp.Entries {
+		kind := entry.Kind()
+		if kind != "post" && kind != "page" {
+			continue
+		}
+		if skipHidden(entry) {
+			skipped++
+			continue
 		}
-		if !isPost {
+		if entry.Path == "" {
 			continue
 		}
 		// Sort and flatten all top level comment chains
-		entry.Children = treeSort(k)
+		entry.Children = c.treeSort(k)
 		for _, v := range entry.Children {
-			if id, err := strconv.ParseUint(exp.Entries[v].ID, 10, 64); err == nil {
+			if id, err := strconv.ParseUint(c.exp.Entries[v].ID, 10, 64); err == nil {
 				entry.Comments = append(entry.Comments, id)
 			}
 		}
-		if extra != nil {
-			entry.Extra = *extra
+		entry.Extra = c.extra
+		if *bloggerID == "frontmatter" || *bloggerID == "both" {
+			entry.BloggerID = entry.ID
 		}
-		if err := writeEntry(entry, dir); err != nil {
-			log.Fatalf("Failed writing post %q to disk:\n%s", entry.Title, err)
+		if kind == "page" {
+			entry.Menu = *pagesMenu
 		}
-		if entry.Draft {
+		jobs = append(jobs, postJob{entry: entry})
+	}
+	start := time.Now()
+	c.writePosts(ctx, jobs)
+	var badMarkdown []string
+	for _, j := range jobs {
+		for _, p := range j.markdown {
+			badMarkdown = append(badMarkdown, fmt.Sprintf("%s (%s %q), %s", j.entry.Path, j.entry.Kind(), j.entry.Title, p))
+		}
+		if j.err != nil {
+			errs = append(errs, j.err)
+		}
+		c.unresolvedLinks = append(c.unresolvedLinks, j.unresolved...)
+		c.externalLinks = append(c.externalLinks, j.external...)
+		switch {
+		case !j.written:
+		case j.entry.Kind() == "page":
+			pages++
+		case bool(j.entry.Draft):
 			drafts++
-		} else {
+		default:
 			count++
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		log.Printf("Stopped (%s) before every post was written; the files written are complete, run again to convert the rest.", err)
+	} else if err := c.writeSiteFiles(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	c.timings.add("write", time.Since(start))
 	log.Printf("Wrote %d published posts to disk.", count)
 	log.Printf("Wrote %d drafts to disk.", drafts)
+	log.Printf("Wrote %d pages to disk.", pages)
+	if skipped > 0 {
+		log.Printf("Skipped %d private or hidden posts and pages.", skipped)
+	}
+	if len(c.unresolvedLinks) > 0 {
+		log.Printf("%d internal links could not be matched to a converted post or anchor:", len(c.unresolvedLinks))
+		for _, l := range c.unresolvedLinks {
+			log.Printf("\t%s", l)
+		}
+	}
+	if len(badMarkdown) > 0 {
+		log.Printf("%d places in the posts and pages won't render as they read, showing as raw text or hiding what follows:", len(badMarkdown))
+		for _, p := range badMarkdown {
+			log.Printf("\t%s", p)
+		}
+	}
+	if len(unknownKinds) > 0 {
+		kinds := make([]string, 0, len(unknownKinds))
+		for kind := range unknownKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			log.Printf("%d entries of kind %q, which the converter doesn't know, were not converted:", len(unknownKinds[kind]), kind)
+			for _, u := range unknownKinds[kind] {
+				log.Printf("\t%s", u)
+			}
+		}
+	}
+	if len(c.fallbacks) > 0 {
+		log.Printf("%d posts had no usable title and were given fallback slugs:", len(c.fallbacks))
+		for _, f := range c.fallbacks {
+			log.Printf("\t%s", f)
+		}
+	}
+	c.timings.add("convert", time.Since(begin)-c.timings.get("download")-c.timings.get("write"))
+	c.timings.finish(count + drafts + pages)
+	return errors.Join(errs...)
+}
+
+// Write the site-wide files the flags ask for, once every post is written. Each is tried
+// even if another fails.
+func (c *Converter) writeSiteFiles(ctx context.Context) error {
+	var errs []error
+	if err := c.target.WriteSite(c); err != nil {
+		errs = append(errs, fmt.Errorf("%s site files: %w", *target, err))
+	}
+	if *authorPages != "" {
+		if err := c.writeAuthorPages(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("author pages: %w", err))
+		}
+	}
+	if c.sections != "" || *splitByAuthor {
+		if err := c.writeSections(); err != nil {
+			errs = append(errs, fmt.Errorf("section pages: %w", err))
+		}
+	}
+	if *archetypeFile != "" {
+		if err := c.writeArchetype(); err != nil {
+			errs = append(errs, fmt.Errorf("archetype: %w", err))
+		}
+	}
+	if *siteConfigFile != "" {
+		if err := c.writeSiteConfig(); err != nil {
+			errs = append(errs, fmt.Errorf("site config: %w", err))
+		}
+	}
+	if *referenceDir != "" {
+		n, err := c.writeReference()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the Blogger theme and settings: %w", err))
+		} else {
+			log.Printf("Saved %d Blogger theme and settings files to %s.", n, *referenceDir)
+		}
+	}
+	if *blogrollFile != "" {
+		n, err := c.writeBlogroll()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("blogroll: %w", err))
+		} else if n == 0 {
+			log.Printf("The theme has no LinkList or BlogList gadgets with links, %s is empty.", *blogrollFile)
+		}
+	}
+	if c.redirectsFormat != "" {
+		if err := c.writeRedirects(); err != nil {
+			errs = append(errs, fmt.Errorf("redirects: %w", err))
+		}
+	}
+	if *hashbangJS != "" {
+		if err := c.writeHashbangJS(); err != nil {
+			errs = append(errs, fmt.Errorf("hash-bang redirect script: %w", err))
+		}
+	}
+	if *linkReport != "" {
+		if err := c.writeLinkReport(); err != nil {
+			errs = append(errs, fmt.Errorf("link report: %w", err))
+		}
+	}
+	if *mappingFile != "" {
+		if err := c.writeMapping(); err != nil {
+			errs = append(errs, fmt.Errorf("URL mapping: %w", err))
+		}
+	}
+	if *contentAdapter != "" {
+		if err := c.writeContentAdapter(); err != nil {
+			errs = append(errs, fmt.Errorf("content adapter: %w", err))
+		}
+	}
+	if *pageViewsData != "" {
+		if err := c.writePageViews(); err != nil {
+			errs = append(errs, fmt.Errorf("page views: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-var delim = []byte("+++\n")
+// Work out where a post is written, setting its Path.
+func (c *Converter) placeEntry(e *Entry) error {
+	sub := c.postDir(*e)
+	if e.Draft && *draftsDir != "" {
+		// Drafts are kept apart for review, outside the dated sections.
+		sub = path.Clean(filepath.ToSlash(*draftsDir))
+	}
+	name := c.entrySlug(*e)
+	prefix := ""
+	if !*noDatePrefix {
+		prefix = e.Published.String()[:10] + "-"
+	}
+	dir := c.languageDir(*e, sub)
+	var slug string
+	if c.slugInFrontMatter {
+		// The URL is made from the slug rather than the filename, whatever directory
+		// the file is in, so it's the slug that has to be unique.
+		claimed, err := c.claimPath(c.languageDir(*e, ""), name, *e)
+		if err != nil {
+			return err
+		}
+		e.Slug, slug = claimed, prefix+claimed
+	} else {
+		claimed, err := c.claimPath(dir, prefix+name, *e)
+		if err != nil {
+			return err
+		}
+		// As claimed, so the slug is the filename's.
+		e.Slug, slug = strings.TrimPrefix(claimed, prefix), claimed
+	}
+	if sub != "" && !(e.Draft && *draftsDir != "") {
+		c.addToSections(sub, e.Published)
+	}
+	e.Path = c.entryFile(dir, slug, *e)
+	c.addMapping(*e, e.Path)
+	return nil
+}
 
-func writeEntry(e Entry, dir string) error {
-	slug := makePath(e.Published, e.Title)
-	filename := filepath.Join(dir, slug+".md")
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+// Work out where a static page is written, which unlike a post has no date in its filename.
+func (c *Converter) placePage(e *Entry) error {
+	dir := c.languageDir(*e, path.Clean(filepath.ToSlash(*pagesDir)))
+	slug, err := c.claimPath(dir, c.entrySlug(*e), *e)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	e.Slug = slug
+	e.Path = c.entryFile(dir, slug, *e)
+	c.addMapping(*e, e.Path)
+	return nil
+}
 
-	return t.Execute(f, e)
+// The file a post or page with the slug is written to in dir: slug.md, or under -images
+// the page bundle slug/index.md.
+func (c *Converter) entryFile(dir, slug string, e Entry) string {
+	if *bundleImages {
+		return path.Join(dir, slug, "index"+c.languageSuffix(e)+".md")
+	}
+	return path.Join(dir, slug+c.languageSuffix(e)+".md")
 }
 
-func writeComment(e Entry, dir string) error {
-	e.Title = strings.Replace(strings.Replace(e.Title, "\n", "", -1), "\r", "", -1)
-	filename := filepath.Join(path.Join(dir, "comments"), "c"+e.ID+".toml")
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+func (c *Converter) writeFile(filename string, e Entry) error {
+	var buf bytes.Buffer
+	if err := c.t.Execute(&buf, e); err != nil {
+		return &WriteError{Path: filename, Err: err}
+	}
+	b := normalizeLines(buf.Bytes())
+	if *frontmatterOnly {
+		merged, ok, err := c.mergeFrontMatter(filename, b)
+		if err != nil {
+			return &WriteError{Path: filename, Err: err}
+		}
+		if ok {
+			b = merged
+		}
+	}
+	f, err := c.create(filename)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Strip trailing whitespace and convert line endings per -line-endings.
+func normalizeLines(b []byte) []byte {
+	switch *lineEndings {
+	case "keep":
+		return b
+	case "crlf":
+		return hugo.NormalizeLines(b, "\r\n")
+	}
+	return hugo.NormalizeLines(b, "\n")
+}
+
+// The slug an entry's filename is built from, per -slug-source.
+func (c *Converter) entrySlug(e Entry) string {
+	name := e.Title
+	if (c.slugSource == "blogger" || e.slugSet) && e.Slug != "" {
+		// Blogger truncates and strips stop-words from its slugs, so keep its version for URL fidelity.
+		name = e.Slug
+		if c.slug.KeepUnicode {
+			if u, err := url.PathUnescape(name); err == nil {
+				name = u
+			}
+		}
+	}
+	slug := c.makeSlug(name)
+	if !c.slug.Transliterable(name) {
+		slug = "post-" + e.ID
+		c.fallbacks = append(c.fallbacks, fmt.Sprintf("%s (post %s, %q has characters -transliterate doesn't know)", slug, e.ID, name))
+	} else if strings.Trim(slug, "-._") == "" {
+		slug = c.fallbackSlug(e)
+		c.fallbacks = append(c.fallbacks, fmt.Sprintf("%s (post %s)", slug, e.ID))
+	}
+	if *bloggerID == "filename" || *bloggerID == "both" {
+		slug += "-" + e.ID
+	}
+	return slug
+}
+
+// Reserve a filename for an entry, resolving clashes with earlier entries according to -on-collision.
+func (c *Converter) claimPath(dir, slug string, e Entry) (string, error) {
+	slug = hugo.SafeName(slug)
+	// Translations of a post are named alike, told apart by their language suffix.
+	key := func(s string) string { return strings.ToLower(path.Join(dir, s+c.languageSuffix(e))) }
+	prev, ok := c.claimed[key(slug)]
+	if !ok {
+		c.claimed[key(slug)] = e
+		return slug, nil
+	}
+	switch *collision {
+	case "fail":
+		return "", fmt.Errorf("%s.md is claimed by both %q (post %s) and %q (post %s)", slug, prev.Title, prev.ID, e.Title, e.ID)
+	case "id":
+		slug += "-" + e.ID
+	case "counter":
+		for i := 2; ; i++ {
+			if _, ok := c.claimed[key(fmt.Sprintf("%s-%d", slug, i))]; !ok {
+				slug = fmt.Sprintf("%s-%d", slug, i)
+				break
+			}
+		}
+	}
+	log.Printf("Post %q collides with %q, writing it as %s.md", e.Title, prev.Title, slug)
+	c.claimed[key(slug)] = e
+	return slug, nil
+}
 
-	return t.Execute(f, e)
+func (c *Converter) writeComment(e Entry) error {
+	e.Content = entryContent(e)
+	e.Title = strings.Replace(strings.Replace(e.Title, "\n", "", -1), "\r", "", -1)
+	return c.writeFile(c.mainLanguageDir(path.Join("comments", "c"+e.ID+".toml")), e)
 }
 
 // Take a string with any characters and replace it so the string could be used in a path.
-// E.g. Social Media -> social-media
-func makePath(d Date, s string) string {
-	return fmt.Sprintf("%v-%s", d.String()[:10], unicodeSanitize(strings.ToLower(strings.Replace(strings.TrimSpace(s), " ", "-", -1))))
+func (c *Converter) makeSlug(s string) string {
+	return c.slug.Slug(s)
 }
 
-func unicodeSanitize(s string) string {
-	source := []rune(s)
-	target := make([]rune, 0, len(source))
+// A Converter with the options set by flags.
+func flagConverter() *Converter {
+	c := newConverter()
+	c.slug = hugo.SlugOptions{
+		Transliterate: *translit,
+		KeepUnicode:   *slugUnicode == "keep",
+		MaxLength:     *maxSlugLen,
+	}
+	c.slugSource = *slugSource
+	// Checked by checkFlags.
+	c.domainRewrites, _ = parseDomainRewrites(*rewriteDomainsFlag)
+	c.redirectsFormat, c.redirectsFile = *redirectsFormat, *redirectsFile
+	c.sections = *sections
+	return c
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Build a slug for a post with an empty or symbol-only title from the first
+// words of its content, or failing that its post ID.
+func (c *Converter) fallbackSlug(e Entry) string {
+	return c.slug.FallbackSlug(entryContent(e), e.ID)
+}
 
-	for _, r := range source {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
-			target = append(target, r)
+// Check the flags whose values are picked from a list or have to make sense together.
+func checkFlags() error {
+	switch *slugSource {
+	case "blogger", "title":
+	default:
+		return fmt.Errorf("Unknown value for -slug-source: %s", *slugSource)
+	}
+	switch *slugUnicode {
+	case "sanitize", "keep":
+	default:
+		return fmt.Errorf("Unknown value for -slug-unicode: %s", *slugUnicode)
+	}
+	switch *sections {
+	case "", "year", "month":
+	default:
+		return fmt.Errorf("Unknown value for -sections: %s", *sections)
+	}
+	if _, ok := redirectFiles[*redirectsFormat]; !ok && *redirectsFormat != "" {
+		return fmt.Errorf("Unknown value for -redirects: %s", *redirectsFormat)
+	}
+	switch *internalLinks {
+	case "", "permalink", "relref":
+	default:
+		return fmt.Errorf("Unknown value for -internal-links: %s", *internalLinks)
+	}
+	switch *bloggerID {
+	case "", "frontmatter", "filename", "both":
+	default:
+		return fmt.Errorf("Unknown value for -blogger-id: %s", *bloggerID)
+	}
+	switch *lineEndings {
+	case "lf", "crlf", "keep":
+	default:
+		return fmt.Errorf("Unknown value for -line-endings: %s", *lineEndings)
+	}
+	switch *collision {
+	case "id", "counter", "fail":
+	default:
+		return fmt.Errorf("Unknown value for -on-collision: %s", *collision)
+	}
+	if *baseURL != "" {
+		if u, err := url.Parse(*baseURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("-base-url must be an http or https URL, e.g. https://example.com: %s", *baseURL)
 		}
 	}
-	return string(target)
+	if err := parseTitleCleanups(*titlesFlag); err != nil {
+		return fmt.Errorf("Bad -titles: %s", err)
+	}
+	if err := parseTypography(*typographyFlag); err != nil {
+		return fmt.Errorf("Bad -typography: %s", err)
+	}
+	if _, err := parseDomainRewrites(*rewriteDomainsFlag); err != nil {
+		return fmt.Errorf("Bad -rewrite-domains: %s", err)
+	}
+	if _, ok := importers[*inputFormat]; !ok && *inputFormat != "auto" {
+		return fmt.Errorf("Unknown value for -input-format: %s", *inputFormat)
+	}
+	switch *hidden {
+	case "keep", "skip", "draft", "unlisted":
+	default:
+		return fmt.Errorf("Unknown value for -hidden: %s", *hidden)
+	}
+	switch *deleted {
+	case "skip", "keep":
+	default:
+		return fmt.Errorf("Unknown value for -deleted: %s", *deleted)
+	}
+	if *workers < 1 {
+		return fmt.Errorf("-workers must be at least 1: %d", *workers)
+	}
+	if *maxMemory < 0 {
+		return fmt.Errorf("-max-memory must be at least 0: %d", *maxMemory)
+	}
+	switch *future {
+	case "draft", "publishDate", "skip":
+	default:
+		return fmt.Errorf("Unknown value for -future: %s", *future)
+	}
+	switch *rtl {
+	case "", "param", "wrap":
+	default:
+		return fmt.Errorf("Unknown value for -rtl: %s", *rtl)
+	}
+	switch *multilingual {
+	case "", "suffix", "dir":
+	default:
+		return fmt.Errorf("Unknown value for -multilingual: %s", *multilingual)
+	}
+	if *validateBuild && *outputArchive != "" {
+		return errors.New("-validate-build and -output-archive cannot be used together")
+	}
+	if *stateFileName != "" && *outputArchive != "" {
+		return errors.New("-state and -output-archive cannot be used together")
+	}
+	if flag.Arg(0) == "verify" && *outputArchive != "" {
+		return errors.New("verify reads the converted posts back from a directory and cannot be used with -output-archive")
+	}
+	if *missingDate != "" {
+		if _, err := missingDateValue(); err != nil {
+			return err
+		}
+	}
+	switch *migrationWarnings {
+	case "", "comment", "frontmatter":
+	default:
+		return fmt.Errorf("Unknown value for -migration-warnings: %s", *migrationWarnings)
+	}
+	if *migrationWarnings == "comment" && *target == "markdown" {
+		return errors.New("-migration-warnings=comment cannot be used with -target=markdown, whose notes have no HTML; use -migration-warnings=frontmatter")
+	}
+	switch *duplicates {
+	case "warn", "skip":
+	default:
+		return fmt.Errorf("Unknown value for -duplicates: %s", *duplicates)
+	}
+	if *gitInit && *outputArchive != "" {
+		return errors.New("-git-init and -output-archive cannot be used together")
+	}
+	if *contentAdapter != "" {
+		if adapterDataKeys(*contentAdapter) == nil {
+			return fmt.Errorf("Bad -content-adapter: %s, expected a file in the site's data directory", *contentAdapter)
+		}
+		if *multilingual != "" || *frontmatterOnly || *bundleImages {
+			return errors.New("-content-adapter cannot be used with -multilingual, -frontmatter-only or -images")
+		}
+	}
+	if _, ok := targets[*target]; !ok {
+		return fmt.Errorf("Unknown value for -target: %s", *target)
+	}
+	switch *target {
+	case "jekyll", "eleventy", "pelican", "hexo", "markdown":
+		if *format == "toml" {
+			return fmt.Errorf("-format=toml cannot be used with -target=%s, which has front matter of its own", *target)
+		}
+	}
+	if *markdownBodies && *target == "markdown" {
+		return errors.New("-markdown cannot be used with -target=markdown, whose notes are Markdown already")
+	}
+	if *aliases && *target != "hugo" && *target != "zola" {
+		return fmt.Errorf("-aliases is for Hugo and Zola sites and cannot be used with -target=%s; see -redirects", *target)
+	}
+	if *wikilinks && *target != "markdown" {
+		return errors.New("-wikilinks needs -target=markdown")
+	}
+	if *target == "markdown" && (*redirectsFormat != "" || *hashbangJS != "") {
+		return errors.New("-target=markdown writes notes, not a site, so there is nothing to redirect to")
+	}
+	if *target != "hugo" {
+		// What only a Hugo site knows what to do with.
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"-hugo-site", *hugoSite != ""},
+			{"-site-config", *siteConfigFile != ""},
+			{"-archetype", *archetypeFile != ""},
+			{"-sections", *sections != ""},
+			{"-split-by-author", *splitByAuthor},
+			{"-author-pages", *authorPages != ""},
+			{"-content-adapter", *contentAdapter != ""},
+			{"-validate-build", *validateBuild},
+			{"-multilingual", *multilingual != ""},
+			{"-internal-links=relref", *internalLinks == "relref"},
+			{"-images", *bundleImages},
+		} {
+			if f.set {
+				return fmt.Errorf("%s is for Hugo sites and cannot be used with -target=%s", f.name, *target)
+			}
+		}
+	}
+	if *translit && *slugUnicode == "keep" {
+		return errors.New("-transliterate and -slug-unicode=keep cannot be used together")
+	}
+	if flag.Arg(0) == "serve" {
+		return checkServeFlags()
+	}
+	return nil
 }
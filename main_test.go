@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+func TestFallbackSlug(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"<p>Just <b>back</b> from the lake, with the hills behind us</p>", "just-back-from-the-lake-with"},
+		{"<p>Short one</p>", "short-one"},
+		// Nothing usable, so the post ID.
+		{"<img src=\"a.jpg\">", "post-123"},
+		{"", "post-123"},
+	}
+	for _, tt := range tests {
+		var e Entry
+		e.ID, e.Content = "123", tt.content
+		if got := newConverter().fallbackSlug(e); got != tt.want {
+			t.Errorf("fallbackSlug(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+// Under -transliterate, a title with a Han character the table doesn't have is named
+// by its post ID, and listed, instead of losing part of the title.
+func TestTransliteratedSlugFallback(t *testing.T) {
+	c := newConverter()
+	c.slug = hugo.SlugOptions{Transliterate: true}
+	c.slugSource = "title"
+	for _, tt := range []struct {
+		title, want string
+	}{
+		{"北京", "bei-jing"},
+		{"北京龘", "post-42"},
+	} {
+		var e Entry
+		e.ID, e.Title = "42", tt.title
+		if got := c.entrySlug(e); got != tt.want {
+			t.Errorf("entrySlug(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+	if len(c.fallbacks) != 1 {
+		t.Errorf("fallbacks = %q, want the one post", c.fallbacks)
+	}
+}
+
+// Two Converters with different options convert side by side, each as set.
+func TestConvertersApart(t *testing.T) {
+	ctx := context.Background()
+	plain, apart := newConverter(), newConverter()
+	apart.slug = hugo.SlugOptions{Transliterate: true}
+	apart.slugSource = "title"
+	apart.sections = "year"
+	apart.domainRewrites = map[string]string{"example.blogspot.com": "example.com"}
+	var wg sync.WaitGroup
+	for _, c := range []*Converter{plain, apart} {
+		c.out = &MemFS{}
+		var err error
+		if c.exp, err = loadExports(ctx, []string{"tests/data/comments-blogger-backup.xml"}); err != nil {
+			t.Fatal(err)
+		}
+		defer c.exp.Close()
+		wg.Add(1)
+		go func(c *Converter) {
+			defer wg.Done()
+			if err := c.convert(ctx, false, ""); err != nil {
+				t.Error(err)
+			}
+		}(c)
+	}
+	wg.Wait()
+	for _, test := range []struct {
+		c          *Converter
+		post, link string
+	}{
+		{plain, "2020-02-20-ice-on-lake.md", "http://example.blogspot.com/2020/01/first-snow.html"},
+		{apart, "2020/2020-02-20-ice-on-the-lake.md", "http://example.com/2020/01/first-snow.html"},
+	} {
+		b, err := test.c.out.(*MemFS).ReadFile(test.post)
+		if err != nil {
+			t.Errorf("%s not written: %s; wrote %v", test.post, err, test.c.out.(*MemFS).Names())
+			continue
+		}
+		if !strings.Contains(string(b), test.link) {
+			t.Errorf("%s doesn't link to %s:\n%s", test.post, test.link, b)
+		}
+	}
+	if got := apart.makeSlug("Привет мир"); got != "privet-mir" {
+		t.Errorf("transliterated slug = %q, want privet-mir", got)
+	}
+	if got := plain.makeSlug("Привет мир"); got != "привет-мир" {
+		t.Errorf("slug = %q, want привет-мир", got)
+	}
+}
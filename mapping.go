@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var mappingFile = flag.String("mapping", "", "write a .json or .csv manifest mapping each post's Blogger URL to its file and Hugo permalink, relative to the target directory")
+
+type urlMapping struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Blogger   string `json:"blogger_url"`
+	Path      string `json:"path"`
+	Permalink string `json:"permalink"`
+}
+
+var baseURL = flag.String("base-url", "", "URL the Hugo site will be served from, e.g. https://example.com; makes rewritten links, permalinks and redirect targets absolute")
+
+func contentSection(dir string) string {
+	if section, ok := sectionOf(dir); ok {
+		return section
+	}
+	// A relative path such as ., or a symlink into the site.
+	if abs, err := filepath.Abs(dir); err == nil {
+		if real, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = real
+		}
+		if section, ok := sectionOf(abs); ok {
+			return section
+		}
+	}
+	return "/"
+}
+
+func sectionOf(dir string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "content" {
+			return "/" + path.Join(parts[i+1:]...), true
+		}
+	}
+	return "", false
+}
+
+// The permalink the site gives a content file.
+func (c *Converter) permalink(e Entry, name string) string {
+	return c.target.Permalink(c, e, name)
+}
+
+// The permalink Hugo gives a content file: from the site's pattern under -hugo-site,
+// otherwise from its path. E.g. 2014/2014-05-19-hello.md -> /posts/2014/2014-05-19-hello/
+func (c *Converter) hugoPermalink(e Entry, name string) string {
+	if e.Lang != "" {
+		// As for the main language, under /<lang>/ for the others.
+		plain := e
+		plain.Lang = ""
+		p := c.hugoPermalink(plain, c.unlocalized(e, name))
+		if e.Lang != c.language {
+			p = "/" + e.Lang + p
+		}
+		return p
+	}
+	if c.permalinkPattern != "" && e.Kind() == "post" {
+		return c.expandPermalink(c.permalinkPattern, e, name)
+	}
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if base := path.Base(name); base == "index" || base == "_index" {
+		name = path.Dir(name)
+	}
+	p := path.Join(c.urlPrefix, name)
+	if p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// Make a site path absolute under -base-url, if one is set.
+// E.g. /posts/hello/ -> https://example.com/posts/hello/
+func absURL(p string) string {
+	if *baseURL == "" {
+		return p
+	}
+	return strings.TrimSuffix(*baseURL, "/") + p
+}
+
+// Record where an entry was written for the -mapping manifest.
+func (c *Converter) addMapping(e Entry, name string) {
+	c.mappings = append(c.mappings, urlMapping{
+		Kind:      e.Kind(),
+		ID:        e.ID,
+		Blogger:   e.URL,
+		Path:      name,
+		Permalink: c.permalink(e, name),
+	})
+}
+
+func (c *Converter) writeMapping() error {
+	f, err := c.create(path.Clean(filepath.ToSlash(*mappingFile)))
+	if err != nil {
+		return err
+	}
+	var all []urlMapping
+	for _, m := range c.mappings {
+		m.Permalink = absURL(m.Permalink)
+		all = append(all, m)
+	}
+	for _, r := range c.feedRedirects() {
+		all = append(all, urlMapping{Kind: "feed", Blogger: r.From, Permalink: absURL(r.To)})
+	}
+	switch strings.ToLower(path.Ext(*mappingFile)) {
+	case ".json":
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(all)
+	case ".csv":
+		w := csv.NewWriter(f)
+		w.Write([]string{"kind", "id", "blogger_url", "path", "permalink"})
+		for _, m := range all {
+			w.Write([]string{m.Kind, m.ID, m.Blogger, m.Path, m.Permalink})
+		}
+		w.Flush()
+		err = w.Error()
+	default:
+		err = fmt.Errorf("Unknown manifest format for %s, expected .json or .csv", *mappingFile)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
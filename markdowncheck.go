@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var checkMarkdown = flag.Bool("check-markdown", false, "parse each post's body as Hugo's Markdown renderer, Goldmark, will and list the posts with parts that will show as raw text or swallow what follows: unclosed HTML, HTML indented into a code block, tables that don't parse")
+
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.Table)).Parser()
+
+// A table's delimiter row, e.g. |---|:--:|
+var tableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// HTML elements that, left open, take the rest of the post with them.
+var closedElements = map[string]bool{
+	"a": true, "article": true, "aside": true, "b": true, "blockquote": true, "center": true,
+	"div": true, "em": true, "figure": true, "font": true, "i": true, "iframe": true, "ol": true,
+	"pre": true, "script": true, "section": true, "span": true, "strong": true, "style": true,
+	"table": true, "textarea": true, "u": true, "ul": true,
+}
+
+var tagName = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)`)
+
+// What in a post's body won't render the way it reads, by line: parsed as Goldmark
+// parses it, and the HTML in it checked for elements that are never closed.
+func markdownProblems(body string) []string {
+	src := []byte(body)
+	line := func(offset int) int { return bytes.Count(src[:offset], []byte("\n")) + 1 }
+	var problems []string
+	doc := markdownParser.Parse(text.NewReader(src))
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock || n.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		lines := n.Lines()
+		first := lines.At(0)
+		switch n := n.(type) {
+		case *ast.CodeBlock:
+			if tagPattern.Match(first.Value(src)) {
+				problems = append(problems, fmt.Sprintf("line %d: indented HTML after a blank line is shown as code", line(first.Start)))
+			}
+		case *ast.HTMLBlock:
+			// <pre>, <script>, <style> and <textarea> run to their closing tag, and
+			// so do comments.
+			if n.HTMLBlockType <= ast.HTMLBlockType5 && !n.HasClosure() {
+				problems = append(problems, fmt.Sprintf("line %d: %s is never closed, so the rest of the post is taken as HTML", line(first.Start), strings.TrimSpace(string(tagPattern.Find(first.Value(src))))))
+			}
+		case *ast.Paragraph:
+			if lines.Len() < 2 || !strings.Contains(string(first.Value(src)), "|") {
+				break
+			}
+			if second := lines.At(1); tableDelimiter.Match(second.Value(src)) {
+				problems = append(problems, fmt.Sprintf("line %d: a table doesn't parse, its rows and header differing, so it's shown as text", line(first.Start)))
+			}
+		case *extast.Table:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// Elements still open at the end, outermost first.
+	type open struct {
+		name string
+		at   int
+	}
+	var stack []open
+	for _, loc := range tagPattern.FindAllIndex(src, -1) {
+		tag := src[loc[0]:loc[1]]
+		m := tagName.FindSubmatch(tag)
+		if m == nil || bytes.HasSuffix(tag, []byte("/>")) {
+			continue
+		}
+		name := strings.ToLower(string(m[2]))
+		if !closedElements[name] {
+			continue
+		}
+		if len(m[1]) == 0 {
+			stack = append(stack, open{name, loc[0]})
+			continue
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].name == name {
+				stack = stack[:i]
+				break
+			}
+		}
+	}
+	if len(stack) > 0 {
+		problems = append(problems, fmt.Sprintf("line %d: <%s> is never closed", line(stack[0].at), stack[0].name))
+	}
+	return problems
+}
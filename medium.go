@@ -0,0 +1,110 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"html"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Medium's export (Settings > Download your information) is a zip with one HTML
+// file per story under posts/, e.g. posts/2019-05-01_My-Story-abc123def456.html,
+// and drafts named draft_... . Each file is a microformats h-entry.
+type mediumImporter struct{}
+
+var (
+	mediumTitle     = regexp.MustCompile(`(?is)<h1[^>]*class="p-name"[^>]*>(.*?)</h1>`)
+	mediumPageTitle = regexp.MustCompile(`(?is)<title>(.*?)</title>`)
+	mediumBody      = regexp.MustCompile(`(?is)<section[^>]*data-field="body"[^>]*>(.*)</section>\s*<footer`)
+	mediumPublished = regexp.MustCompile(`(?is)<time[^>]*class="dt-published"[^>]*datetime="([^"]+)"`)
+	mediumAuthor    = regexp.MustCompile(`(?is)<a[^>]*class="p-author h-card"[^>]*>(.*?)</a>`)
+	mediumCanonical = regexp.MustCompile(`(?is)<a[^>]*href="([^"]+)"[^>]*class="p-canonical"`)
+	// The story's title repeated as the first heading of its body.
+	mediumTitleGraf = regexp.MustCompile(`(?is)^(\s*(?:<[^>]+>\s*)*?)<h3[^>]*graf--title[^>]*>.*?</h3>`)
+	// Medium story URLs end in a 12 character hex ID, e.g. /my-story-abc123def456
+	mediumStoryID = regexp.MustCompile(`-[0-9a-f]{10,12}$`)
+)
+
+func (mi mediumImporter) Detect(b []byte) bool {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	return err == nil && mi.DetectZip(z)
+}
+
+func (mediumImporter) DetectZip(z *zip.Reader) bool {
+	for _, f := range z.File {
+		if strings.HasPrefix(f.Name, "posts/") && path.Ext(f.Name) == ".html" {
+			return true
+		}
+	}
+	return false
+}
+
+func (mi mediumImporter) Import(b []byte) (Export, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return Export{}, err
+	}
+	return mi.ImportZip(z)
+}
+
+func (mediumImporter) ImportZip(z *zip.Reader) (Export, error) {
+	var files []*zip.File
+	for _, f := range z.File {
+		if strings.HasPrefix(f.Name, "posts/") && path.Ext(f.Name) == ".html" {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	exp := Export{Title: "Medium"}
+	for i, f := range files {
+		page, err := readZipFile(f)
+		if err != nil {
+			return Export{}, err
+		}
+		exp.Entries = append(exp.Entries, mediumEntry(i, path.Base(f.Name), string(page)))
+	}
+	return exp, nil
+}
+
+func mediumEntry(i int, name, page string) Entry {
+	first := func(re *regexp.Regexp) string {
+		if m := re.FindStringSubmatch(page); m != nil {
+			return strings.TrimSpace(m[1])
+		}
+		return ""
+	}
+	e := Entry{
+		Entry: blogger.Entry{
+			ID:    "medium.post-" + strconv.Itoa(i+1),
+			Title: html.UnescapeString(tagPattern.ReplaceAllString(first(mediumTitle), "")),
+			Tags:  Tags{kindTag("post")},
+			Draft: Draft(strings.HasPrefix(name, "draft_")),
+		},
+	}
+	if e.Title == "" {
+		e.Title = html.UnescapeString(first(mediumPageTitle))
+	}
+	e.Content = mediumTitleGraf.ReplaceAllString(first(mediumBody), "$1")
+	e.Published = feedDate(first(mediumPublished))
+	e.Updated = e.Published
+	e.Author.Name = html.UnescapeString(tagPattern.ReplaceAllString(first(mediumAuthor), ""))
+	if u := html.UnescapeString(first(mediumCanonical)); u != "" && !e.Draft {
+		e.Links = []Reply{{Rel: "alternate", Link: u}}
+		e.Slug = mediumStoryID.ReplaceAllString(path.Base(u), "")
+	} else {
+		// posts/2019-05-01_My-Story-abc123def456.html
+		base := strings.TrimSuffix(strings.TrimPrefix(name, "draft_"), ".html")
+		if j := strings.Index(base, "_"); j >= 0 {
+			base = base[j+1:]
+		}
+		e.Slug = strings.ToLower(mediumStoryID.ReplaceAllString(base, ""))
+	}
+	return e
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// A zip holding the given files, in name order.
+func zipOf(t *testing.T, files ...[2]string) string {
+	t.Helper()
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for _, f := range files {
+		w, err := z.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f[1]))
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+const mediumStory = `<!DOCTYPE html><html><head><title>My Story</title></head><body><article class="h-entry">
+<header><h1 class="p-name">My &amp; Story</h1></header>
+<section data-field="body" class="e-content"><section><div><h3 class="graf graf--h3 graf--title">My &amp; Story</h3><p class="graf">Once upon a time.</p></div></section></section>
+<footer><p>By <a href="https://medium.com/@ann" class="p-author h-card">Ann</a> on <time class="dt-published" datetime="2019-05-01T12:00:00.000Z">May 1, 2019</time></p>
+<p><a href="https://medium.com/@ann/my-story-abc123def456" class="p-canonical">Canonical link</a></p></footer></article></body></html>`
+
+const mediumDraft = `<!DOCTYPE html><html><head><title>Later</title></head><body><article class="h-entry">
+<section data-field="body" class="e-content"><p>Someday.</p></section>
+<footer></footer></article></body></html>`
+
+func TestMediumImport(t *testing.T) {
+	export := zipOf(t,
+		[2]string{"README.html", "<p>Your data</p>"},
+		[2]string{"posts/draft_Later-0123456789ab.html", mediumDraft},
+		[2]string{"posts/2019-05-01_My-Story-abc123def456.html", mediumStory},
+	)
+	testImport(t, "medium", export, []string{
+		`post medium.post-1 "My & Story" by Ann at 2019-05-01T12:00:00Z`,
+		// Titled by the page, as the draft has no heading yet.
+		`post medium.post-2 "Later" by  at 0001-01-01T00:00:00Z draft`,
+	})
+
+	exp, err := mediumImporter{}.Import([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	story, draft := exp.Entries[0], exp.Entries[1]
+	if want := `<section><div><p class="graf">Once upon a time.</p></div></section>`; story.Content != want {
+		t.Errorf("story content = %q, want %q, without the title repeated", story.Content, want)
+	}
+	if story.Slug != "my-story" || draft.Slug != "later" {
+		t.Errorf("slugs = %q and %q, want my-story and later", story.Slug, draft.Slug)
+	}
+}
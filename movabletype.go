@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// The Movable Type export format, which TypePad also exports: entries of KEY: value
+// header lines and multi-line sections (BODY:, COMMENT:, ...) ended by "-----",
+// with "--------" between entries.
+type mtImporter struct{}
+
+var mtHeader = regexp.MustCompile(`^[A-Z][A-Z ]*: ?`)
+
+func (mtImporter) Detect(b []byte) bool {
+	b = bytes.TrimSpace(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n")))
+	return mtHeader.Match(b) && bytes.Contains(b, []byte("\n-----\n")) && bytes.Contains(b, []byte("\nBODY:"))
+}
+
+func (mtImporter) Import(b []byte) (Export, error) {
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	var exp Export
+	comments := 0
+	for i, chunk := range strings.Split(text, "\n--------\n") {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		sections := strings.Split(strings.TrimSuffix(strings.TrimRight(chunk, "\n"), "\n-----"), "\n-----\n")
+		head := mtFields(sections[0])
+		id := strconv.Itoa(i + 1)
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        "mt.post-" + id,
+				Title:     head.get("TITLE"),
+				Published: mtDate(head.get("DATE")),
+				Tags:      Tags{kindTag("post")},
+				Author:    Author{Name: head.get("AUTHOR")},
+				Draft:     Draft(strings.EqualFold(head.get("STATUS"), "draft")),
+			},
+			Slug: head.get("BASENAME"),
+		}
+		e.Updated = e.Published
+		if u := head.get("UNIQUE URL"); u != "" && !e.Draft {
+			e.Links = []Reply{{Rel: "alternate", Link: u}}
+		}
+		for _, c := range head["CATEGORY"] {
+			e.Tags = append(e.Tags, labelTag(c))
+		}
+		for _, t := range mtTags(head.get("TAGS")) {
+			e.Tags = append(e.Tags, labelTag(t))
+		}
+		convert := head.get("CONVERT BREAKS")
+		body, extended := "", ""
+		var entryComments []Entry
+		for _, s := range sections[1:] {
+			name, value, _ := strings.Cut(strings.TrimLeft(s, "\n"), ":")
+			value = strings.TrimPrefix(value, "\n")
+			switch name {
+			case "BODY":
+				body = mtText(value, convert)
+			case "EXTENDED BODY":
+				extended = mtText(value, convert)
+			case "COMMENT":
+				comments++
+				fields, text := mtComment(value)
+				c := Entry{
+					Entry: blogger.Entry{
+						ID:        "mt.post-" + strconv.FormatUint(commentIDBase+uint64(comments), 10),
+						Published: mtDate(fields.get("DATE")),
+						Title:     fields.get("AUTHOR"),
+						Content:   mtText(text, "1"),
+						Tags:      Tags{kindTag("comment")},
+						Author:    Author{Name: fields.get("AUTHOR"), Uri: fields.get("URL")},
+						Source:    Reply{Source: "mt/" + id},
+					},
+				}
+				c.Updated = c.Published
+				entryComments = append(entryComments, c)
+			}
+		}
+		e.Content = body
+		if strings.TrimSpace(extended) != "" {
+			// Hugo's summary divider, where MT showed "Continue reading".
+			e.Content += "\n<!--more-->\n" + extended
+		}
+		exp.Entries = append(exp.Entries, e)
+		exp.Entries = append(exp.Entries, entryComments...)
+	}
+	return exp, nil
+}
+
+// Header fields by key; CATEGORY can repeat.
+type mtHeaderFields map[string][]string
+
+func (f mtHeaderFields) get(key string) string {
+	if v := f[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func mtFields(s string) mtHeaderFields {
+	f := mtHeaderFields{}
+	for _, line := range strings.Split(s, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok && mtHeader.MatchString(line) {
+			f[key] = append(f[key], strings.TrimSpace(value))
+		}
+	}
+	return f
+}
+
+// A comment's header lines, up to the first line that isn't one, and its text.
+func mtComment(s string) (mtHeaderFields, string) {
+	lines := strings.Split(s, "\n")
+	n := 0
+	for n < len(lines) && mtHeader.MatchString(lines[n]) {
+		n++
+	}
+	return mtFields(strings.Join(lines[:n], "\n")), strings.Join(lines[n:], "\n")
+}
+
+// TAGS: "two words",single
+func mtTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.Trim(strings.TrimSpace(t), `"`); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// MT dates are local to the blog, with no zone, in 12 or 24 hour time.
+func mtDate(s string) Date {
+	for _, layout := range []string{"01/02/2006 03:04:05 PM", "01/02/2006 15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return Date(t)
+		}
+	}
+	return Date{}
+}
+
+// Text an entry was written with CONVERT BREAKS on has its paragraphs and line breaks
+// added at publish time, so add them here. Other filters (markdown, textile) are left alone.
+func mtText(s, convert string) string {
+	s = strings.Trim(s, "\n")
+	if convert != "1" && convert != "__default__" {
+		return s
+	}
+	return autoParagraphs(s)
+}
+
+var blockTag = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|form|hr|figure)\b`)
+
+// Wrap blank line separated text in <p> and turn single newlines into <br />.
+func autoParagraphs(s string) string {
+	var paras []string
+	for _, p := range regexp.MustCompile(`\n\s*\n`).Split(s, -1) {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if blockTag.MatchString(p) {
+			paras = append(paras, p)
+			continue
+		}
+		paras = append(paras, "<p>"+strings.ReplaceAll(p, "\n", "<br />\n")+"</p>")
+	}
+	return strings.Join(paras, "\n")
+}
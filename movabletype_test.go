@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+const testMT = `AUTHOR: Ann
+TITLE: Hello World
+BASENAME: hello_world
+STATUS: Publish
+CONVERT BREAKS: 1
+DATE: 03/01/2006 02:30:00 PM
+CATEGORY: News
+CATEGORY: Go
+TAGS: "two words",single
+UNIQUE URL: https://ann.typepad.com/blog/2006/03/hello_world.html
+-----
+BODY:
+First paragraph
+second line.
+
+Second paragraph.
+-----
+EXTENDED BODY:
+The rest.
+-----
+COMMENT:
+AUTHOR: Bob
+URL: https://bob.example.com/
+DATE: 03/02/2006 09:00:00 AM
+Nice post.
+-----
+--------
+AUTHOR: Ann
+TITLE: Not yet
+STATUS: Draft
+CONVERT BREAKS: 0
+DATE: 2006-04-01 10:00:00
+-----
+BODY:
+<p>Someday.</p>
+-----
+--------
+`
+
+func TestMovableTypeImport(t *testing.T) {
+	testImport(t, "auto", testMT, []string{
+		`post mt.post-1 "Hello World" by Ann at 2006-03-01T14:30:00Z labels News, Go, two words, single`,
+		`comment mt.post-1099511627777 "Bob" by Bob at 2006-03-02T09:00:00Z on mt/1`,
+		`post mt.post-2 "Not yet" by Ann at 2006-04-01T10:00:00Z draft`,
+	})
+	exp, err := mtImporter{}.Import([]byte(testMT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<p>First paragraph<br />\nsecond line.</p>\n<p>Second paragraph.</p>\n<!--more-->\n<p>The rest.</p>"
+	if got := exp.Entries[0].Content; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if got := exp.Entries[0].Slug; got != "hello_world" {
+		t.Errorf("slug = %q, want hello_world", got)
+	}
+	// Written without CONVERT BREAKS, so as it was.
+	if got := exp.Entries[2].Content; got != "<p>Someday.</p>" {
+		t.Errorf("draft content = %q", got)
+	}
+}
+
+func TestAutoParagraphs(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"One\ntwo", "<p>One<br />\ntwo</p>"},
+		{"One\n\n  \nTwo", "<p>One</p>\n<p>Two</p>"},
+		{"<blockquote>Quoted</blockquote>\n\nAfter", "<blockquote>Quoted</blockquote>\n<p>After</p>"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := autoParagraphs(tt.in); got != tt.want {
+			t.Errorf("autoParagraphs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
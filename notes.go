@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+var wikilinks = flag.Bool("wikilinks", false, "with -target=markdown, write links between posts as [[wikilinks]], the way Obsidian and other note apps link notes")
+
+// Plain Markdown notes, for keeping the writing rather than a site: a vault of
+// YYYY/MM/YYYY-MM-DD-slug.md files, with the posts' HTML turned into Markdown, their
+// comments at the end, and only the few properties note apps read.
+type markdownTarget struct{}
+
+var markdownTempl = `---
+title: "{{ .Title }}"
+date: {{ slice .Published.String 0 10 }}{{ with noteTags . }}
+tags: [{{ . }}]{{ end }}{{ with .URL }}
+source: "{{ . }}"{{ end }}{{ with quoteList .Warnings }}
+migration_warnings: [{{ . }}]{{ end }}{{ if .Draft }}
+draft: true{{ end }}
+---
+
+# {{ .Title }}
+
+{{ noteBody . }}{{ with noteComments . }}
+## Comments
+
+{{ . }}{{ end }}`
+
+func (markdownTarget) Template() string { return markdownTempl }
+
+func (markdownTarget) PlacePost(c *Converter, e *Entry) error {
+	date := e.Published.String()[:10]
+	dir := date[:4] + "/" + date[5:7]
+	name, err := c.claimPath(dir, date+"-"+c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = strings.TrimPrefix(name, date+"-")
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (markdownTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := "pages"
+	if *pagesDir != "" {
+		dir = path.Clean(filepath.ToSlash(*pagesDir))
+	}
+	name, err := c.claimPath(dir, c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// A note has no URL, only its path in the vault.
+func (markdownTarget) Permalink(c *Converter, e Entry, name string) string { return name }
+
+// Comments go at the end of their post, see noteComments.
+func (markdownTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+func (markdownTarget) WriteSite(c *Converter) error { return nil }
+
+// A post's body as Markdown, its links to other posts pointing at their notes. HTML
+// that can't be read is kept as it is.
+func (c *Converter) noteBody(e Entry) string {
+	return markdownBody(e, func(href, text string) string { return c.noteLink(e, href, text) })
+}
+
+// A link from e to another post's note, as a wikilink under -wikilinks or a relative
+// Markdown link, or "" if href isn't to a post converted.
+func (c *Converter) noteLink(e Entry, href, text string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || !c.blogHosts[normalizeHost(u.Host)] {
+		return ""
+	}
+	target, ok := c.linkTargets[linkKey(u)]
+	if !ok || target.Path == "" {
+		return ""
+	}
+	note := strings.TrimSuffix(path.Base(target.Path), ".md")
+	if *wikilinks {
+		if text == "" || text == note {
+			return "[[" + note + "]]"
+		}
+		return "[[" + note + "|" + strings.ReplaceAll(text, "|", `\|`) + "]]"
+	}
+	rel, err := filepath.Rel(path.Dir(e.Path), target.Path)
+	if err != nil {
+		return ""
+	}
+	if text == "" {
+		text = note
+	}
+	return fmt.Sprintf("[%s](%s)", text, strings.ReplaceAll(filepath.ToSlash(rel), " ", "%20"))
+}
+
+// A post's comments, oldest thread first, each under its author and date.
+func (c *Converter) noteComments(e Entry) string {
+	var b strings.Builder
+	for n, i := range e.Children {
+		comment := c.exp.Entries[i]
+		if n > 0 {
+			b.WriteString("\n")
+		}
+		body, err := htmlToMarkdown(entryContent(comment), nil)
+		if err != nil {
+			body = entryContent(comment) + "\n"
+		}
+		fmt.Fprintf(&b, "**%s**, %s:\n\n> %s\n", strings.TrimSpace(comment.Author.Name), comment.Published.String()[:10],
+			strings.ReplaceAll(strings.TrimSpace(body), "\n", "\n> "))
+	}
+	return b.String()
+}
+
+// A post's labels as note tags, which can't have spaces or #.
+func noteTags(e Entry) string {
+	var tags []string
+	for _, l := range e.Tags.Labels() {
+		if t := strings.Trim(strings.Join(strings.Fields(strings.ReplaceAll(l, "#", "")), "-"), "-"); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return hugo.QuoteList(tags)
+}
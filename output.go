@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WriteFS is where generated files are written. Names are slash-separated paths
+// relative to the root of the output, as with io/fs.
+type WriteFS interface {
+	Create(name string) (io.WriteCloser, error)
+}
+
+// ReadFS is implemented by outputs whose files can be read back after writing.
+type ReadFS interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// A WriteError is an output file that couldn't be written.
+type WriteError struct {
+	Path string // slash-separated, relative to the output
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("writing %s: %s", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// Create a file in the output, failing with a *WriteError.
+func (c *Converter) create(name string) (io.WriteCloser, error) {
+	w, err := c.out.Create(name)
+	if err != nil {
+		return nil, &WriteError{Path: name, Err: err}
+	}
+	return outFile{w, name}, nil
+}
+
+type outFile struct {
+	io.WriteCloser
+	name string
+}
+
+func (f outFile) Write(p []byte) (int, error) {
+	n, err := f.WriteCloser.Write(p)
+	if err != nil {
+		err = &WriteError{Path: f.name, Err: err}
+	}
+	return n, err
+}
+
+func (f outFile) Close() error {
+	if err := f.WriteCloser.Close(); err != nil {
+		return &WriteError{Path: f.name, Err: err}
+	}
+	return nil
+}
+
+// DirFS writes files into the directory tree rooted at the given path.
+type DirFS string
+
+func (d DirFS) Create(name string) (io.WriteCloser, error) {
+	full, err := d.path("create", name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(full), "."+filepath.Base(full)+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &dirFile{f: f, name: full}, nil
+}
+
+// A file written under a temporary name, which only takes its real name when it's closed
+// after writing without errors, so an interrupted run leaves no half-written files.
+type dirFile struct {
+	f    *os.File
+	name string
+	err  error
+}
+
+func (f *dirFile) Write(p []byte) (int, error) {
+	n, err := f.f.Write(p)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return n, err
+}
+
+func (f *dirFile) Close() error {
+	err := f.f.Close()
+	if err == nil {
+		err = f.err
+	}
+	if err == nil {
+		err = os.Chmod(f.f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.f.Name(), f.name)
+	}
+	if err != nil {
+		os.Remove(f.f.Name())
+	}
+	return err
+}
+
+func (d DirFS) ReadFile(name string) ([]byte, error) {
+	full, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(full)
+}
+
+// The local path of the file name in d. Backslashes in name, as in zips made on Windows,
+// are taken as separators, so the same tree is written on every platform. Names may
+// climb out of d with .., as -pages-dir=.. does, but may not be absolute.
+func (d DirFS) path(op, name string) (string, error) {
+	name = path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return longPath(filepath.Join(string(d), filepath.FromSlash(name))), nil
+}
+
+// On Windows, a path too long for the old 260 character limit, made absolute and given
+// the \\?\ prefix that lifts it; Windows takes only whole backslashed paths that way.
+func longPath(p string) string {
+	if runtime.GOOS != "windows" || len(p) < 248 || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
+
+// MemFS keeps written files in memory, e.g. for tests or for handing the output
+// to something other than the local disk.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	return &memFile{m: m, name: name}, nil
+}
+
+// ReadFile returns the contents of a file written to m.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return b, nil
+}
+
+// Names lists every file written to m in sorted order.
+func (m *MemFS) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type memFile struct {
+	bytes.Buffer
+	m    *MemFS
+	name string
+}
+
+func (f *memFile) Close() error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.m.files == nil {
+		f.m.files = map[string][]byte{}
+	}
+	f.m.files[f.name] = f.Bytes()
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var pageViews = flag.String("pageviews", "", "a CSV of page views by post, its Blogger URL or path then its count, e.g. exported from Google Analytics; each post's count is written as views in its front matter")
+var pageViewsData = flag.String("pageviews-data", "", "write the posts' page views by permalink to this JSON data file, relative to the target directory, e.g. ../../data/pageviews.json; with -blogger-api and -api-token, the blog's totals from the API too")
+
+// The ranges of the blog's page views the Blogger API is asked for.
+var pageViewRanges = []string{"all", "30DAYS", "7DAYS"}
+
+type apiPageViews struct {
+	Counts []struct {
+		TimeRange string `json:"timeRange"`
+		Count     int64  `json:"count,string"`
+	} `json:"counts"`
+}
+
+// Fetch the blog's page views from the Blogger API, by time range. The API only
+// counts them for the whole blog, and only for its admins.
+func fetchPageViews(ctx context.Context, blogID string) (map[string]int64, error) {
+	var v apiPageViews
+	if err := apiGet(ctx, "blogs/"+url.PathEscape(blogID)+"/pageviews", url.Values{"range": pageViewRanges}, &v); err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, c := range v.Counts {
+		counts[c.TimeRange] = c.Count
+	}
+	return counts, nil
+}
+
+// Read -pageviews, summing the counts by path so the ?m=1 and other variants of a
+// post's URL count towards it. A header row and rows without a count are skipped.
+func readPageViews(name string) (map[string]int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.Comment = '#'
+	views := map[string]int64{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return views, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 2 {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(row[1]), ",", ""), 10, 64)
+		if err != nil {
+			continue
+		}
+		if p := bloggerPath(row[0]); p != "" {
+			views[p] += n
+		}
+	}
+}
+
+// Give each post and page its page views from -pageviews.
+func (c *Converter) applyPageViews() error {
+	views, err := readPageViews(*pageViews)
+	if err != nil {
+		return fmt.Errorf("-pageviews: %w", err)
+	}
+	counted := 0
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if kind := e.Kind(); kind != "post" && kind != "page" || e.Path == "" || e.URL == "" {
+			continue
+		}
+		if n, ok := views[bloggerPath(e.URL)]; ok {
+			e.Views = n
+			counted++
+		}
+	}
+	log.Printf("Found the page views of %d posts and pages in %s.", counted, *pageViews)
+	return nil
+}
+
+// Write -pageviews-data: the posts' page views by permalink, and the blog's totals.
+func (c *Converter) writePageViews() error {
+	data := struct {
+		Blog  map[string]int64 `json:"blog,omitempty"`
+		Posts map[string]int64 `json:"posts"`
+	}{Blog: c.exp.PageViews, Posts: map[string]int64{}}
+	for _, e := range c.exp.Entries {
+		if e.Views > 0 {
+			data.Posts[absURL(c.permalink(e, e.Path))] = e.Views
+		}
+	}
+	f, err := c.create(path.Clean(filepath.ToSlash(*pageViewsData)))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Pelican's articles: YYYY-MM-DD-slug.md in its content directory, with pages under
+// pages/, each headed by Pelican's Markdown metadata, a Key: value line each.
+type pelicanTarget struct{}
+
+var pelicanTempl = `Title: {{ pelicanValue .Title }}
+Date: {{ .Published }}
+Modified: {{ .Updated }}
+Slug: {{ .Slug }}{{ with .Tags.Labels }}
+Tags: {{ pelicanValue (join . ", ") }}{{ end }}
+Author: {{ pelicanValue .Author.Name }}{{ if .Draft }}
+Status: draft{{ else if .Unlisted }}
+Status: hidden{{ end }}{{ if .Featured }}
+{{ featuredKey }}: true{{ end }}{{ with .Views }}
+Views: {{ . }}{{ end }}{{ with .BloggerID }}
+Blogger_id: {{ . }}{{ end }}{{ with .URL }}
+Blogger_url: {{ . }}{{ end }}{{ range $k, $v := .Params }}
+{{ $k }}: {{ pelicanValue $v }}{{ end }}{{ with .Related }}
+Related: {{ join . ", " }}{{ end }}{{ with .Warnings }}
+Migration_warnings: {{ pelicanValue (join . "; ") }}{{ end }}{{ with .Extra }}
+{{.}}{{ end }}
+
+{{ .Content }}
+`
+
+func (pelicanTarget) Template() string { return pelicanTempl }
+
+func (pelicanTarget) PlacePost(c *Converter, e *Entry) error {
+	date := e.Published.String()[:10]
+	name, err := c.claimPath("", date+"-"+c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = strings.TrimPrefix(name, date+"-")
+	e.Path = name + ".md"
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (pelicanTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := "pages"
+	if *pagesDir != "" {
+		dir = path.Clean(filepath.ToSlash(*pagesDir))
+	}
+	name, err := c.claimPath(dir, c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// Pelican's default URLs, ARTICLE_URL and PAGE_URL: /hello.html and /pages/about.html.
+func (pelicanTarget) Permalink(c *Converter, e Entry, name string) string {
+	if e.Kind() == "page" {
+		return "/pages/" + e.Slug + ".html"
+	}
+	return "/" + e.Slug + ".html"
+}
+
+// Comments are written by article once they have their slugs, see WriteSite.
+func (pelicanTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+// Write each article's comments into comments/<slug>/, one file a comment with its own
+// metadata, the way the pelican-comment-system plugin reads them.
+func (pelicanTarget) WriteSite(c *Converter) error {
+	var errs []error
+	for k, post := range c.exp.Entries {
+		if post.Path == "" || len(post.Children) == 0 {
+			continue
+		}
+		for _, comment := range c.dataComments(k) {
+			if err := c.writePelicanComment(post.Slug, comment); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Converter) writePelicanComment(slug string, comment dataComment) error {
+	f, err := c.create(path.Join("comments", slug, "c"+comment.ID+".md"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "author: %s\ndate: %s\n", pelicanValue(comment.Author), comment.Date)
+	if comment.AuthorURI != "" {
+		fmt.Fprintf(f, "website: %s\n", comment.AuthorURI)
+	}
+	if comment.ReplyingTo != "" {
+		fmt.Fprintf(f, "replyto: c%smd\n", comment.ReplyingTo)
+	}
+	_, err = fmt.Fprintf(f, "\n%s\n", comment.Content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// A metadata value on a line of its own, as Pelican reads them.
+func pelicanValue(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+var workers = flag.Int("workers", runtime.NumCPU(), "how many posts and pages to convert and write at once")
+
+// A post or page to write, and what came of writing it.
+type postJob struct {
+	entry      Entry
+	written    bool
+	unresolved []string
+	external   []externalLink
+	// What -check-markdown found won't render as written.
+	markdown []string
+	err      error
+	// Closed once the job is written or has failed, so the next one's turn comes.
+	done chan struct{}
+}
+
+// Convert posts on -workers goroutines, writing them out in export order, so an
+// -output-archive lists them the same way every run. What each job found is kept with
+// it, so it's reported in export order too. Jobs not started by the time ctx is done
+// are left unwritten.
+func (c *Converter) writePosts(ctx context.Context, jobs []postJob) {
+	for i := range jobs {
+		jobs[i].done = make(chan struct{})
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				var prev chan struct{}
+				if i > 0 {
+					prev = jobs[i-1].done
+				}
+				c.writePost(ctx, &jobs[i], prev)
+			}
+		}()
+	}
+queue:
+	for i := range jobs {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(next)
+	wg.Wait()
+}
+
+// Rework a post's body per the flags and the transforms, and write it out once prev,
+// the job before it, is done.
+func (c *Converter) writePost(ctx context.Context, j *postJob, prev chan struct{}) {
+	defer func() {
+		// The next job waits on this one to have waited its turn too, written or not.
+		if prev != nil {
+			<-prev
+		}
+		close(j.done)
+	}()
+	entry := j.entry
+	fail := func(err error) {
+		j.err = &blogger.EntryError{ID: entry.ID, Err: fmt.Errorf("%s %q: %w", entry.Kind(), entry.Title, err)}
+	}
+	content, err := readContent(entry)
+	if err != nil {
+		fail(err)
+		return
+	}
+	entry.Content = explicitAnchors(content)
+	entry.spill = nil
+	// What was lost, for -migration-warnings.
+	var warnings []string
+	if *bundleImages {
+		var missed []string
+		entry.Content, missed = c.useBundledImages(entry)
+		warnings = append(warnings, missed...)
+	} else if len(c.exp.Media) > 0 {
+		var missed []string
+		entry.Content, missed = c.useBundledMedia(entry)
+		warnings = append(warnings, missed...)
+	}
+	if *internalLinks != "" || *markdownBodies {
+		entry.Content, j.unresolved = c.rewriteLinks(entry)
+		warnings = append(warnings, linkWarnings(entry.Title, j.unresolved)...)
+	}
+	if *linkReport != "" {
+		j.external = c.externalLinksIn(entry)
+	}
+	if *migrationWarnings != "" && *checkLinks {
+		warnings = append(warnings, c.deadLinkWarnings(entry)...)
+	}
+	if *archiveDeadLinks {
+		entry.Content = c.archiveLinks(entry)
+	}
+	if len(typography) > 0 {
+		entry.Content = normalizeTypography(entry.Content)
+	}
+	if *rtl != "" {
+		markRTL(&entry)
+	}
+	if err := c.transform(ctx, &entry); err != nil {
+		fail(err)
+		return
+	}
+	if *markdownBodies {
+		entry.Content = markdownBody(entry, nil)
+	}
+	if *checkMarkdown || (*migrationWarnings != "" && *target != "markdown") {
+		problems := markdownProblems(entry.Content)
+		if *checkMarkdown {
+			j.markdown = problems
+		}
+		warnings = append(warnings, problems...)
+	}
+	if *migrationWarnings != "" {
+		markWarnings(&entry, warnings)
+	}
+	if prev != nil {
+		<-prev
+	}
+	write := c.writeFile
+	if *contentAdapter != "" {
+		write = c.addAdapterPage
+	}
+	if err := write(entry.Path, entry); err != nil {
+		fail(err)
+		return
+	}
+	j.written = true
+}
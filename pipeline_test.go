@@ -0,0 +1,57 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+// With several workers, posts still go into an archive in export order, and the same
+// export gives the same archive byte for byte.
+func TestWritePostsOrder(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	defer func(n int) { *workers = n }(*workers)
+	*workers = 4
+	ctx := context.Background()
+	convert := func() (*Converter, []byte) {
+		c := newConverter()
+		var err error
+		if c.exp, err = loadExports(ctx, []string{"tests/data/comments-blogger-backup.xml"}); err != nil {
+			t.Fatal(err)
+		}
+		defer c.exp.Close()
+		var b bytes.Buffer
+		if err := c.convertToZip(ctx, &b); err != nil {
+			t.Fatal(err)
+		}
+		return c, b.Bytes()
+	}
+	c, first := convert()
+	if _, again := convert(); !bytes.Equal(first, again) {
+		t.Error("converting the same export twice gave different archives")
+	}
+
+	var want []string
+	written := map[string]bool{}
+	for _, e := range c.exp.Entries {
+		if kind := e.Kind(); (kind == "post" || kind == "page") && e.Path != "" {
+			want = append(want, e.Path)
+			written[e.Path] = true
+		}
+	}
+	z, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range z.File {
+		if written[f.Name] {
+			got = append(got, f.Name)
+		}
+	}
+	if len(want) < 3 || !reflect.DeepEqual(got, want) {
+		t.Errorf("posts were archived as %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+var downloadAudio = flag.Bool("download-audio", false, "download the audio attached to podcast posts into audio/ in the target directory, to move to the site's static directory, and point their enclosure at the copy")
+
+// The audio attached to a post, as podcast themes want it in the front matter.
+type enclosure struct {
+	URL    string
+	Length int64
+	Type   string
+}
+
+// File extensions of audio, for enclosures that don't give their type.
+var audioExtensions = map[string]string{
+	".mp3": "audio/mpeg", ".m4a": "audio/mp4", ".aac": "audio/aac", ".ogg": "audio/ogg",
+	".oga": "audio/ogg", ".opus": "audio/opus", ".wav": "audio/wav", ".flac": "audio/flac",
+}
+
+// The first audio enclosure of an entry.
+func audioEnclosure(e Entry) (enclosure, bool) {
+	for _, l := range e.Links {
+		if !strings.EqualFold(l.Rel, "enclosure") || l.Link == "" {
+			continue
+		}
+		typ := strings.ToLower(strings.TrimSpace(l.Type))
+		if typ == "" {
+			typ = audioExtensions[strings.ToLower(path.Ext(strings.SplitN(l.Link, "?", 2)[0]))]
+		}
+		if !strings.HasPrefix(typ, "audio/") {
+			continue
+		}
+		length, _ := strconv.ParseInt(strings.TrimSpace(l.Length), 10, 64)
+		return enclosure{URL: l.Link, Length: length, Type: typ}, true
+	}
+	return enclosure{}, false
+}
+
+// Give each post with audio attached its enclosure, downloading the audio under
+// -download-audio. Audio that can't be downloaded is linked where it is.
+func (c *Converter) findEnclosures(ctx context.Context) {
+	client := &http.Client{Timeout: 10 * time.Minute}
+	// Downloaded files by URL, and the URLs by file.
+	files, urls := map[string]string{}, map[string]string{}
+	failed := 0
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if e.Path == "" {
+			continue
+		}
+		enc, ok := audioEnclosure(*e)
+		if !ok {
+			continue
+		}
+		if *downloadAudio && ctx.Err() == nil {
+			name, ok := files[enc.URL]
+			if !ok {
+				name = audioFileName(enc.URL)
+				if other, taken := urls[name]; taken && other != enc.URL {
+					name = e.ID + "-" + name
+				}
+				n, err := c.downloadEnclosure(ctx, client, enc.URL, path.Join("audio", name))
+				if err != nil {
+					log.Printf("Failed downloading the audio of %q: %s", e.Title, err)
+					failed++
+					name = ""
+				} else if enc.Length == 0 {
+					enc.Length = n
+				}
+				files[enc.URL], urls[name] = name, enc.URL
+			}
+			if name != "" {
+				enc.URL = absURL("/audio/" + name)
+			}
+		}
+		e.Enclosure = &enc
+	}
+	if failed > 0 {
+		log.Printf("%d podcast episodes' audio could not be downloaded and is linked where it was.", failed)
+	}
+}
+
+// The file name to save the audio at u as.
+func audioFileName(u string) string {
+	name := "audio"
+	if parsed, err := url.Parse(u); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+		name = path.Base(parsed.Path)
+	}
+	name = hugo.SafeName(name)
+	if path.Ext(name) == "" {
+		name += ".mp3"
+	}
+	return name
+}
+
+// Save the audio at u as name, returning its size.
+func (c *Converter) downloadEnclosure(ctx context.Context, client *http.Client, u, name string) (int64, error) {
+	resp, err := request(ctx, client, "GET", u)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("downloading %s: %s", u, resp.Status)
+	}
+	f, err := c.create(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
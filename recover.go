@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+var recoverFlag = flag.Bool("recover", false, "convert what can be read of a truncated or malformed Blogger export, skipping broken entries, instead of stopping at the first error")
+var strict = flag.Bool("strict", false, "stop at anything in the export the converter doesn't know, such as a draft flag that's neither yes nor no, a date or ID it can't read, or a kind of entry it doesn't convert, instead of warning and going on with a default")
+
+// Decode an Atom feed entry by entry per -recover, for the Blogger-shaped formats
+// the blogger package doesn't decode itself.
+func decodeEntries(r io.Reader, title *string, fn func(dec *xml.Decoder, start xml.StartElement) error) error {
+	p := newParser()
+	return recoverHint(p.Decode(r, title, fn))
+}
+
+// Point out -recover when reading an export failed without it, or the limits when it
+// went over them.
+func recoverHint(err error) error {
+	if errors.Is(err, blogger.ErrLimit) {
+		return fmt.Errorf("%w (-max-entry-size and -max-depth raise the limits for an export you trust)", err)
+	}
+	if err == nil || *recoverFlag {
+		return err
+	}
+	var pe *blogger.ParseError
+	var ee *blogger.EntryError
+	switch {
+	case errors.As(err, &pe):
+		return fmt.Errorf("%w (-recover converts the entries before it)", err)
+	case errors.As(err, &ee):
+		return fmt.Errorf("%w (-recover skips it and converts the rest)", err)
+	}
+	return err
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var redirectsFormat = flag.String("redirects", "", "write redirects from the old Blogger URLs to the new permalinks: netlify, cloudflare, vercel, nginx or htaccess")
+var redirectsFile = flag.String("redirects-file", "", "where to write -redirects, relative to the target directory (defaults to the format's usual filename)")
+
+type redirect struct {
+	From string
+	To   string
+}
+
+// Default output file for each -redirects format.
+var redirectFiles = map[string]string{
+	"netlify":    "_redirects",
+	"cloudflare": "_redirects",
+	"vercel":     "vercel.json",
+	"nginx":      "blogger-redirects.conf",
+	"htaccess":   ".htaccess",
+}
+
+// Every old Blogger path with the permalink it now lives at.
+func (c *Converter) collectRedirects() []redirect {
+	var rs []redirect
+	for _, m := range c.mappings {
+		if m.Blogger == "" {
+			continue
+		}
+		from := bloggerPath(m.Blogger)
+		if from == "" || from == m.Permalink {
+			continue
+		}
+		rs = append(rs, redirect{from, m.Permalink})
+	}
+	rs = append(rs, c.listRedirects()...)
+	rs = append(rs, c.feedRedirects()...)
+	for i := range rs {
+		rs[i].To = absURL(rs[i].To)
+	}
+	return rs
+}
+
+// The feed of each target that makes a single one, by its usual feed plugin, and no
+// label or archive pages either.
+var siteFeeds = map[string]string{
+	"jekyll":   "/feed.xml",
+	"eleventy": "/feed/feed.xml",
+	"pelican":  "/feeds/all.atom.xml",
+	"hexo":     "/atom.xml",
+}
+
+// Redirects from Blogger's feeds to Hugo's RSS feeds, or the target's, so existing subscribers keep
+// getting posts: the main feeds go to the posts section's feed, and label feeds
+// to their tag's feed.
+func (c *Converter) feedRedirects() []redirect {
+	main, feed := path.Join(c.urlPrefix, "index.xml"), "index.xml"
+	if *target == "zola" {
+		main, feed = "/atom.xml", "atom.xml"
+	}
+	single, ok := siteFeeds[*target]
+	if ok {
+		main = single
+	}
+	var rs []redirect
+	for _, from := range []string{"/feeds/posts/default", "/feeds/posts/summary", "/atom.xml", "/rss.xml"} {
+		if from != main {
+			rs = append(rs, redirect{from, main})
+		}
+	}
+	if ok {
+		return rs
+	}
+	labels := map[string]bool{}
+	for _, e := range c.exp.Entries {
+		if e.Kind() != "post" {
+			continue
+		}
+		for _, t := range e.Tags {
+			if t.Scheme == "http://www.blogger.com/atom/ns#" && !labels[t.Name] {
+				labels[t.Name] = true
+				rs = append(rs, redirect{"/feeds/posts/default/-/" + url.PathEscape(t.Name), "/" + path.Join(c.tagsKey, urlize(t.Name), feed)})
+			}
+		}
+	}
+	return rs
+}
+
+// Redirects from Blogger's label searches and date archives to the matching Hugo
+// taxonomy and section list pages.
+func (c *Converter) listRedirects() []redirect {
+	if _, ok := siteFeeds[*target]; ok {
+		return nil
+	}
+	var rs []redirect
+	labels := map[string]bool{}
+	months := map[string]bool{}
+	for _, e := range c.exp.Entries {
+		if e.Kind() != "post" || e.Draft {
+			continue
+		}
+		for _, t := range e.Tags {
+			if t.Scheme == "http://www.blogger.com/atom/ns#" && !labels[t.Name] {
+				labels[t.Name] = true
+				rs = append(rs, redirect{"/search/label/" + url.PathEscape(t.Name), "/" + path.Join(c.tagsKey, urlize(t.Name)) + "/"})
+			}
+		}
+		d := time.Time(e.Published)
+		month := d.Format("2006/01")
+		if months[month] {
+			continue
+		}
+		months[month] = true
+		to := c.archivePage(e.Published, "month")
+		rs = append(rs,
+			redirect{d.Format("/2006_01") + "_01_archive.html", to},
+			redirect{"/" + month + "/", to},
+		)
+		if year := d.Format("2006"); !months[year] {
+			months[year] = true
+			rs = append(rs, redirect{"/" + year + "/", c.archivePage(e.Published, "year")})
+		}
+	}
+	return rs
+}
+
+// The closest Hugo list page to a Blogger date archive: the year or month section
+// under -sections, or the posts section itself.
+func (c *Converter) archivePage(d Date, period string) string {
+	sub := ""
+	switch {
+	case c.sections == "month" && period == "month":
+		sub = c.sectionDir(d)
+	case c.sections != "":
+		sub = strconv.Itoa(time.Time(d).Year())
+	}
+	p := path.Join(c.urlPrefix, sub)
+	if p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// Make a term into a URL path segment the way Hugo does for taxonomy pages.
+// E.g. O. Henry -> o.-henry
+func urlize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r), strings.ContainsRune("._-#+~", r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// The path of a Blogger URL with the variants it is shared under folded together:
+// mobile (?m=1) and comment (?showComment=...#c...) links drop their query and fragment,
+// and country domains don't matter. E.g. https://foo.blogspot.co.uk/2014/06/bar.html?m=1 -> /2014/06/bar.html
+func bloggerPath(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return u.EscapedPath()
+}
+
+func (c *Converter) writeRedirects() error {
+	name := c.redirectsFile
+	if name == "" {
+		name = redirectFiles[c.redirectsFormat]
+	}
+	f, err := c.create(path.Clean(filepath.ToSlash(name)))
+	if err != nil {
+		return err
+	}
+	rs := c.collectRedirects()
+	switch c.redirectsFormat {
+	case "netlify":
+		err = writeNetlifyRedirects(f, rs)
+	case "cloudflare":
+		if len(rs) > cloudflareLimit {
+			log.Printf("Warning: %d redirects is more than the %d Cloudflare Pages allows in _redirects.", len(rs), cloudflareLimit)
+		}
+		// Cloudflare Pages reads the same _redirects syntax as Netlify.
+		err = writeNetlifyRedirects(f, rs)
+	case "vercel":
+		err = writeVercelRedirects(f, rs)
+	case "nginx":
+		err = writeNginxRedirects(f, rs)
+	case "htaccess":
+		err = writeHtaccessRedirects(f, rs)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func writeNetlifyRedirects(w io.Writer, rs []redirect) error {
+	for _, r := range rs {
+		if _, err := fmt.Fprintf(w, "%s  %s  301\n", r.From, r.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Static redirects Cloudflare Pages accepts in one _redirects file.
+const cloudflareLimit = 2000
+
+// A vercel.json holding just the redirects; merge it into an existing one by hand.
+func writeVercelRedirects(w io.Writer, rs []redirect) error {
+	type vercelRedirect struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		Permanent   bool   `json:"permanent"`
+	}
+	config := struct {
+		Redirects []vercelRedirect `json:"redirects"`
+	}{[]vercelRedirect{}}
+	for _, r := range rs {
+		config.Redirects = append(config.Redirects, vercelRedirect{vercelSource.Replace(r.From), r.To, true})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config)
+}
+
+// Vercel reads a redirect's source as a path-to-regexp pattern, where these mark
+// parameters, groups and modifiers, so a literal one is escaped.
+var vercelSource = strings.NewReplacer(`(`, `\(`, `)`, `\)`, `:`, `\:`, `*`, `\*`, `?`, `\?`, `+`, `\+`)
+
+// Rewrite rules to include in an nginx server block. The trailing ? drops the
+// query string, so mobile links (?m=1) land on the plain permalink.
+func writeNginxRedirects(w io.Writer, rs []redirect) error {
+	fmt.Fprintln(w, "# Blogger redirects, include this inside your server { } block.")
+	for _, r := range rs {
+		from := "^" + regexp.QuoteMeta(decodedPath(r.From)) + "$"
+		if _, err := fmt.Fprintf(w, "rewrite %s %s permanent;\n", nginxQuote(from), nginxQuote(r.To+"?")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mod_rewrite rules for an Apache .htaccess at the site root. QSD discards the
+// query string, so mobile links (?m=1) land on the plain permalink.
+func writeHtaccessRedirects(w io.Writer, rs []redirect) error {
+	fmt.Fprintln(w, "RewriteEngine On")
+	for _, r := range rs {
+		from := "^" + regexp.QuoteMeta(strings.TrimPrefix(decodedPath(r.From), "/")) + "$"
+		if _, err := fmt.Fprintf(w, "RewriteRule %s %s [R=301,L,QSD]\n", apacheQuote(from), apacheQuote(r.To)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A redirect's path as nginx and Apache match it: percent-decoded. E.g.
+// /2014/05/caf%C3%A9.html -> /2014/05/café.html
+func decodedPath(p string) string {
+	if d, err := url.PathUnescape(p); err == nil {
+		return d
+	}
+	return p
+}
+
+// s in double quotes for nginx, which takes a backslash in a quoted string as an escape.
+func nginxQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// s in double quotes for Apache, where a backslash only escapes the quote.
+func apacheQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
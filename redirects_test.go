@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Redirects as collectRedirects finds them, for the writers.
+var testRedirects = []redirect{
+	{"/2014/05/the-gift-of-magi.html", "/posts/2014-05-19-the-gift-of-magi/"},
+	{"/p/about.html", "/posts/about/"},
+}
+
+func TestCollectRedirects(t *testing.T) {
+	c := newConverter()
+	c.urlPrefix = "/posts"
+	c.mappings = []urlMapping{
+		{Kind: "post", Blogger: "https://example.blogspot.com/2014/05/the-gift-of-magi.html", Permalink: "/posts/2014-05-19-the-gift-of-magi/"},
+		// Served where it was, so there's nothing to redirect.
+		{Kind: "post", Blogger: "https://example.blogspot.com/2014/06/same.html", Permalink: "/2014/06/same.html"},
+		// A mobile link, shared with ?m=1.
+		{Kind: "post", Blogger: "https://example.blogspot.co.uk/2014/06/mobile.html?m=1", Permalink: "/posts/2014-06-02-mobile/"},
+		// Never published on Blogger.
+		{Kind: "post", Permalink: "/posts/2014-07-01-draft/"},
+	}
+	want := []redirect{
+		{"/2014/05/the-gift-of-magi.html", "/posts/2014-05-19-the-gift-of-magi/"},
+		{"/2014/06/mobile.html", "/posts/2014-06-02-mobile/"},
+		{"/feeds/posts/default", "/posts/index.xml"},
+		{"/feeds/posts/summary", "/posts/index.xml"},
+		{"/atom.xml", "/posts/index.xml"},
+		{"/rss.xml", "/posts/index.xml"},
+	}
+	if got := c.collectRedirects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("collectRedirects() = %v, want %v", got, want)
+	}
+}
+
+func TestWriteNetlifyRedirects(t *testing.T) {
+	var b strings.Builder
+	if err := writeNetlifyRedirects(&b, testRedirects); err != nil {
+		t.Fatal(err)
+	}
+	want := "/2014/05/the-gift-of-magi.html  /posts/2014-05-19-the-gift-of-magi/  301\n" +
+		"/p/about.html  /posts/about/  301\n"
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteNginxRedirects(t *testing.T) {
+	var b strings.Builder
+	if err := writeNginxRedirects(&b, testRedirects); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Blogger redirects, include this inside your server { } block.\n" +
+		"rewrite \"^/2014/05/the-gift-of-magi\\\\.html$\" \"/posts/2014-05-19-the-gift-of-magi/?\" permanent;\n" +
+		"rewrite \"^/p/about\\\\.html$\" \"/posts/about/?\" permanent;\n"
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteHtaccessRedirects(t *testing.T) {
+	var b strings.Builder
+	if err := writeHtaccessRedirects(&b, testRedirects); err != nil {
+		t.Fatal(err)
+	}
+	want := "RewriteEngine On\n" +
+		"RewriteRule \"^2014/05/the-gift-of-magi\\.html$\" \"/posts/2014-05-19-the-gift-of-magi/\" [R=301,L,QSD]\n" +
+		"RewriteRule \"^p/about\\.html$\" \"/posts/about/\" [R=301,L,QSD]\n"
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// Old paths that need escaping, as collectRedirects has them, percent-encoded, and as
+// servers match them, decoded.
+var escapedRedirects = []struct {
+	redirect
+	decoded string
+}{
+	{redirect{"/2014/05/caf%C3%A9%20time.html", "/posts/cafe-time/"}, "/2014/05/café time.html"},
+	{redirect{"/2014/05/say-%22hi%22.html", "/posts/say-hi/"}, `/2014/05/say-"hi".html`},
+	{redirect{"/2014/05/a+b(1)[2].html", "/posts/a-b/"}, "/2014/05/a+b(1)[2].html"},
+	{redirect{"/2014/05/back%5Cslash.html", "/posts/backslash/"}, `/2014/05/back\slash.html`},
+}
+
+// The double-quoted arguments of a config line, unescaped as the server would: nginx
+// takes \ and \" as escapes, Apache only \".
+func quotedArgs(t *testing.T, line string, nginx bool) []string {
+	t.Helper()
+	var args []string
+	for rest := line; ; {
+		i := strings.IndexByte(rest, '"')
+		if i < 0 {
+			return args
+		}
+		rest = rest[i+1:]
+		var arg strings.Builder
+		for {
+			if rest == "" {
+				t.Fatalf("unterminated quote in %q", line)
+			}
+			c := rest[0]
+			if c == '\\' && len(rest) > 1 && (rest[1] == '"' || nginx && rest[1] == '\\') {
+				arg.WriteByte(rest[1])
+				rest = rest[2:]
+				continue
+			}
+			rest = rest[1:]
+			if c == '"' {
+				break
+			}
+			arg.WriteByte(c)
+		}
+		args = append(args, arg.String())
+	}
+}
+
+// Read the rules back as nginx and Apache would, and check each matches its old path.
+func TestRedirectRulesReadBack(t *testing.T) {
+	var rs []redirect
+	for _, r := range escapedRedirects {
+		rs = append(rs, r.redirect)
+	}
+	for _, test := range []struct {
+		name   string
+		write  func(io.Writer, []redirect) error
+		prefix string
+		nginx  bool
+	}{
+		{"nginx", writeNginxRedirects, "rewrite ", true},
+		{"htaccess", writeHtaccessRedirects, "RewriteRule ", false},
+	} {
+		var b strings.Builder
+		if err := test.write(&b, rs); err != nil {
+			t.Fatal(err)
+		}
+		var rules []string
+		for _, line := range strings.Split(b.String(), "\n") {
+			if strings.HasPrefix(line, test.prefix) {
+				rules = append(rules, line)
+			}
+		}
+		if len(rules) != len(rs) {
+			t.Fatalf("%s: got %d rules, want %d:\n%s", test.name, len(rules), len(rs), b.String())
+		}
+		for i, line := range rules {
+			args := quotedArgs(t, line, test.nginx)
+			if len(args) != 2 {
+				t.Errorf("%s: %q has %d quoted arguments, want 2", test.name, line, len(args))
+				continue
+			}
+			re, err := regexp.Compile(args[0])
+			if err != nil {
+				t.Errorf("%s: %q: %s", test.name, line, err)
+				continue
+			}
+			want := escapedRedirects[i]
+			path := want.decoded
+			if !test.nginx {
+				// A .htaccess at the root matches without the leading slash.
+				path = strings.TrimPrefix(path, "/")
+			}
+			if !re.MatchString(path) {
+				t.Errorf("%s: %q doesn't match %q", test.name, line, want.decoded)
+			}
+			if re.MatchString(path + "x") {
+				t.Errorf("%s: %q matches more than %q", test.name, line, want.decoded)
+			}
+			if to := strings.TrimSuffix(args[1], "?"); to != want.To {
+				t.Errorf("%s: %q redirects to %q, want %q", test.name, line, to, want.To)
+			}
+		}
+	}
+}
+
+func TestWriteVercelRedirects(t *testing.T) {
+	var b strings.Builder
+	if err := writeVercelRedirects(&b, testRedirects); err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Redirects []struct {
+			Source, Destination string
+			Permanent           bool
+		}
+	}
+	if err := json.Unmarshal([]byte(b.String()), &config); err != nil {
+		t.Fatalf("vercel.json doesn't parse: %s", err)
+	}
+	if len(config.Redirects) != len(testRedirects) {
+		t.Fatalf("got %d redirects, want %d", len(config.Redirects), len(testRedirects))
+	}
+	for i, r := range config.Redirects {
+		if r.Source != testRedirects[i].From || r.Destination != testRedirects[i].To || !r.Permanent {
+			t.Errorf("redirect %d = %+v, want a permanent one from %s to %s", i, r, testRedirects[i].From, testRedirects[i].To)
+		}
+	}
+}
+
+// Characters path-to-regexp gives a meaning are escaped in a Vercel source.
+func TestVercelSource(t *testing.T) {
+	tests := []struct {
+		from, want string
+	}{
+		{"/2014/05/plain.html", "/2014/05/plain.html"},
+		{"/2014/05/a+b(1).html", `/2014/05/a\+b\(1\).html`},
+		{"/2014/05/note:*todo*.html", `/2014/05/note\:\*todo\*.html`},
+		{"/2014/05/why?.html", `/2014/05/why\?.html`},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeVercelRedirects(&b, []redirect{{tt.from, "/posts/x/"}}); err != nil {
+			t.Fatal(err)
+		}
+		var config struct {
+			Redirects []struct{ Source string }
+		}
+		if err := json.Unmarshal([]byte(b.String()), &config); err != nil {
+			t.Fatalf("vercel.json doesn't parse: %s", err)
+		}
+		if len(config.Redirects) != 1 || config.Redirects[0].Source != tt.want {
+			t.Errorf("%s: got %+v, want source %s", tt.from, config.Redirects, tt.want)
+		}
+	}
+}
+
+func TestBloggerPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.blogspot.com/2014/06/bar.html", "/2014/06/bar.html"},
+		{"https://example.blogspot.co.uk/2014/06/bar.html?m=1", "/2014/06/bar.html"},
+		{"https://example.blogspot.com/2014/06/bar.html?showComment=1402#c123", "/2014/06/bar.html"},
+		{" http://example.blogspot.com/p/about.html ", "/p/about.html"},
+		{"https://example.blogspot.com/search/label/O.%20Henry", "/search/label/O.%20Henry"},
+		{"://bad", ""},
+	}
+	for _, tt := range tests {
+		if got := bloggerPath(tt.in); got != tt.want {
+			t.Errorf("bloggerPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// A published post with the given labels.
+func testPost(published time.Time, labels ...string) Entry {
+	var e Entry
+	e.Published = Date(published)
+	e.Tags = Tags{{Scheme: "http://schemas.google.com/g/2005#kind", Name: kindPrefix + "post"}}
+	for _, l := range labels {
+		e.Tags = append(e.Tags, Tag{Scheme: "http://www.blogger.com/atom/ns#", Name: l})
+	}
+	return e
+}
+
+func TestListRedirects(t *testing.T) {
+	c := newConverter()
+	c.urlPrefix = "/posts"
+	draft := testPost(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), "Drafts")
+	draft.Draft = true
+	c.exp.Entries = []Entry{
+		testPost(time.Date(2014, 5, 19, 0, 0, 0, 0, time.UTC), "O. Henry", "Story"),
+		testPost(time.Date(2014, 5, 20, 0, 0, 0, 0, time.UTC), "O. Henry"),
+		testPost(time.Date(2014, 8, 27, 0, 0, 0, 0, time.UTC), "Ruskin Bond"),
+		draft,
+	}
+	tests := []struct {
+		sections string
+		want     []redirect
+	}{
+		{"", []redirect{
+			{"/search/label/O.%20Henry", "/tags/o.-henry/"},
+			{"/search/label/Story", "/tags/story/"},
+			{"/2014_05_01_archive.html", "/posts/"},
+			{"/2014/05/", "/posts/"},
+			{"/2014/", "/posts/"},
+			{"/search/label/Ruskin%20Bond", "/tags/ruskin-bond/"},
+			{"/2014_08_01_archive.html", "/posts/"},
+			{"/2014/08/", "/posts/"},
+		}},
+		{"month", []redirect{
+			{"/search/label/O.%20Henry", "/tags/o.-henry/"},
+			{"/search/label/Story", "/tags/story/"},
+			{"/2014_05_01_archive.html", "/posts/2014/05/"},
+			{"/2014/05/", "/posts/2014/05/"},
+			{"/2014/", "/posts/2014/"},
+			{"/search/label/Ruskin%20Bond", "/tags/ruskin-bond/"},
+			{"/2014_08_01_archive.html", "/posts/2014/08/"},
+			{"/2014/08/", "/posts/2014/08/"},
+		}},
+	}
+	for _, tt := range tests {
+		c.sections = tt.sections
+		if got := c.listRedirects(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("listRedirects() with sections %q = %v, want %v", tt.sections, got, tt.want)
+		}
+	}
+}
+
+func TestURLize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"O. Henry", "o.-henry"},
+		{" Go ", "go"},
+		{"C++ & C#", "c++--c#"},
+		{"Café", "café"},
+	}
+	for _, tt := range tests {
+		if got := urlize(tt.in); got != tt.want {
+			t.Errorf("urlize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFeedRedirects(t *testing.T) {
+	c := newConverter()
+	c.urlPrefix = "/posts"
+	draft := testPost(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), "Drafts")
+	draft.Draft = true
+	c.exp.Entries = []Entry{
+		testPost(time.Date(2014, 5, 19, 0, 0, 0, 0, time.UTC), "O. Henry", "Story"),
+		testPost(time.Date(2014, 5, 20, 0, 0, 0, 0, time.UTC), "O. Henry"),
+		draft,
+	}
+	want := []redirect{
+		{"/feeds/posts/default", "/posts/index.xml"},
+		{"/feeds/posts/summary", "/posts/index.xml"},
+		{"/atom.xml", "/posts/index.xml"},
+		{"/rss.xml", "/posts/index.xml"},
+		{"/feeds/posts/default/-/O.%20Henry", "/tags/o.-henry/index.xml"},
+		{"/feeds/posts/default/-/Story", "/tags/story/index.xml"},
+		// Drafts' labels too, as their feeds may have subscribers.
+		{"/feeds/posts/default/-/Drafts", "/tags/drafts/index.xml"},
+	}
+	if got := c.feedRedirects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("feedRedirects() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var referenceDir = flag.String("blogger-reference", "", "save the old Blogger theme and the blog's settings into this directory, relative to the target directory (e.g. ../../_blogger), for reference when rebuilding the design")
+
+// Write the export's template entries as they are, named after their ID
+// (e.g. blog-42.layout -> layout.xml), and its settings as one settings.json.
+// Returns how many files were written.
+func (c *Converter) writeReference() (int, error) {
+	dir := path.Clean(filepath.ToSlash(*referenceDir))
+	settings := map[string]string{}
+	n := 0
+	for _, e := range c.exp.Entries {
+		switch e.Kind() {
+		case "template":
+			name := e.ID[strings.LastIndex(e.ID, ".")+1:]
+			if err := c.writeReferenceFile(path.Join(dir, name+".xml"), []byte(e.Content)); err != nil {
+				return n, err
+			}
+			n++
+		case "settings":
+			if i := strings.LastIndex(e.ID, ".settings."); i >= 0 {
+				settings[e.ID[i+len(".settings."):]] = e.Content
+			}
+		}
+	}
+	if len(settings) == 0 {
+		return n, nil
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(settings); err != nil {
+		return n, err
+	}
+	if err := c.writeReferenceFile(path.Join(dir, "settings.json"), b.Bytes()); err != nil {
+		return n, err
+	}
+	return n + 1, nil
+}
+
+func (c *Converter) writeReferenceFile(name string, b []byte) error {
+	f, err := c.create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Point each post's and page's related links, the cross-references some blogs curate
+// by hand, at the new permalinks of the posts they name, for the related front matter.
+// Comments' related links name the comment replied to instead, and are left alone.
+func (c *Converter) resolveRelated() {
+	for k := range c.exp.Entries {
+		e := &c.exp.Entries[k]
+		if kind := e.Kind(); kind != "post" && kind != "page" || e.Path == "" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, l := range e.Links {
+			if !strings.EqualFold(l.Rel, "related") {
+				continue
+			}
+			u, err := url.Parse(strings.TrimSpace(l.Link))
+			if err != nil || !c.blogHosts[normalizeHost(u.Host)] {
+				continue
+			}
+			target, ok := c.linkTargets[linkKey(u)]
+			if !ok {
+				c.unresolvedLinks = append(c.unresolvedLinks, fmt.Sprintf("%s has a related link to %s", e.Title, l.Link))
+				continue
+			}
+			if target.ID == e.ID {
+				continue
+			}
+			if p := absURL(c.permalink(target, target.Path)); !seen[p] {
+				seen[p] = true
+				e.Related = append(e.Related, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var rtl = flag.String("rtl", "", "for posts written right to left, in Arabic, Hebrew, Persian or Urdu, so they don't come out left-aligned: param to write direction = \"rtl\" into their front matter, for themes that read it, or wrap to wrap their body in <div dir=\"rtl\">")
+
+// Scripts written right to left.
+var rtlScripts = []*unicode.RangeTable{unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko}
+
+// Languages written right to left, as Hugo's languageDirection wants to be told.
+var rtlLanguages = map[string]bool{"ar": true, "he": true, "fa": true, "ur": true, "ps": true, "sd": true, "yi": true, "dv": true}
+
+// An element at the start of a body that sets it right to left, as Blogger's editor
+// wraps posts written in its RTL mode.
+var rtlWrapper = regexp.MustCompile(`(?is)^\s*<(?:div|p|span|section|article)\b[^>]*\bdir\s*=\s*["']?rtl\b`)
+
+// Whether a post or page is written right to left: its body is wrapped in an element
+// saying so, it's in a right-to-left language under -multilingual, or most of its
+// letters are in a right-to-left script.
+func isRTL(e Entry) bool {
+	if rtlWrapper.MatchString(e.Content) || rtlLanguages[e.Lang] {
+		return true
+	}
+	rtlLetters, letters := 0, 0
+	for _, r := range e.Title + " " + html.UnescapeString(tagPattern.ReplaceAllString(e.Content, " ")) {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, rtlScripts...) {
+			rtlLetters++
+		}
+	}
+	return rtlLetters > letters/2
+}
+
+// Mark a right-to-left post or page as such per -rtl.
+func markRTL(e *Entry) {
+	if !isRTL(*e) {
+		return
+	}
+	switch *rtl {
+	case "param":
+		if _, ok := e.Params["direction"]; ok {
+			return
+		}
+		params := make(map[string]string, len(e.Params)+1)
+		for k, v := range e.Params {
+			params[k] = v
+		}
+		params["direction"] = "rtl"
+		e.Params = params
+	case "wrap":
+		if !rtlWrapper.MatchString(e.Content) {
+			e.Content = "<div dir=\"rtl\">\n" + strings.TrimSpace(e.Content) + "\n</div>"
+		}
+	}
+}
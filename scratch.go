@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// Somewhere to put what's kept out of memory for the length of a conversion: post
+// bodies under -low-memory, archived files past -memory-budget, a zip export read
+// from a stream.
+type scratchFile interface {
+	io.ReadWriteSeeker
+	io.ReaderAt
+	io.WriterAt
+	io.Closer
+}
+
+// Scratch files are temporary files, unlinked straight away as they're only used
+// through the open file. Where there's no file system, e.g. in a browser, they're
+// kept in memory instead.
+var newScratch = func() (scratchFile, error) {
+	f, err := os.CreateTemp("", "blogger2hugo-*")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	return f, nil
+}
+
+// A scratch file in memory.
+type memScratch struct {
+	b   []byte
+	off int64
+}
+
+func newMemScratch() (scratchFile, error) {
+	return &memScratch{}, nil
+}
+
+func (m *memScratch) Read(p []byte) (int, error) {
+	n, err := m.ReadAt(p, m.off)
+	m.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (m *memScratch) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(len(m.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memScratch) Write(p []byte) (int, error) {
+	n, err := m.WriteAt(p, m.off)
+	m.off += int64(n)
+	return n, err
+}
+
+func (m *memScratch) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if end := off + int64(len(p)); end > int64(len(m.b)) {
+		if end > int64(cap(m.b)) {
+			b := make([]byte, end, 2*end)
+			copy(b, m.b)
+			m.b = b
+		}
+		m.b = m.b[:end]
+	}
+	return copy(m.b[off:], p), nil
+}
+
+func (m *memScratch) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += m.off
+	case io.SeekEnd:
+		offset += int64(len(m.b))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	m.off = offset
+	return offset, nil
+}
+
+func (m *memScratch) Close() error {
+	m.b = nil
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+var scriptFile = flag.String("script", "", "a Starlark script whose transform(post) func is called on each post and page before it's placed, to change its title, slug, tags, front matter or body")
+
+// A -script, loaded for a conversion. Its transform func is called with a post as a
+// dict, and what it leaves in the dict is what's written.
+type script struct {
+	thread    *starlark.Thread
+	transform starlark.Callable
+}
+
+func loadScript(name string) (*script, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { log.Print(msg) },
+	}
+	globals, err := starlark.ExecFile(thread, name, src, starlark.StringDict{"re": reModule})
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := globals["transform"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't define transform(post)", name)
+	}
+	return &script{thread, fn}, nil
+}
+
+// Call the script's transform on a post or page, and take back what it changed: title,
+// slug, tags, draft, author, params (further front matter) and content. The other keys,
+// kind, id, url, published and updated, say which post it is.
+func (s *script) run(ctx context.Context, e *Entry) error {
+	content, err := readContent(*e)
+	if err != nil {
+		return err
+	}
+	tags := starlark.NewList(nil)
+	for _, l := range e.Tags.Labels() {
+		tags.Append(starlark.String(l))
+	}
+	// In key order, as a script sees a dict's keys in the order they went in.
+	keys := make([]string, 0, len(e.Params))
+	for k := range e.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := starlark.NewDict(len(keys))
+	for _, k := range keys {
+		params.SetKey(starlark.String(k), starlark.String(e.Params[k]))
+	}
+	post := starlark.NewDict(12)
+	for _, kv := range []struct {
+		k string
+		v starlark.Value
+	}{
+		{"kind", starlark.String(e.Kind())},
+		{"id", starlark.String(e.ID)},
+		{"url", starlark.String(e.URL)},
+		{"published", starlark.String(e.Published.String())},
+		{"updated", starlark.String(e.Updated.String())},
+		{"title", starlark.String(e.Title)},
+		{"slug", starlark.String(e.Slug)},
+		{"tags", tags},
+		{"draft", starlark.Bool(e.Draft)},
+		{"author", starlark.String(e.Author.Name)},
+		{"params", params},
+		{"content", starlark.String(content)},
+	} {
+		post.SetKey(starlark.String(kv.k), kv.v)
+	}
+
+	stop := context.AfterFunc(ctx, func() { s.thread.Cancel(ctx.Err().Error()) })
+	_, err = starlark.Call(s.thread, s.transform, starlark.Tuple{post}, nil)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	str := func(key string) (string, error) {
+		v, _, _ := post.Get(starlark.String(key))
+		s, ok := starlark.AsString(v)
+		if !ok {
+			return "", fmt.Errorf("post[%q] must be a string, not %s", key, typeName(v))
+		}
+		return s, nil
+	}
+	if e.Title, err = str("title"); err != nil {
+		return err
+	}
+	slug, err := str("slug")
+	if err != nil {
+		return err
+	}
+	if slug != e.Slug {
+		e.Slug, e.slugSet = slug, true
+	}
+	if e.Author.Name, err = str("author"); err != nil {
+		return err
+	}
+	changed, err := str("content")
+	if err != nil {
+		return err
+	}
+	if changed != content {
+		setContent(e, changed)
+	}
+	v, _, _ := post.Get(starlark.String("draft"))
+	draft, ok := v.(starlark.Bool)
+	if !ok {
+		return fmt.Errorf("post[\"draft\"] must be a bool, not %s", typeName(v))
+	}
+	e.Draft = blogger.Draft(draft)
+
+	v, _, _ = post.Get(starlark.String("tags"))
+	list, ok := v.(starlark.Iterable)
+	if !ok {
+		return fmt.Errorf("post[\"tags\"] must be a list, not %s", typeName(v))
+	}
+	var labels []string
+	iter := list.Iterate()
+	defer iter.Done()
+	var tag starlark.Value
+	for iter.Next(&tag) {
+		l, ok := starlark.AsString(tag)
+		if !ok {
+			return fmt.Errorf("post[\"tags\"] must hold strings, not %s", typeName(tag))
+		}
+		labels = append(labels, l)
+	}
+	e.Tags = e.Tags.WithLabels(labels)
+
+	v, _, _ = post.Get(starlark.String("params"))
+	dict, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("post[\"params\"] must be a dict, not %s", typeName(v))
+	}
+	e.Params = nil
+	for _, item := range dict.Items() {
+		k, kok := starlark.AsString(item[0])
+		v, vok := starlark.AsString(item[1])
+		if !kok || !vok {
+			return fmt.Errorf("post[\"params\"] must map strings to strings, not %s to %s", typeName(item[0]), typeName(item[1]))
+		}
+		if e.Params == nil {
+			e.Params = map[string]string{}
+		}
+		e.Params[k] = v
+	}
+	return nil
+}
+
+func typeName(v starlark.Value) string {
+	if v == nil {
+		return "nothing"
+	}
+	return v.Type()
+}
+
+// Regular expressions for scripts, in Go's syntax: re.sub(pattern, repl, string) replaces
+// every match, with $1 for a group in repl, and re.search(pattern, string) gives the first
+// match and its groups, or None.
+var reModule = &starlarkstruct.Module{
+	Name: "re",
+	Members: starlark.StringDict{
+		"sub":    starlark.NewBuiltin("re.sub", reSub),
+		"search": starlark.NewBuiltin("re.search", reSearch),
+	},
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	re, ok := patterns[pattern]
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+		patterns[pattern] = re
+	}
+	return re, nil
+}
+
+func reSub(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, repl, s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "repl", &repl, "string", &s); err != nil {
+		return nil, err
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(re.ReplaceAllString(s, repl)), nil
+}
+
+func reSearch(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "string", &s); err != nil {
+		return nil, err
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return starlark.None, nil
+	}
+	groups := make(starlark.Tuple, len(m))
+	for i, g := range m {
+		groups[i] = starlark.String(g)
+	}
+	return groups, nil
+}
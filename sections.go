@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var sections = flag.String("sections", "", "file posts into year or month directories, each with an _index.md list page")
+var splitByAuthor = flag.Bool("split-by-author", false, "file each author's posts into their own directory")
+
+var indexTempl = template.Must(template.New("").Parse(`---
+title: "{{ .Title }}"
+date: {{ .Date }}
+---
+`))
+
+// The directory a post belongs in, relative to the target directory.
+func (c *Converter) postDir(e Entry) string {
+	sub := c.sectionDir(e.Published)
+	if *splitByAuthor {
+		sub = path.Join(c.authorKey(e.Author), sub)
+	}
+	return sub
+}
+
+// E.g. Joe D'souza -> joe-dsouza
+func (c *Converter) authorKey(a Author) string {
+	key := c.makeSlug(a.Name)
+	if strings.Trim(key, "-._") == "" {
+		key = "unknown"
+	}
+	c.authorNames[key] = a.Name
+	return key
+}
+
+// The date-based directory a post belongs in, e.g. 2014/05 for month sections.
+func (c *Converter) sectionDir(d Date) string {
+	t := time.Time(d)
+	switch c.sections {
+	case "year":
+		return strconv.Itoa(t.Year())
+	case "month":
+		return path.Join(strconv.Itoa(t.Year()), t.Format("01"))
+	}
+	return ""
+}
+
+// Record a post under its section directory and every parent up to the target directory.
+func (c *Converter) addToSections(sub string, d Date) {
+	for ; ; sub = path.Dir(sub) {
+		if prev, ok := c.sectionDates[sub]; !ok || time.Time(d).After(time.Time(prev)) {
+			c.sectionDates[sub] = d
+		}
+		if sub == "." {
+			return
+		}
+	}
+}
+
+// Write an _index.md for every section directory that received posts.
+func (c *Converter) writeSections() error {
+	subs := make([]string, 0, len(c.sectionDates))
+	for sub := range c.sectionDates {
+		subs = append(subs, sub)
+	}
+	sort.Strings(subs)
+	for _, sub := range subs {
+		f, err := c.create(c.mainLanguageDir(path.Join(sub, "_index.md")))
+		if err != nil {
+			return err
+		}
+		err = indexTempl.Execute(f, struct {
+			Title string
+			Date  Date
+		}{c.sectionTitle(sub), c.sectionDates[sub]})
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// E.g. . -> the blog's title, 2014 -> 2014, 2014/05 -> May 2014
+// joe -> Joe, joe/2014 -> 2014
+func (c *Converter) sectionTitle(sub string) string {
+	if sub == "." {
+		return c.exp.Title
+	}
+	parent, last := path.Split(sub)
+	if t, err := time.Parse("2006/01", path.Base(parent)+"/"+last); err == nil {
+		return t.Format("January 2006")
+	}
+	if name, ok := c.authorNames[sub]; ok {
+		return name
+	}
+	return last
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+)
+
+var maxUpload = flag.Int("max-upload", 512, "with serve, the largest export accepted, in MB")
+var serveDebug = flag.Bool("serve-debug", false, "with serve, also serve /debug/vars, for the time conversions have taken by stage, and /debug/pprof/")
+
+const uploadPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Blogger to Hugo</title></head>
+<body>
+<h1>Blogger to Hugo</h1>
+<form method="post" action="convert" enctype="multipart/form-data">
+<p>Your blog's export, from Blogger's Settings, Back up content, or a Google Takeout zip:</p>
+<p><input type="file" name="export" required></p>
+<p><button>Convert</button></p>
+</form>
+<p>You'll get a zip of the posts and pages to put in your Hugo site's content directory.</p>
+</body>
+</html>
+`
+
+// Offer the conversion over HTTP until ctx is done. An export POSTed to /convert, as the
+// request body or the export field of a form, comes back as a zip of the converted content.
+// Each request is a conversion of its own, run with the flags the server was started with.
+func serve(ctx context.Context, addr, extra string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, uploadPage)
+	})
+	mux.HandleFunc("/convert", func(w http.ResponseWriter, r *http.Request) {
+		convertUpload(w, r, extra)
+	})
+	if *serveDebug {
+		mux.Handle("/debug/vars", expvar.Handler())
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+		// A slow client can't hold a connection open; the body read allows for a
+		// -max-upload export on a slow link.
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Minute,
+		// Conversions in progress are cancelled along with the server.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	log.Printf("Serving the converter on http://%s/", addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+func convertUpload(w http.ResponseWriter, r *http.Request, extra string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST an export to convert it", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(*maxUpload)<<20)
+	var in io.Reader = r.Body
+	name := "upload"
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, hdr, err := r.FormFile("export")
+		if err != nil {
+			uploadError(w, fmt.Errorf("no export in the form: %w", err))
+			return
+		}
+		defer f.Close()
+		in, name = f, hdr.Filename
+	}
+	log.Printf("Converting %s for %s", name, r.RemoteAddr)
+	c := flagConverter()
+	c.extra = extra
+	start := time.Now()
+	exp, err := importExport(r.Context(), name, in)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	defer exp.Close()
+	c.exp = exp
+	c.timings.add("parse", time.Since(start))
+	if len(exp.Entries) < 1 {
+		http.Error(w, "No blog entries found!", http.StatusUnprocessableEntity)
+		return
+	}
+
+	// The zip is put together in a scratch file first, so a failed conversion gets an
+	// error instead of a truncated download.
+	tmp, err := newScratch()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tmp.Close()
+	err = c.convertToZip(r.Context(), tmp)
+	if r.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="hugo-content.zip"`)
+	http.ServeContent(w, r, "", time.Time{}, tmp)
+}
+
+// Convert c's export into a zip of its content written to w, as a conversion of its
+// own run with the current flags.
+func (c *Converter) convertToZip(ctx context.Context, w io.Writer) error {
+	a, err := newArchive(w, "hugo-content.zip")
+	if err != nil {
+		return err
+	}
+	c.out = a
+	err = c.convert(ctx, false, "")
+	if cerr := a.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Answer an export that couldn't be read: too large, or not an export it can read.
+func uploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("The export is larger than the %d MB this server accepts.", *maxUpload), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// What would have the server run commands or make requests for whoever uploads.
+func checkServeFlags() error {
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"-exec-filter", *execFilter != ""},
+		{"-images", *bundleImages},
+		{"-check-links", *checkLinks},
+		{"-rewrite-domains", *rewriteDomainsFlag != ""},
+	} {
+		if f.set {
+			return fmt.Errorf("%s cannot be used with serve", f.name)
+		}
+	}
+	return nil
+}
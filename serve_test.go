@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// POST body to /convert with the given content type, returning the response.
+func postUpload(t *testing.T, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	convertUpload(w, r, "")
+	return w
+}
+
+// The names in a zip download.
+func zipNames(t *testing.T, b []byte) []string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("not a zip: %s", err)
+	}
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestConvertUpload(t *testing.T) {
+	export, err := os.ReadFile("tests/data/story-blogger-backup.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const post = "2014-05-19-the-gift-of-magi-o-henry.md"
+
+	w := postUpload(t, "application/atom+xml", export)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("got %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	if names := zipNames(t, w.Body.Bytes()); !strings.Contains(strings.Join(names, "\n"), post) {
+		t.Errorf("zip holds %v, want %s among them", names, post)
+	}
+
+	// From the upload page's form.
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("export", "blog.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(export)
+	mw.Close()
+	w = postUpload(t, mw.FormDataContentType(), form.Bytes())
+	if w.Code != http.StatusOK {
+		t.Fatalf("form upload: got %d: %s", w.Code, w.Body)
+	}
+	if names := zipNames(t, w.Body.Bytes()); !strings.Contains(strings.Join(names, "\n"), post) {
+		t.Errorf("form upload: zip holds %v, want %s among them", names, post)
+	}
+}
+
+func TestConvertUploadErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/convert", nil)
+	w := httptest.NewRecorder()
+	convertUpload(w, r, "")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: got %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if w := postUpload(t, "text/plain", []byte("not an export")); w.Code != http.StatusBadRequest {
+		t.Errorf("not an export: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := postUpload(t, "multipart/form-data; boundary=x", []byte("--x--\r\n")); w.Code != http.StatusBadRequest {
+		t.Errorf("form without an export: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	defer func(n int) { *maxUpload = n }(*maxUpload)
+	*maxUpload = 1
+	if w := postUpload(t, "application/atom+xml", bytes.Repeat([]byte("x"), 2<<20)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too large: got %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestCheckServeFlags(t *testing.T) {
+	if err := checkServeFlags(); err != nil {
+		t.Fatalf("no flags: %s", err)
+	}
+	defer func(s string) { *execFilter = s }(*execFilter)
+	*execFilter = "tidy -q"
+	if err := checkServeFlags(); err == nil || !strings.Contains(err.Error(), "-exec-filter") {
+		t.Errorf("-exec-filter: got %v, want it refused", err)
+	}
+	*execFilter = ""
+	defer func(b bool) { *checkLinks = b }(*checkLinks)
+	*checkLinks = true
+	if err := checkServeFlags(); err == nil || !strings.Contains(err.Error(), "-check-links") {
+		t.Errorf("-check-links: got %v, want it refused", err)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var siteConfigFile = flag.String("site-config", "", "write a starter hugo.toml from the blog's settings (title, description, language, time zone) to this file, relative to the target directory (e.g. ../../hugo.toml)")
+
+var siteConfigTempl = template.Must(template.New("").Parse(`baseURL = {{ printf "%q" .BaseURL }}
+title = {{ printf "%q" .Title }}{{ if .Language }}
+languageCode = {{ printf "%q" .Language }}
+defaultContentLanguage = {{ printf "%q" .DefaultLanguage }}{{ end }}{{ if .TimeZone }}
+timeZone = {{ printf "%q" .TimeZone }}{{ end }}
+
+[taxonomies]
+tag = {{ printf "%q" .Tags }}
+{{ range $i, $l := .Languages }}
+[languages.{{ $l.Code }}]
+weight = {{ $l.Weight }}{{ if $l.RTL }}
+languageDirection = "rtl"{{ end }}{{ with $l.ContentDir }}
+contentDir = {{ printf "%q" . }}{{ end }}
+{{ end }}{{ if .Description }}
+[params]
+description = {{ printf "%q" .Description }}
+{{ end }}`))
+
+type siteLanguage struct {
+	Code, ContentDir string
+	Weight           int
+	RTL              bool
+}
+
+// A hugo.toml for a new site carrying over the blog's name, description, locale
+// and time zone, with a placeholder baseURL unless -base-url is given.
+func (c *Converter) writeSiteConfig() error {
+	var cfg struct {
+		BaseURL, Title, Description, Language, DefaultLanguage, TimeZone, Tags string
+		// Under -multilingual, the main language first.
+		Languages []siteLanguage
+	}
+	cfg.BaseURL = *baseURL
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://example.org/"
+	}
+	cfg.Title = c.exp.Title
+	if name, ok := c.blogSetting("BLOG_NAME"); ok && name != "" {
+		cfg.Title = name
+	}
+	cfg.Description, _ = c.blogSetting("BLOG_DESCRIPTION")
+	// Blogger locales look like en_GB, Hugo wants en-gb.
+	if locale, _ := c.blogSetting("BLOG_LOCALE"); locale != "" {
+		cfg.Language = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+		cfg.DefaultLanguage, _, _ = strings.Cut(cfg.Language, "-")
+	}
+	if *multilingual != "" {
+		cfg.DefaultLanguage = c.language
+		if cfg.Language == "" {
+			cfg.Language = c.language
+		}
+		langs := append([]string{c.language}, c.languages...)
+		seen := map[string]bool{}
+		for _, lang := range langs {
+			if seen[lang] {
+				continue
+			}
+			seen[lang] = true
+			l := siteLanguage{Code: lang, Weight: len(cfg.Languages) + 1, RTL: rtlLanguages[lang]}
+			if *multilingual == "dir" {
+				l.ContentDir = path.Join("content", lang)
+			}
+			cfg.Languages = append(cfg.Languages, l)
+		}
+	}
+	cfg.TimeZone, _ = c.blogSetting("BLOG_TIME_ZONE")
+	cfg.Tags = c.tagsKey
+	f, err := c.create(path.Clean(filepath.ToSlash(*siteConfigFile)))
+	if err != nil {
+		return err
+	}
+	if err := siteConfigTempl.Execute(f, cfg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
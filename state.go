@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+)
+
+var stateFileName = flag.String("state", "", "a file to keep a hash of every file written in, so a re-run leaves the files whose generated content hasn't changed as they are, edits to them included, and only touches the posts that did change")
+
+// What a -state file holds: the hash of each file as it was generated, by its
+// slash-separated path in the target directory.
+type stateRecord struct {
+	Files map[string]string `json:"files"`
+}
+
+// A directory output that skips rewriting files whose generated content is the same
+// as last time, going by the hashes in the -state file, or the same as the file
+// already there.
+type stateDir struct {
+	DirFS
+	mu   sync.Mutex
+	last map[string]string
+	// Hashes of the files generated this run, written or not.
+	generated map[string]string
+	unchanged int
+}
+
+func openStateDir(dir DirFS, name string) (*stateDir, error) {
+	s := &stateDir{DirFS: dir, last: map[string]string{}, generated: map[string]string{}}
+	b, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec stateRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if rec.Files != nil {
+		s.last = rec.Files
+	}
+	return s, nil
+}
+
+func (s *stateDir) Create(name string) (io.WriteCloser, error) {
+	w, err := s.DirFS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &hashedFile{dirFile: w.(*dirFile), s: s, rel: name, h: sha256.New()}, nil
+}
+
+// Whether the file name, generated with the hash sum, can be left as it is: it's
+// there, and it was generated the same last time or already holds the same thing.
+func (s *stateDir) isUnchanged(name, sum string) bool {
+	full, err := s.path("open", name)
+	if err != nil {
+		return false
+	}
+	f, err := os.Open(full)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	s.mu.Lock()
+	last := s.last[name]
+	s.mu.Unlock()
+	if last == sum {
+		return true
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return hex.EncodeToString(h.Sum(nil)) == sum
+}
+
+// Write the hashes of this run's files to the state file name. An interrupted run
+// keeps the last run's hashes for the files it didn't get to.
+func (s *stateDir) save(name string, interrupted bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if interrupted {
+		for k, v := range s.last {
+			if _, ok := s.generated[k]; !ok {
+				s.generated[k] = v
+			}
+		}
+	}
+	b, err := json.MarshalIndent(stateRecord{Files: s.generated}, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(b, '\n'), 0644)
+}
+
+// A file hashed as it's written, and only put in place if it changed.
+type hashedFile struct {
+	*dirFile
+	s   *stateDir
+	rel string
+	h   hash.Hash
+}
+
+func (f *hashedFile) Write(p []byte) (int, error) {
+	f.h.Write(p)
+	return f.dirFile.Write(p)
+}
+
+func (f *hashedFile) Close() error {
+	sum := hex.EncodeToString(f.h.Sum(nil))
+	if f.err == nil && f.s.isUnchanged(f.rel, sum) {
+		err := f.f.Close()
+		os.Remove(f.f.Name())
+		if err != nil {
+			return err
+		}
+		f.s.mu.Lock()
+		f.s.generated[f.rel] = sum
+		f.s.unchanged++
+		f.s.mu.Unlock()
+		return nil
+	}
+	if err := f.dirFile.Close(); err != nil {
+		return err
+	}
+	f.s.mu.Lock()
+	f.s.generated[f.rel] = sum
+	f.s.mu.Unlock()
+	return nil
+}
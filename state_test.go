@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateDir(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(t.TempDir(), "state.json")
+	run := func(files map[string]string, interrupted bool) *stateDir {
+		t.Helper()
+		s, err := openStateDir(DirFS(dir), state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			w, err := s.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(w, content)
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.save(state, interrupted); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	run(map[string]string{"post/a.md": "one", "b.md": "two"}, false)
+	if got := read("post/a.md"); got != "one" {
+		t.Fatalf("first run wrote %q, want %q", got, "one")
+	}
+
+	// An edit to a file whose generated content hasn't changed is kept.
+	if err := os.WriteFile(filepath.Join(dir, "post", "a.md"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := run(map[string]string{"post/a.md": "one", "b.md": "changed"}, false)
+	if s.unchanged != 1 {
+		t.Errorf("left %d files unchanged, want 1", s.unchanged)
+	}
+	if got := read("post/a.md"); got != "edited" {
+		t.Errorf("unchanged post/a.md: got %q, want the edit kept", got)
+	}
+	if got := read("b.md"); got != "changed" {
+		t.Errorf("changed b.md: got %q, want it rewritten", got)
+	}
+
+	// Generated differently, the file is rewritten, edit or not.
+	run(map[string]string{"post/a.md": "one, revised"}, true)
+	if got := read("post/a.md"); got != "one, revised" {
+		t.Errorf("revised post/a.md: got %q, want it rewritten", got)
+	}
+	// The interrupted run kept b.md's hash, so an edit to it is still kept.
+	if err := os.WriteFile(filepath.Join(dir, "b.md"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if s := run(map[string]string{"b.md": "changed"}, false); s.unchanged != 1 {
+		t.Errorf("after an interrupted run, left %d files unchanged, want 1", s.unchanged)
+	}
+	if got := read("b.md"); got != "edited" {
+		t.Errorf("b.md after an interrupted run: got %q, want the edit kept", got)
+	}
+}
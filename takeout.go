@@ -0,0 +1,291 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+var takeoutBlog = flag.String("takeout-blog", "", "with a Google Takeout zip holding several blogs, the name of the blog's folder to convert")
+
+// A Google Takeout zip of Blogger, e.g. Takeout/Blogger/Blogs/My Blog/feed.atom, with
+// the blog's uploaded images under Takeout/Blogger/Albums/.
+type takeoutImporter struct{}
+
+func (ti takeoutImporter) Detect(b []byte) bool {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	return err == nil && ti.DetectZip(z)
+}
+
+func (takeoutImporter) DetectZip(z *zip.Reader) bool {
+	return len(takeoutFeeds(z)) > 0
+}
+
+// The Blogger feeds in a Takeout, by blog.
+func takeoutFeeds(z *zip.Reader) map[string]*zip.File {
+	feeds := map[string]*zip.File{}
+	for _, f := range z.File {
+		if path.Ext(f.Name) == ".atom" && strings.Contains(f.Name, "Blogger/") {
+			feeds[path.Base(path.Dir(f.Name))] = f
+		}
+	}
+	return feeds
+}
+
+func (ti takeoutImporter) Import(b []byte) (Export, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return Export{}, err
+	}
+	return ti.ImportZip(z)
+}
+
+func (takeoutImporter) ImportZip(z *zip.Reader) (Export, error) {
+	feeds := takeoutFeeds(z)
+	if len(feeds) == 0 {
+		return Export{}, fmt.Errorf("no Blogger feed in the Takeout")
+	}
+	var blogs []string
+	for name := range feeds {
+		blogs = append(blogs, name)
+	}
+	sort.Strings(blogs)
+	blog := *takeoutBlog
+	if blog == "" {
+		if len(blogs) > 1 {
+			return Export{}, fmt.Errorf("the Takeout holds %d blogs, pick one with -takeout-blog: %s", len(blogs), strings.Join(blogs, ", "))
+		}
+		blog = blogs[0]
+	}
+	f, ok := feeds[blog]
+	if !ok {
+		return Export{}, fmt.Errorf("no blog %q in the Takeout, it holds: %s", blog, strings.Join(blogs, ", "))
+	}
+	media := map[string]*zip.File{}
+	for _, m := range z.File {
+		if !m.FileInfo().IsDir() && (strings.Contains(m.Name, "Blogger/Albums/") || path.Dir(m.Name) == path.Dir(f.Name) && isMedia(m.Name)) {
+			if _, dup := media[path.Base(m.Name)]; !dup {
+				media[path.Base(m.Name)] = m
+			}
+		}
+	}
+	feed, err := readZipFile(f)
+	if err != nil {
+		return Export{}, err
+	}
+	exp, err := bloggerImporter{}.Import(feed)
+	exp.Media = media
+	return exp, err
+}
+
+func isMedia(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".svg", ".mp4", ".mov", ".webm":
+		return true
+	}
+	return false
+}
+
+// Takeouts since 2019 use a different feed from the Blogger export: the kind, status and
+// URL of each entry are blogger: elements, and comments name their post directly.
+const takeoutNS = "http://schemas.google.com/blogger/2018"
+
+type takeoutFeed struct {
+	Title   string         `xml:"title"`
+	Links   []Reply        `xml:"link"`
+	Entries []takeoutEntry `xml:"entry"`
+}
+
+type takeoutEntry struct {
+	ID        string   `xml:"id"`
+	Type      string   `xml:"http://schemas.google.com/blogger/2018 type"`
+	Status    string   `xml:"http://schemas.google.com/blogger/2018 status"`
+	Filename  string   `xml:"http://schemas.google.com/blogger/2018 filename"`
+	Parent    string   `xml:"http://schemas.google.com/blogger/2018 parent"`
+	InReplyTo string   `xml:"http://schemas.google.com/blogger/2018 inReplyTo"`
+	Title     string   `xml:"title"`
+	Content   string   `xml:"content"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Authors   []Author `xml:"author"`
+	Tags      []Tag    `xml:"category"`
+}
+
+// The feed's own <link> elements, which come before its entries.
+func feedLinks(b []byte) []Reply {
+	var links []Reply
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return links
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			switch se.Name.Local {
+			case "link":
+				var l Reply
+				dec.DecodeElement(&l, &se)
+				links = append(links, l)
+			case "entry":
+				return links
+			}
+		}
+	}
+}
+
+func importTakeoutFeed(b []byte) (Export, error) {
+	var f takeoutFeed
+	err := decodeEntries(bytes.NewReader(b), &f.Title, func(dec *xml.Decoder, start xml.StartElement) error {
+		var te takeoutEntry
+		if err := dec.DecodeElement(&te, &start); err != nil {
+			return err
+		}
+		f.Entries = append(f.Entries, te)
+		return nil
+	})
+	if err != nil {
+		return Export{}, err
+	}
+	f.Links = feedLinks(b)
+	host := ""
+	for _, l := range f.Links {
+		if l.Rel == "alternate" {
+			if u, err := url.Parse(l.Link); err == nil {
+				host = u.Scheme + "://" + u.Host
+			}
+		}
+	}
+	exp := Export{Title: f.Title}
+	for _, te := range f.Entries {
+		var author Author
+		if len(te.Authors) > 0 {
+			author = te.Authors[0]
+		}
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        te.ID,
+				Published: feedDate(te.Published, te.Updated),
+				Updated:   feedDate(te.Updated, te.Published),
+				Title:     te.Title,
+				Content:   te.Content,
+				Author:    author,
+				Authors:   te.Authors,
+				Draft:     Draft(te.Status == "DRAFT" || te.Status == "SOFT_TRASHED" || te.Status == "DELETED"),
+			},
+		}
+		switch te.Status {
+		case "SOFT_TRASHED":
+			e.Deleted = "in the trash"
+		case "DELETED":
+			e.Deleted = "deleted"
+		}
+		switch te.Type {
+		case "POST", "PAGE":
+			e.Tags = Tags{kindTag(strings.ToLower(te.Type))}
+			for _, t := range te.Tags {
+				e.Tags = append(e.Tags, labelTag(html.UnescapeString(t.Name)))
+			}
+			if te.Filename != "" {
+				e.Slug = strings.TrimSuffix(path.Base(te.Filename), path.Ext(te.Filename))
+				if host != "" && !e.Draft {
+					e.Links = []Reply{{Rel: "alternate", Link: host + te.Filename}}
+				}
+			}
+		case "COMMENT":
+			if te.Status != "LIVE" && te.Status != "" {
+				continue
+			}
+			e.ID = "takeout.post-" + idNumber(te.ID)
+			e.Title = author.Name
+			e.Tags = Tags{kindTag("comment")}
+			e.Source = Reply{Source: "takeout/" + idNumber(te.Parent)}
+			if te.InReplyTo != "" {
+				e.Links = []Reply{{Rel: "related", Link: "takeout/" + idNumber(te.InReplyTo)}}
+			}
+		default:
+			continue
+		}
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+// The number at the end of a Blogger ID, e.g. tag:blogger.com,1999:blog-1.post-42 -> 42
+func idNumber(id string) string {
+	return id[strings.LastIndexAny(id, "-.:")+1:]
+}
+
+// Point the entry's Blogger-hosted images at the copies bundled in the Takeout, writing
+// each to media/ in the target directory the first time it's used. Links are to /media/,
+// as the folder is meant to be moved to the site's static directory. Also returns the
+// images left on Blogger's servers, missing from the Takeout or failing to copy.
+func (c *Converter) useBundledMedia(e Entry) (string, []string) {
+	var missed []string
+	content := linkAttrPattern.ReplaceAllStringFunc(e.Content, func(attr string) string {
+		m := linkAttrPattern.FindStringSubmatch(attr)
+		raw := linkAttrURL(m)
+		u, err := url.Parse(raw)
+		if err != nil || !bloggerMediaHost(u.Host) {
+			return attr
+		}
+		name := path.Base(u.Path)
+		f, ok := c.exp.Media[name]
+		if !ok {
+			missed = append(missed, fmt.Sprintf("image %s isn't in the Takeout, so is still on Blogger's servers", raw))
+			return attr
+		}
+		dest := path.Join("media", name)
+		// Posts written side by side can share an image, which is copied once.
+		c.mediaMu.Lock()
+		copied := c.copiedMedia[dest]
+		if !copied {
+			if err := c.copyZipFile(f, dest); err != nil {
+				log.Printf("Failed copying %s from the Takeout: %s", f.Name, err)
+				missed = append(missed, fmt.Sprintf("image %s couldn't be copied from the Takeout, so is still on Blogger's servers: %s", raw, err))
+			} else {
+				c.copiedMedia[dest] = true
+				copied = true
+			}
+		}
+		c.mediaMu.Unlock()
+		if !copied {
+			return attr
+		}
+		return strings.Replace(attr, m[1][1:len(m[1])-1], absURL("/"+dest), 1)
+	})
+	return content, missed
+}
+
+// Hosts Blogger serves uploaded images from.
+func bloggerMediaHost(host string) bool {
+	host = strings.ToLower(host)
+	return strings.HasSuffix(host, ".bp.blogspot.com") || strings.HasSuffix(host, ".googleusercontent.com")
+}
+
+func (c *Converter) copyZipFile(f *zip.File, name string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := c.create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
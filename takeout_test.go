@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTakeoutFeed = `<?xml version='1.0' encoding='utf-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:blogger='http://schemas.google.com/blogger/2018'>
+<title>My Blog</title>
+<link rel='alternate' type='text/html' href='https://myblog.blogspot.com/'/>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-42</id>
+<blogger:type>POST</blogger:type>
+<blogger:status>LIVE</blogger:status>
+<blogger:filename>/2019/03/hello.html</blogger:filename>
+<title>Hello</title>
+<content type='html'>&lt;img src="https://blogger.googleusercontent.com/img/b/x/s320/photo.jpg"&gt;</content>
+<published>2019-03-01T10:00:00.000Z</published>
+<updated>2019-03-02T10:00:00.000Z</updated>
+<author><name>Ann</name></author>
+<category scheme='tag:blogger.com,1999:blog-1' term='Travel &amp;amp; Food'/>
+</entry>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-43</id>
+<blogger:type>POST</blogger:type>
+<blogger:status>DRAFT</blogger:status>
+<blogger:filename>/2019/04/later.html</blogger:filename>
+<title>Later</title>
+<published>2019-04-01T10:00:00.000Z</published>
+<author><name>Ann</name></author>
+</entry>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-7001</id>
+<blogger:type>COMMENT</blogger:type>
+<blogger:status>LIVE</blogger:status>
+<blogger:parent>tag:blogger.com,1999:blog-1.post-42</blogger:parent>
+<title>Nice</title>
+<content type='html'>Nice.</content>
+<published>2019-03-03T10:00:00.000Z</published>
+<author><name>Bob</name></author>
+</entry>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-7002</id>
+<blogger:type>COMMENT</blogger:type>
+<blogger:status>LIVE</blogger:status>
+<blogger:parent>tag:blogger.com,1999:blog-1.post-42</blogger:parent>
+<blogger:inReplyTo>tag:blogger.com,1999:blog-1.post-7001</blogger:inReplyTo>
+<content type='html'>Thanks.</content>
+<published>2019-03-04T10:00:00.000Z</published>
+<author><name>Ann</name></author>
+</entry>
+<entry>
+<id>tag:blogger.com,1999:blog-1.post-7003</id>
+<blogger:type>COMMENT</blogger:type>
+<blogger:status>SPAM_COMMENT</blogger:status>
+<blogger:parent>tag:blogger.com,1999:blog-1.post-42</blogger:parent>
+<content type='html'>Buy now</content>
+<author><name>Spammer</name></author>
+</entry>
+</feed>`
+
+func TestTakeoutImport(t *testing.T) {
+	defer func(b string) { *takeoutBlog = b }(*takeoutBlog)
+	export := zipOf(t,
+		[2]string{"Takeout/Blogger/Blogs/My Blog/feed.atom", testTakeoutFeed},
+		[2]string{"Takeout/Blogger/Albums/My Blog/photo.jpg", "JPEG"},
+		[2]string{"Takeout/archive_browser.html", "<p>Your data</p>"},
+	)
+	testImport(t, "auto", export, []string{
+		`post tag:blogger.com,1999:blog-1.post-42 "Hello" by Ann at 2019-03-01T10:00:00Z labels Travel & Food`,
+		`post tag:blogger.com,1999:blog-1.post-43 "Later" by Ann at 2019-04-01T10:00:00Z draft`,
+		`comment takeout.post-7001 "Bob" by Bob at 2019-03-03T10:00:00Z on takeout/42`,
+		`comment takeout.post-7002 "Ann" by Ann at 2019-03-04T10:00:00Z on takeout/42 replying to takeout/7001`,
+	})
+	exp, err := takeoutImporter{}.Import([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := exp.Media["photo.jpg"]; f == nil || f.Name != "Takeout/Blogger/Albums/My Blog/photo.jpg" {
+		t.Errorf("photo.jpg not found among the Takeout's media: %v", exp.Media)
+	}
+
+	two := zipOf(t,
+		[2]string{"Takeout/Blogger/Blogs/My Blog/feed.atom", testTakeoutFeed},
+		[2]string{"Takeout/Blogger/Blogs/Other Blog/feed.atom", testTakeoutFeed},
+	)
+	_, err = takeoutImporter{}.Import([]byte(two))
+	if err == nil || !strings.Contains(err.Error(), "My Blog, Other Blog") {
+		t.Errorf("err = %v, want the blogs to pick from listed", err)
+	}
+	*takeoutBlog = "Other Blog"
+	if _, err = (takeoutImporter{}).Import([]byte(two)); err != nil {
+		t.Errorf("with -takeout-blog: %s", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+var target = flag.String("target", "hugo", "static site generator to convert for: hugo; jekyll (the target directory is then the site's root, with posts written into _posts, drafts into _drafts and comments into _data/comments); zola (into a section of its content directory, with TOML front matter); eleventy (the target directory is then the site's input directory, with posts written into posts and comments into _data/comments); pelican (into its content directory, with pages under pages and comments under comments); hexo (into the site's source directory, with posts written into _posts and comments into _data/comments.json); or markdown (plain Markdown notes by year and month, for an Obsidian vault or just to keep)")
+
+// A Target is a static site generator the blog is converted for: where the posts and
+// pages go, what their front matter looks like, and the URLs the site gives them.
+type Target interface {
+	// The template posts and pages are written with.
+	Template() string
+	// Work out where a post or page is written, setting its Path and Slug.
+	PlacePost(c *Converter, e *Entry) error
+	PlacePage(c *Converter, e *Entry) error
+	// The URL the site serves the post or page written to name at, e.g. /posts/hello/
+	Permalink(c *Converter, e Entry, name string) string
+	// Write a comment as it's found, before the posts are placed.
+	WriteComment(c *Converter, e Entry) error
+	// Write what the site needs once the posts and pages are written.
+	WriteSite(c *Converter) error
+}
+
+// Targets by -target.
+var targets = map[string]Target{
+	"hugo":     hugoTarget{},
+	"jekyll":   jekyllTarget{},
+	"zola":     zolaTarget{},
+	"eleventy": eleventyTarget{},
+	"pelican":  pelicanTarget{},
+	"hexo":     hexoTarget{},
+	"markdown": markdownTarget{},
+}
+
+type hugoTarget struct{}
+
+func (hugoTarget) Template() string {
+	if *format == "toml" {
+		return hugo.TOMLTemplate
+	}
+	return hugo.YAMLTemplate
+}
+
+func (hugoTarget) PlacePost(c *Converter, e *Entry) error { return c.placeEntry(e) }
+func (hugoTarget) PlacePage(c *Converter, e *Entry) error { return c.placePage(e) }
+
+func (hugoTarget) Permalink(c *Converter, e Entry, name string) string {
+	return c.hugoPermalink(e, name)
+}
+
+func (hugoTarget) WriteComment(c *Converter, e Entry) error { return c.writeComment(e) }
+
+// The Hugo site files are each asked for with their own flag, see writeSiteFiles.
+func (hugoTarget) WriteSite(c *Converter) error { return nil }
+
+// A comment as the targets keeping comments as data write them.
+type dataComment struct {
+	ID         string `json:"id"`
+	Author     string `json:"author"`
+	AuthorURI  string `json:"author_uri,omitempty"`
+	ReplyingTo string `json:"replying_to,omitempty"`
+	Date       string `json:"date"`
+	Content    string `json:"content"`
+}
+
+// The comments on the post at k, oldest thread first.
+func (c *Converter) dataComments(k int) []dataComment {
+	post := c.exp.Entries[k]
+	var comments []dataComment
+	for _, i := range c.treeSort(k) {
+		e := c.exp.Entries[i]
+		comment := dataComment{
+			ID:        e.ID,
+			Author:    strings.TrimSpace(e.Author.Name),
+			AuthorURI: e.Author.Uri,
+			Date:      e.Published.String(),
+			Content:   entryContent(e),
+		}
+		if reply := strconv.FormatUint(e.Reply, 10); e.Reply != 0 && reply != post.ID {
+			comment.ReplyingTo = reply
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// A post's file name without its date prefix or extension, which is what the targets
+// other than Hugo know it by, e.g. hello for _posts/2014-05-19-hello.md.
+func postFileSlug(e Entry) string {
+	slug := strings.TrimSuffix(path.Base(e.Path), path.Ext(e.Path))
+	return strings.TrimPrefix(slug, e.Published.String()[:10]+"-")
+}
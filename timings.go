@@ -0,0 +1,70 @@
+package main
+
+import (
+	"expvar"
+	"flag"
+	"log"
+	"sync"
+	"time"
+)
+
+var showTimings = flag.Bool("timings", false, "report how long each stage of the conversion took: parse, download, convert and write")
+
+// The stages of a conversion, in the order they're reported. Parsing includes
+// downloading the export; download is resolving -rewrite-domains and -check-links;
+// write includes the -archive-dead-links lookups, made as posts are written.
+var stageNames = []string{"parse", "download", "convert", "write"}
+
+// Totals for every conversion this process has run, for serve's /debug/vars.
+var (
+	stageSeconds = expvar.NewMap("stage_seconds")
+	conversions  = expvar.NewInt("conversions")
+	postsWritten = expvar.NewInt("posts_written")
+)
+
+// Time spent in each stage of a conversion.
+type timings struct {
+	mu    sync.Mutex
+	stage map[string]time.Duration
+}
+
+func (t *timings) add(stage string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stage == nil {
+		t.stage = map[string]time.Duration{}
+	}
+	t.stage[stage] += d
+}
+
+func (t *timings) get(stage string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stage[stage]
+}
+
+// Add a finished conversion, which wrote written posts and pages, to the totals, and
+// log its timings under -timings.
+func (t *timings) finish(written int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conversions.Add(1)
+	postsWritten.Add(int64(written))
+	var total time.Duration
+	for _, stage := range stageNames {
+		stageSeconds.AddFloat(stage, t.stage[stage].Seconds())
+		total += t.stage[stage]
+	}
+	if !*showTimings {
+		return
+	}
+	log.Printf("Took %v:", total.Round(time.Millisecond))
+	for _, stage := range stageNames {
+		d := t.stage[stage]
+		if stage == "write" && written > 0 && d > 0 {
+			log.Printf("\t%-8s %v, %.0f posts and pages a second", stage, d.Round(time.Millisecond), float64(written)/d.Seconds())
+			continue
+		}
+		log.Printf("\t%-8s %v", stage, d.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+	"golang.org/x/text/unicode/norm"
+)
+
+var titlesFlag = flag.String("titles", "", "comma separated clean-ups of post and page titles: trim (trim them and collapse runs of spaces), suffix (strip a trailing site name, e.g. \"... | My Blog\", the blog's name or -title-suffix), and title-case or sentence-case to recase them")
+var titleSuffix = flag.String("title-suffix", "", "with -titles=suffix, the site name titles end with, if it isn't the blog's name")
+
+// Parsed -titles, by clean-up.
+var titleCleanups = map[string]bool{}
+
+func parseTitleCleanups(s string) error {
+	for _, cleanup := range strings.Split(s, ",") {
+		switch cleanup = strings.TrimSpace(cleanup); cleanup {
+		case "":
+		case "trim", "suffix", "title-case", "sentence-case":
+			titleCleanups[cleanup] = true
+		default:
+			return fmt.Errorf("unknown clean-up %q; expected trim, suffix, title-case or sentence-case", cleanup)
+		}
+	}
+	if titleCleanups["title-case"] && titleCleanups["sentence-case"] {
+		return errors.New("title-case and sentence-case cannot be used together")
+	}
+	return nil
+}
+
+// The site name -titles=suffix strips from titles.
+func (c *Converter) titleSiteName() string {
+	if *titleSuffix != "" {
+		return strings.TrimSpace(*titleSuffix)
+	}
+	if name, ok := c.blogSetting("BLOG_NAME"); ok && name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.exp.Title)
+}
+
+// What separates a title from the site name after it.
+var suffixSeparators = []string{"|", "-", "–", "—", "::", ":", "»", "·"}
+
+// Small words title case leaves lowercase, unless they start or end the title.
+var smallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "but": true, "by": true, "for": true,
+	"in": true, "nor": true, "of": true, "on": true, "or": true, "per": true, "the": true, "to": true,
+	"vs": true, "via": true,
+}
+
+// A title with the -titles clean-ups done, site the name of the site to strip.
+func cleanTitle(title, site string) string {
+	if titleCleanups["trim"] {
+		title = strings.Join(strings.FieldsFunc(title, unicode.IsSpace), " ")
+	}
+	if titleCleanups["suffix"] && site != "" {
+		title = stripSiteName(title, site)
+	}
+	switch {
+	case titleCleanups["title-case"]:
+		title = recase(title, true)
+	case titleCleanups["sentence-case"]:
+		title = recase(title, false)
+	}
+	return title
+}
+
+// title without a trailing " | site", if there's anything before it.
+func stripSiteName(title, site string) string {
+	rest := strings.TrimSpace(title)
+	if len(rest) <= len(site) || !strings.EqualFold(rest[len(rest)-len(site):], site) {
+		return title
+	}
+	rest = strings.TrimSpace(rest[:len(rest)-len(site)])
+	for _, sep := range suffixSeparators {
+		if strings.HasSuffix(rest, sep) {
+			if stripped := strings.TrimSpace(strings.TrimSuffix(rest, sep)); stripped != "" {
+				return stripped
+			}
+		}
+	}
+	return title
+}
+
+// A title in title case, or with titleCase false in sentence case. Words written
+// other than in lower case or with a capital to start, such as NASA or iPhone, are
+// taken to be names and left as they are.
+func recase(title string, titleCase bool) string {
+	words := strings.Split(title, " ")
+	first, last := -1, -1
+	for i, w := range words {
+		if strings.IndexFunc(w, unicode.IsLetter) >= 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	for i, w := range words {
+		if i < first || !plainWord(w) {
+			continue
+		}
+		lower := strings.ToLower(w)
+		switch {
+		case i == first, lower == "i", strings.HasPrefix(lower, "i'"), strings.HasPrefix(lower, "i’"):
+			words[i] = capitalize(lower)
+		case !titleCase:
+			words[i] = lower
+		case smallWords[strings.TrimFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })] && i != last:
+			words[i] = lower
+		default:
+			words[i] = capitalize(lower)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Whether a word is in lower case, or lower case with a capital to start.
+func plainWord(w string) bool {
+	letters := 0
+	for _, r := range w {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.IsUpper(r) && letters > 0 {
+			return false
+		}
+		letters++
+	}
+	return letters > 0
+}
+
+// A word with its first letter in upper case, e.g. "(why" -> "(Why".
+func capitalize(w string) string {
+	i := strings.IndexFunc(w, unicode.IsLetter)
+	if i < 0 {
+		return w
+	}
+	r, size := utf8.DecodeRuneInString(w[i:])
+	return w[:i] + string(unicode.ToTitle(r)) + w[i+size:]
+}
+
+// Normalize an entry's title and labels to NFC, so the same words typed where text is
+// decomposed, as on macOS, and typed elsewhere make the same slugs, filenames and terms.
+func normalizeTitle(e *Entry) {
+	e.Title = norm.NFC.String(e.Title)
+	for i, t := range e.Tags {
+		if t.Scheme != blogger.KindScheme {
+			e.Tags[i].Name = norm.NFC.String(t.Name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/hugo"
+)
+
+var execFilter = flag.String("exec-filter", "", "pipe each post's and page's HTML through this shell command and write its output instead, e.g. 'tidy -q -asxhtml'")
+
+// Run -exec-filter, then the transforms registered with hugo.RegisterTransform, on a
+// post or page about to be written, and take back what they changed.
+func (c *Converter) transform(ctx context.Context, e *Entry) error {
+	p := hugo.ConvertedPost{
+		Kind:      e.Kind(),
+		ID:        hugo.PostID(e.ID),
+		Title:     e.Title,
+		Slug:      e.Slug,
+		Path:      e.Path,
+		URL:       e.URL,
+		Published: time.Time(e.Published),
+		Updated:   time.Time(e.Updated),
+		Draft:     bool(e.Draft),
+		Labels:    e.Tags.Labels(),
+		Author:    e.Author.Name,
+		Authors:   entryAuthors(*e),
+		Params:    e.Params,
+		Content:   e.Content,
+	}
+	if *execFilter != "" {
+		if err := filterContent(ctx, &p); err != nil {
+			return err
+		}
+	}
+	if err := hugo.ApplyTransforms(ctx, &p); err != nil {
+		return err
+	}
+	p.ApplyTo(&e.Entry)
+	e.Params = p.Params
+	return nil
+}
+
+// Pipe a post's HTML through -exec-filter. The command is told which post it is by
+// BLOGGER2HUGO_KIND, _ID, _TITLE, _URL and _PATH in its environment.
+func filterContent(ctx context.Context, p *hugo.ConvertedPost) error {
+	shell, arg := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, arg = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, arg, *execFilter)
+	cmd.Env = append(os.Environ(),
+		"BLOGGER2HUGO_KIND="+p.Kind,
+		"BLOGGER2HUGO_ID="+p.ID,
+		"BLOGGER2HUGO_TITLE="+p.Title,
+		"BLOGGER2HUGO_URL="+p.URL,
+		"BLOGGER2HUGO_PATH="+p.Path,
+	)
+	cmd.Stdin = strings.NewReader(p.Content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("-exec-filter: %w: %s", err, msg)
+		}
+		return fmt.Errorf("-exec-filter: %w", err)
+	}
+	p.Content = stdout.String()
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// Tumblr's posts XML, as served by its v1 API (/api/read) and saved by most Tumblr
+// backup tools, either on its own or as posts.xml in a zip.
+type tumblrExport struct {
+	Blog struct {
+		Name  string `xml:"name,attr"`
+		Title string `xml:"title,attr"`
+	} `xml:"tumblelog"`
+	Posts []tumblrPost `xml:"posts>post"`
+}
+
+type tumblrPost struct {
+	ID        uint64   `xml:"id,attr"`
+	URL       string   `xml:"url-with-slug,attr"`
+	Type      string   `xml:"type,attr"`
+	DateGMT   string   `xml:"date-gmt,attr"`
+	Timestamp int64    `xml:"unix-timestamp,attr"`
+	Slug      string   `xml:"slug,attr"`
+	Private   bool     `xml:"private,attr"`
+	Tags      []string `xml:"tag"`
+
+	RegularTitle    string           `xml:"regular-title"`
+	RegularBody     string           `xml:"regular-body"`
+	PhotoCaption    string           `xml:"photo-caption"`
+	PhotoLink       string           `xml:"photo-link-url"`
+	PhotoURLs       []tumblrPhoto    `xml:"photo-url"`
+	Photoset        []tumblrSetPhoto `xml:"photoset>photo"`
+	QuoteText       string           `xml:"quote-text"`
+	QuoteSource     string           `xml:"quote-source"`
+	LinkText        string           `xml:"link-text"`
+	LinkURL         string           `xml:"link-url"`
+	LinkDescription string           `xml:"link-description"`
+	ConvTitle       string           `xml:"conversation-title"`
+	ConvLines       []tumblrLine     `xml:"conversation>line"`
+	VideoCaption    string           `xml:"video-caption"`
+	VideoPlayer     string           `xml:"video-player"`
+	AudioCaption    string           `xml:"audio-caption"`
+	AudioPlayer     string           `xml:"audio-player"`
+	Question        string           `xml:"question"`
+	Answer          string           `xml:"answer"`
+}
+
+type tumblrPhoto struct {
+	MaxWidth int    `xml:"max-width,attr"`
+	URL      string `xml:",chardata"`
+}
+
+type tumblrSetPhoto struct {
+	URLs []tumblrPhoto `xml:"photo-url"`
+}
+
+type tumblrLine struct {
+	Name string `xml:"name,attr"`
+	Text string `xml:",chardata"`
+}
+
+type tumblrImporter struct{}
+
+func (tumblrImporter) Detect(b []byte) bool {
+	if x, err := tumblrXML(b); err == nil {
+		return rootElement(x) == "tumblr"
+	}
+	return false
+}
+
+func (tumblrImporter) DetectZip(z *zip.Reader) bool {
+	if x, err := tumblrZipXML(z); err == nil {
+		return rootElement(x) == "tumblr"
+	}
+	return false
+}
+
+// The posts XML, from a zip if b is one.
+func tumblrXML(b []byte) ([]byte, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return b, nil
+	}
+	return tumblrZipXML(z)
+}
+
+func tumblrZipXML(z *zip.Reader) ([]byte, error) {
+	for _, f := range z.File {
+		if path.Base(f.Name) == "posts.xml" {
+			return readZipFile(f)
+		}
+	}
+	return nil, fmt.Errorf("no posts.xml in the zip")
+}
+
+func (tumblrImporter) Import(b []byte) (Export, error) {
+	x, err := tumblrXML(b)
+	if err != nil {
+		return Export{}, err
+	}
+	return importTumblr(x)
+}
+
+func (tumblrImporter) ImportZip(z *zip.Reader) (Export, error) {
+	x, err := tumblrZipXML(z)
+	if err != nil {
+		return Export{}, err
+	}
+	return importTumblr(x)
+}
+
+func importTumblr(x []byte) (Export, error) {
+	var t tumblrExport
+	if err := xml.Unmarshal(x, &t); err != nil {
+		return Export{}, err
+	}
+	exp := Export{Title: t.Blog.Title}
+	for _, p := range t.Posts {
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:     "tumblr.post-" + strconv.FormatUint(p.ID, 10),
+				Tags:   Tags{kindTag("post")},
+				Author: Author{Name: t.Blog.Name},
+			},
+			Slug:   p.Slug,
+			Params: map[string]string{"tumblr_type": p.Type},
+		}
+		if d, err := time.Parse("2006-01-02 15:04:05 MST", p.DateGMT); err == nil {
+			e.Published = Date(d.UTC())
+		} else if p.Timestamp > 0 {
+			e.Published = Date(time.Unix(p.Timestamp, 0).UTC())
+		}
+		e.Updated = e.Published
+		if p.URL != "" {
+			e.Links = []Reply{{Rel: "alternate", Link: p.URL}}
+		}
+		if p.Private {
+			e.Hidden = "private on Tumblr"
+		}
+		for _, tag := range p.Tags {
+			e.Tags = append(e.Tags, labelTag(tag))
+		}
+		e.Title, e.Content = tumblrBody(p, e.Params)
+		exp.Entries = append(exp.Entries, e)
+	}
+	return exp, nil
+}
+
+// A title and HTML body for each kind of Tumblr post. Photo, quote and link posts have no
+// title of their own, so one is made from their text; the parts a theme may want to lay out
+// itself (the link, the quote's source) also go into params.
+func tumblrBody(p tumblrPost, params map[string]string) (title, body string) {
+	var b strings.Builder
+	switch p.Type {
+	case "photo":
+		photos := [][]tumblrPhoto{p.PhotoURLs}
+		if len(p.Photoset) > 0 {
+			photos = nil
+			for _, ph := range p.Photoset {
+				photos = append(photos, ph.URLs)
+			}
+		}
+		for _, sizes := range photos {
+			if src := largestPhoto(sizes); src != "" {
+				fmt.Fprintf(&b, "<figure><img src=\"%s\"></figure>\n", html.EscapeString(src))
+				if params["image"] == "" {
+					params["image"] = src
+				}
+			}
+		}
+		if p.PhotoLink != "" {
+			params["link"] = p.PhotoLink
+		}
+		b.WriteString(p.PhotoCaption)
+		return textTitle(p.PhotoCaption), b.String()
+	case "quote":
+		fmt.Fprintf(&b, "<blockquote>%s</blockquote>\n", p.QuoteText)
+		if p.QuoteSource != "" {
+			fmt.Fprintf(&b, "<p>— %s</p>\n", p.QuoteSource)
+			params["quote_source"] = html.UnescapeString(tagPattern.ReplaceAllString(p.QuoteSource, ""))
+		}
+		return textTitle(p.QuoteText), b.String()
+	case "link":
+		params["link"] = p.LinkURL
+		text := p.LinkText
+		if text == "" {
+			text = p.LinkURL
+		}
+		fmt.Fprintf(&b, "<p><a href=\"%s\">%s</a></p>\n%s", html.EscapeString(p.LinkURL), text, p.LinkDescription)
+		return html.UnescapeString(tagPattern.ReplaceAllString(text, "")), b.String()
+	case "conversation":
+		for _, l := range p.ConvLines {
+			fmt.Fprintf(&b, "<p><strong>%s</strong> %s</p>\n", html.EscapeString(l.Name), html.EscapeString(l.Text))
+		}
+		return p.ConvTitle, b.String()
+	case "video":
+		return textTitle(p.VideoCaption), p.VideoPlayer + "\n" + p.VideoCaption
+	case "audio":
+		return textTitle(p.AudioCaption), p.AudioPlayer + "\n" + p.AudioCaption
+	case "answer":
+		fmt.Fprintf(&b, "<blockquote>%s</blockquote>\n%s", p.Question, p.Answer)
+		return textTitle(p.Question), b.String()
+	}
+	return p.RegularTitle, p.RegularBody
+}
+
+func largestPhoto(sizes []tumblrPhoto) string {
+	best := tumblrPhoto{}
+	for _, s := range sizes {
+		if s.MaxWidth >= best.MaxWidth {
+			best = s
+		}
+	}
+	return strings.TrimSpace(best.URL)
+}
+
+// The first few words of some HTML, for posts that have no title.
+func textTitle(s string) string {
+	words := strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(s, " ")))
+	if len(words) > 8 {
+		return strings.Join(words[:8], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
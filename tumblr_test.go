@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+const testTumblr = `<?xml version="1.0" encoding="UTF-8"?>
+<tumblr version="1.0">
+<tumblelog name="ann" title="Ann's Tumblr"/>
+<posts>
+<post id="101" url-with-slug="https://ann.tumblr.com/post/101/hello" type="regular" date-gmt="2013-02-03 04:05:06 GMT" slug="hello">
+<regular-title>Hello</regular-title><regular-body>&lt;p&gt;Hi&lt;/p&gt;</regular-body><tag>Go</tag><tag>Web</tag>
+</post>
+<post id="102" type="photo" unix-timestamp="1360000000" slug="sunset" private="true">
+<photo-caption>&lt;p&gt;A sunset over the bay, seen from the hill behind the house&lt;/p&gt;</photo-caption>
+<photo-link-url>https://example.com/sunset</photo-link-url>
+<photo-url max-width="500">https://64.media.tumblr.com/a_500.jpg</photo-url>
+<photo-url max-width="1280">https://64.media.tumblr.com/a_1280.jpg</photo-url>
+<photo-url max-width="100">https://64.media.tumblr.com/a_100.jpg</photo-url>
+</post>
+<post id="103" type="quote" date-gmt="2013-02-05 00:00:00 GMT">
+<quote-text>Be yourself.</quote-text><quote-source>&lt;a href="https://example.com/"&gt;Oscar Wilde&lt;/a&gt;</quote-source>
+</post>
+<post id="104" type="link" date-gmt="2013-02-06 00:00:00 GMT">
+<link-url>https://go.dev/</link-url><link-description>The Go site</link-description>
+</post>
+</posts>
+</tumblr>`
+
+func TestTumblrImport(t *testing.T) {
+	want := []string{
+		`post tumblr.post-101 "Hello" by ann at 2013-02-03T04:05:06Z labels Go, Web`,
+		`post tumblr.post-102 "A sunset over the bay, seen from the…" by ann at 2013-02-04T17:46:40Z`,
+		`post tumblr.post-103 "Be yourself." by ann at 2013-02-05T00:00:00Z`,
+		`post tumblr.post-104 "https://go.dev/" by ann at 2013-02-06T00:00:00Z`,
+	}
+	testImport(t, "auto", testTumblr, want)
+	// The same, zipped by a backup tool.
+	testImport(t, "auto", zipOf(t, [2]string{"ann/posts.xml", testTumblr}), want)
+
+	exp, err := tumblrImporter{}.Import([]byte(testTumblr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo, quote, link := exp.Entries[1], exp.Entries[2], exp.Entries[3]
+	if got := photo.Params["image"]; got != "https://64.media.tumblr.com/a_1280.jpg" {
+		t.Errorf("photo image = %q, want the largest size", got)
+	}
+	if photo.Params["link"] != "https://example.com/sunset" || photo.Hidden == "" {
+		t.Errorf("photo params = %v, hidden %q, want its link, and hidden as it's private", photo.Params, photo.Hidden)
+	}
+	if got := quote.Params["quote_source"]; got != "Oscar Wilde" {
+		t.Errorf("quote source = %q, want Oscar Wilde", got)
+	}
+	if got, want := link.Content, "<p><a href=\"https://go.dev/\">https://go.dev/</a></p>\nThe Go site"; got != want {
+		t.Errorf("link content = %q, want %q", got, want)
+	}
+}
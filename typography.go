@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var typographyFlag = flag.String("typography", "", "comma separated clean-ups of the typography Blogger's editor left in posts: entities (&ldquo;, &mdash;, &#8217; and the like written as the characters they stand for), quotes (curly quotes made straight), nbsp (runs of non-breaking spaces made one space, and paragraphs of nothing else dropped), or all")
+
+// Parsed -typography, by pass.
+var typography = map[string]bool{}
+
+func parseTypography(s string) error {
+	for _, pass := range strings.Split(s, ",") {
+		switch pass = strings.TrimSpace(pass); pass {
+		case "":
+		case "all":
+			typography["entities"], typography["quotes"], typography["nbsp"] = true, true, true
+		case "entities", "quotes", "nbsp":
+			typography[pass] = true
+		default:
+			return fmt.Errorf("unknown pass %q; expected entities, quotes, nbsp or all", pass)
+		}
+	}
+	return nil
+}
+
+// Entities for typographic characters, which read better in the Markdown as themselves.
+var typographicEntities = strings.NewReplacer(
+	"&ldquo;", "“", "&rdquo;", "”", "&lsquo;", "‘", "&rsquo;", "’", "&bdquo;", "„", "&sbquo;", "‚",
+	"&laquo;", "«", "&raquo;", "»", "&mdash;", "—", "&ndash;", "–", "&hellip;", "…",
+	"&#8220;", "“", "&#8221;", "”", "&#8216;", "‘", "&#8217;", "’", "&#8222;", "„",
+	"&#8212;", "—", "&#8211;", "–", "&#8230;", "…",
+	"&#x201C;", "“", "&#x201D;", "”", "&#x2018;", "‘", "&#x2019;", "’",
+	"&#x201c;", "“", "&#x201d;", "”", "&#x2014;", "—", "&#x2013;", "–", "&#x2026;", "…",
+)
+
+var straightQuotes = strings.NewReplacer("“", `"`, "”", `"`, "„", `"`, "‘", "'", "’", "'", "‚", "'")
+
+// Runs of spaces, breaking or not.
+var spaceRun = regexp.MustCompile(`(?:[ \t]|&nbsp;|&#160;|&#xa0;|\x{a0})+`)
+
+// A run of spaces with a non-breaking one among them made one space. A lone
+// non-breaking space, as in 10&nbsp;km, is kept.
+func collapseNbsp(run string) string {
+	switch {
+	case strings.Trim(run, " \t") == "":
+		return run
+	case run == "&nbsp;", run == "&#160;", run == "&#xa0;", run == "\u00a0":
+		return run
+	}
+	return " "
+}
+
+// Paragraphs Blogger's editor left to space posts out, e.g. <p>&nbsp;</p>.
+var nbspParagraph = regexp.MustCompile(`(?i)<(?:p|div)\b[^>]*>(?:\s|&nbsp;|&#160;|&#xa0;|\x{a0}|<br\s*/?>)*</(?:p|div)>\n?`)
+
+// Elements whose text is kept exactly as it is.
+var verbatimElements = map[string]bool{"pre": true, "code": true, "script": true, "style": true, "textarea": true}
+
+// Apply the -typography passes to a post's HTML. Only the text between tags is
+// touched, and not the text of <pre>, <code>, <script> and the like.
+func normalizeTypography(content string) string {
+	if typography["nbsp"] {
+		content = nbspParagraph.ReplaceAllString(content, "")
+	}
+	var b strings.Builder
+	verbatim := 0
+	last := 0
+	clean := func(s string) string {
+		if verbatim > 0 {
+			return s
+		}
+		if typography["entities"] || typography["quotes"] {
+			s = typographicEntities.Replace(s)
+		}
+		if typography["quotes"] {
+			s = straightQuotes.Replace(s)
+		}
+		if typography["nbsp"] {
+			s = spaceRun.ReplaceAllStringFunc(s, collapseNbsp)
+		}
+		return s
+	}
+	for _, loc := range tagPattern.FindAllStringIndex(content, -1) {
+		b.WriteString(clean(content[last:loc[0]]))
+		tag := content[loc[0]:loc[1]]
+		b.WriteString(tag)
+		last = loc[1]
+		if m := tagName.FindStringSubmatch(tag); m != nil && verbatimElements[strings.ToLower(m[2])] && !strings.HasSuffix(tag, "/>") {
+			if m[1] == "" {
+				verbatim++
+			} else if verbatim > 0 {
+				verbatim--
+			}
+		}
+	}
+	b.WriteString(clean(content[last:]))
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var verifyRate = flag.Duration("verify-rate", time.Second, "with verify, wait this long between requests to Blogger")
+var verifyThreshold = flag.Float64("verify-threshold", 0.9, "with verify, flag posts where less than this share of the converted text appears on the live page")
+
+var (
+	scriptPattern  = regexp.MustCompile(`(?is)<(script|style|noscript)\b.*?</(script|style|noscript)>`)
+	wordPattern    = regexp.MustCompile(`[\pL\pN]+`)
+	noindexPattern = regexp.MustCompile(`(?i)<meta[^>]+name=["']?robots["']?[^>]+content=["'][^"']*noindex`)
+)
+
+// The words of an HTML fragment, lowercased, with markup, scripts and entities removed.
+func htmlWords(s string) []string {
+	s = scriptPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(tagPattern.ReplaceAllString(s, " "))
+	return wordPattern.FindAllString(strings.ToLower(s), -1)
+}
+
+// Compare every converted post that has a Blogger URL against the live page,
+// reporting posts whose title or text didn't survive the export or conversion.
+func (c *Converter) verifySite(ctx context.Context, dir string) error {
+	r, ok := c.out.(ReadFS)
+	if !ok {
+		return errors.New("verify needs a directory of converted posts")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	checked, flagged := 0, 0
+	tick := time.NewTicker(*verifyRate)
+	defer tick.Stop()
+	for _, e := range c.exp.Entries {
+		if e.Path == "" || e.URL == "" || e.Draft {
+			continue
+		}
+		converted, err := r.ReadFile(e.Path)
+		if err != nil {
+			log.Printf("%s: %s", e.Path, err)
+			flagged++
+			continue
+		}
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			log.Printf("Stopped: %s", ctx.Err())
+			log.Printf("Verified %d posts against %s before stopping, %d need a look.", checked, dir, flagged)
+			return nil
+		}
+		live, err := fetchPage(ctx, client, e.URL)
+		if err != nil {
+			log.Printf("%s: %s", e.URL, err)
+			flagged++
+			continue
+		}
+		checked++
+		if noindexPattern.MatchString(live) && e.Hidden == "" {
+			log.Printf("%s (%s): the live page is marked noindex; consider -hidden", e.Path, e.URL)
+		}
+		_, body, _ := splitFrontMatter(converted)
+		if problem := comparePost(e.Title, string(body), live); problem != "" {
+			log.Printf("%s (%s): %s", e.Path, e.URL, problem)
+			flagged++
+		}
+	}
+	log.Printf("Verified %d posts against %s, %d need a look.", checked, dir, flagged)
+	return nil
+}
+
+func fetchPage(ctx context.Context, client *http.Client, u string) (string, error) {
+	resp, err := request(ctx, client, "GET", u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching the live page returned %s", resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	return string(b), err
+}
+
+// Describe how the converted post differs from the live page, or "" if it matches.
+func comparePost(title, converted, live string) string {
+	liveWords := map[string]bool{}
+	for _, w := range htmlWords(live) {
+		liveWords[w] = true
+	}
+	for _, w := range htmlWords(title) {
+		if !liveWords[w] {
+			return fmt.Sprintf("title word %q is not on the live page", w)
+		}
+	}
+	words := htmlWords(converted)
+	if len(words) == 0 {
+		return "the converted post has no text"
+	}
+	found := 0
+	for _, w := range words {
+		if liveWords[w] {
+			found++
+		}
+	}
+	if share := float64(found) / float64(len(words)); share < *verifyThreshold {
+		return fmt.Sprintf("only %.0f%% of the converted text is on the live page", share*100)
+	}
+	return ""
+}
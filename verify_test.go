@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Verifying into an archive, which can't be read back, is an error rather than an exit.
+func TestVerifyNeedsDirectory(t *testing.T) {
+	c := newConverter()
+	a, err := newArchive(io.Discard, "content.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.out = a
+	if err := c.verifySite(context.Background(), "content.zip"); err == nil || !strings.Contains(err.Error(), "directory") {
+		t.Errorf("got %v, want an error asking for a directory", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var migrationWarnings = flag.String("migration-warnings", "", "mark the posts and pages whose conversion lost something, such as a table that won't render, an image that couldn't be copied from the Takeout or a link that matched no converted post, so they can be reviewed: comment to list it in an HTML comment at the top of the body, or frontmatter for a migration_warnings list")
+
+// The links of a post that matched no converted post or anchor, as warnings about it.
+func linkWarnings(title string, unresolved []string) []string {
+	warnings := make([]string, len(unresolved))
+	for i, u := range unresolved {
+		// Said of the post by its title.
+		warnings[i] = strings.TrimPrefix(u, title+" ")
+	}
+	return warnings
+}
+
+// The links of a post -check-links found dead, as warnings about it.
+func (c *Converter) deadLinkWarnings(e Entry) []string {
+	var warnings []string
+	seen := map[string]bool{}
+	for _, m := range linkAttrPattern.FindAllStringSubmatch(e.Content, -1) {
+		raw := linkAttrURL(m)
+		if status, ok := c.linkStatus[raw]; ok && isDead(status) && !seen[raw] {
+			seen[raw] = true
+			w := fmt.Sprintf("links to %s, which is dead (%s)", raw, status)
+			if *archiveDeadLinks {
+				w += ", so to its copy on web.archive.org instead"
+			}
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// Put what was lost converting the post into it per -migration-warnings: as Warnings
+// for its front matter, or in an HTML comment at the top of its body.
+func markWarnings(e *Entry, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	if *migrationWarnings == "frontmatter" {
+		e.Warnings = warnings
+		return
+	}
+	var b strings.Builder
+	b.WriteString("<!-- Migration warnings, to review (lines count from the one after this comment):\n")
+	for _, w := range warnings {
+		// -- can't be in a comment.
+		fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(w, "--", "- -"))
+	}
+	b.WriteString("-->\n")
+	e.Content = b.String() + e.Content
+}
@@ -0,0 +1,105 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+func init() {
+	platformMain = browserMain
+	// There's no file system in a browser.
+	newScratch = newMemScratch
+}
+
+// Conversions share the flags, so they're run one at a time.
+var browserMu sync.Mutex
+
+// In a browser the page calls blogger2hugo.convert(export, name, args), with the export's
+// bytes as a Uint8Array, its file name and the options as they'd be given on the command
+// line, and gets back a promise of the zip of its content as a Uint8Array.
+func browserMain() {
+	flag.CommandLine.Init("blogger2hugo", flag.ContinueOnError)
+	js.Global().Set("blogger2hugo", map[string]interface{}{
+		"convert": js.FuncOf(convertInBrowser),
+	})
+	select {}
+}
+
+func convertInBrowser(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New("convert takes the export and its name"))
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+	name := args[1].String()
+	var options []string
+	if len(args) > 2 && args[2].Truthy() {
+		for i := 0; i < args[2].Length(); i++ {
+			options = append(options, args[2].Index(i).String())
+		}
+	}
+	run := js.FuncOf(func(this js.Value, p []js.Value) interface{} {
+		resolve, reject := p[0], p[1]
+		go func() {
+			b, err := convertBytes(data, name, options)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			zip := js.Global().Get("Uint8Array").New(len(b))
+			js.CopyBytesToJS(zip, b)
+			resolve.Invoke(zip)
+		}()
+		return nil
+	})
+	// The promise runs it straight away.
+	defer run.Release()
+	return js.Global().Get("Promise").New(run)
+}
+
+// Convert an export held in memory into a zip of its content, with the options starting
+// from their defaults each time.
+func convertBytes(data []byte, name string, options []string) ([]byte, error) {
+	browserMu.Lock()
+	defer browserMu.Unlock()
+	flag.VisitAll(func(f *flag.Flag) {
+		f.Value.Set(f.DefValue)
+	})
+	if err := flag.CommandLine.Parse(options); err != nil {
+		return nil, err
+	}
+	if err := checkFlags(); err != nil {
+		return nil, err
+	}
+	if *format != "yaml" && *format != "toml" {
+		return nil, fmt.Errorf("Unknown value for -format: %s", *format)
+	}
+
+	ctx := context.Background()
+	c := flagConverter()
+	c.extra = flag.Lookup("extra").Value.String()
+	start := time.Now()
+	exp, err := importExport(ctx, name, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer exp.Close()
+	c.exp = exp
+	c.timings.add("parse", time.Since(start))
+	if len(exp.Entries) < 1 {
+		return nil, errors.New("No blog entries found!")
+	}
+	var buf bytes.Buffer
+	if err := c.convertToZip(ctx, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
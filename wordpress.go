@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atulsingh0/blogger2hugo/blogger"
+)
+
+// A WordPress eXtended RSS (WXR) export, from Tools > Export in WordPress.
+// Elements are matched by local name, so every WXR version (wp namespace 1.0 to 1.2) reads the same.
+type wxrExport struct {
+	Title   string      `xml:"channel>title"`
+	Link    string      `xml:"channel>link"`
+	Authors []wxrAuthor `xml:"channel>author"`
+	Items   []wxrItem   `xml:"channel>item"`
+}
+
+type wxrAuthor struct {
+	Login       string `xml:"author_login"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Creator    string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Content    string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	ID         uint64        `xml:"post_id"`
+	Date       string        `xml:"post_date"`
+	DateGMT    string        `xml:"post_date_gmt"`
+	Modified   string        `xml:"post_modified_gmt"`
+	Name       string        `xml:"post_name"`
+	Status     string        `xml:"status"`
+	Type       string        `xml:"post_type"`
+	Categories []wxrCategory `xml:"category"`
+	Comments   []wxrComment  `xml:"comment"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID        uint64 `xml:"comment_id"`
+	Author    string `xml:"comment_author"`
+	AuthorURL string `xml:"comment_author_url"`
+	DateGMT   string `xml:"comment_date_gmt"`
+	Content   string `xml:"comment_content"`
+	Approved  string `xml:"comment_approved"`
+	Type      string `xml:"comment_type"`
+	Parent    uint64 `xml:"comment_parent"`
+}
+
+type wxrImporter struct{}
+
+func (wxrImporter) Detect(b []byte) bool {
+	return rootElement(b) == "rss" && bytes.Contains(b, []byte("wordpress.org/export/"))
+}
+
+func (wxrImporter) Import(b []byte) (Export, error) {
+	var w wxrExport
+	if err := xml.Unmarshal(b, &w); err != nil {
+		return Export{}, err
+	}
+	names := map[string]string{}
+	for _, a := range w.Authors {
+		names[a.Login] = a.DisplayName
+	}
+	exp := Export{Title: w.Title}
+	for _, it := range w.Items {
+		var kind string
+		switch it.Type {
+		case "post":
+			kind = "post"
+		case "page":
+			kind = "page"
+		default:
+			// Attachments, menu items, revisions and custom post types.
+			continue
+		}
+		if it.Status == "trash" || it.Status == "auto-draft" || it.Status == "inherit" {
+			continue
+		}
+		published := wxrDate(it.DateGMT, it.Date)
+		e := Entry{
+			Entry: blogger.Entry{
+				ID:        "wordpress.post-" + strconv.FormatUint(it.ID, 10),
+				Published: published,
+				Updated:   wxrDate(it.Modified, ""),
+				Draft:     Draft(it.Status != "publish" && it.Status != "private"),
+				Title:     it.Title,
+				Content:   it.Content,
+				Tags:      Tags{kindTag(kind)},
+				Author:    Author{Name: it.Creator},
+			},
+			Slug: it.Name,
+		}
+		if n, ok := names[it.Creator]; ok && n != "" {
+			e.Author.Name = n
+		}
+		if time.Time(e.Updated).IsZero() {
+			e.Updated = published
+		}
+		if it.Status == "private" {
+			e.Hidden = "private on WordPress"
+		}
+		if !bool(e.Draft) && it.Link != "" {
+			e.Links = []Reply{{Rel: "alternate", Link: it.Link}}
+		}
+		for _, c := range it.Categories {
+			if c.Domain == "category" || c.Domain == "post_tag" {
+				if name := html.UnescapeString(strings.TrimSpace(c.Name)); name != "" && name != "Uncategorized" {
+					e.Tags = append(e.Tags, labelTag(name))
+				}
+			}
+		}
+		exp.Entries = append(exp.Entries, e)
+
+		for _, c := range it.Comments {
+			if c.Approved != "1" || c.Type == "pingback" || c.Type == "trackback" {
+				continue
+			}
+			ce := Entry{
+				Entry: blogger.Entry{
+					ID:        "wordpress.post-" + strconv.FormatUint(commentIDBase+c.ID, 10),
+					Published: wxrDate(c.DateGMT, ""),
+					Title:     c.Author,
+					Content:   c.Content,
+					Tags:      Tags{kindTag("comment")},
+					Author:    Author{Name: c.Author, Uri: c.AuthorURL},
+					Source:    Reply{Source: "wordpress/" + strconv.FormatUint(it.ID, 10)},
+				},
+			}
+			ce.Updated = ce.Published
+			if c.Parent != 0 {
+				ce.Links = []Reply{{Rel: "related", Link: "wordpress/" + strconv.FormatUint(commentIDBase+c.Parent, 10)}}
+			}
+			exp.Entries = append(exp.Entries, ce)
+		}
+	}
+	return exp, nil
+}
+
+// WordPress dates are "2006-01-02 15:04:05", in UTC for the _gmt fields. Unpublished
+// posts have a zero GMT date, so the local one is used instead.
+func wxrDate(gmt, local string) Date {
+	for _, s := range []string{gmt, local} {
+		if t, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(s)); err == nil && t.Year() > 1 {
+			return Date(t)
+		}
+	}
+	return Date{}
+}
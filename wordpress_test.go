@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+<title>Stories</title>
+<link>https://stories.example.com</link>
+<wp:author><wp:author_login>della</wp:author_login><wp:author_display_name>Della Young</wp:author_display_name></wp:author>
+<item>
+<title>The Gift of the Magi</title>
+<link>https://stories.example.com/2014/05/gift/</link>
+<dc:creator>della</dc:creator>
+<content:encoded><![CDATA[<p>One dollar and eighty-seven cents.</p>]]></content:encoded>
+<wp:post_id>7</wp:post_id>
+<wp:post_date>2014-05-19 16:36:00</wp:post_date>
+<wp:post_date_gmt>2014-05-19 23:36:00</wp:post_date_gmt>
+<wp:post_modified_gmt>2014-05-20 08:00:00</wp:post_modified_gmt>
+<wp:post_name>gift</wp:post_name>
+<wp:status>publish</wp:status>
+<wp:post_type>post</wp:post_type>
+<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+<category domain="category" nicename="o-henry"><![CDATA[O. Henry]]></category>
+<category domain="post_tag" nicename="christmas"><![CDATA[Christmas &amp; Gifts]]></category>
+<wp:comment>
+<wp:comment_id>3</wp:comment_id>
+<wp:comment_author>Jim</wp:comment_author>
+<wp:comment_date_gmt>2014-05-20 10:00:00</wp:comment_date_gmt>
+<wp:comment_content>Lovely.</wp:comment_content>
+<wp:comment_approved>1</wp:comment_approved>
+<wp:comment_parent>0</wp:comment_parent>
+</wp:comment>
+<wp:comment>
+<wp:comment_id>4</wp:comment_id>
+<wp:comment_author>Della</wp:comment_author>
+<wp:comment_date_gmt>2014-05-20 11:00:00</wp:comment_date_gmt>
+<wp:comment_content>Thanks, Jim.</wp:comment_content>
+<wp:comment_approved>1</wp:comment_approved>
+<wp:comment_parent>3</wp:comment_parent>
+</wp:comment>
+<wp:comment>
+<wp:comment_id>5</wp:comment_id>
+<wp:comment_author>Spammer</wp:comment_author>
+<wp:comment_content>Buy now</wp:comment_content>
+<wp:comment_approved>spam</wp:comment_approved>
+</wp:comment>
+<wp:comment>
+<wp:comment_id>6</wp:comment_id>
+<wp:comment_author>Other blog</wp:comment_author>
+<wp:comment_content>Linked here</wp:comment_content>
+<wp:comment_approved>1</wp:comment_approved>
+<wp:comment_type>pingback</wp:comment_type>
+</wp:comment>
+</item>
+<item>
+<title>Unfinished</title>
+<dc:creator>della</dc:creator>
+<content:encoded><![CDATA[<p>Someday.</p>]]></content:encoded>
+<wp:post_id>8</wp:post_id>
+<wp:post_date>2014-06-01 09:00:00</wp:post_date>
+<wp:post_date_gmt>0000-00-00 00:00:00</wp:post_date_gmt>
+<wp:status>draft</wp:status>
+<wp:post_type>post</wp:post_type>
+</item>
+<item>
+<title>About</title>
+<dc:creator>della</dc:creator>
+<content:encoded><![CDATA[<p>About us.</p>]]></content:encoded>
+<wp:post_id>9</wp:post_id>
+<wp:post_date_gmt>2014-01-01 00:00:00</wp:post_date_gmt>
+<wp:status>publish</wp:status>
+<wp:post_type>page</wp:post_type>
+</item>
+<item>
+<title>photo.jpg</title>
+<wp:post_id>10</wp:post_id>
+<wp:status>inherit</wp:status>
+<wp:post_type>attachment</wp:post_type>
+</item>
+<item>
+<title>Thrown out</title>
+<wp:post_id>11</wp:post_id>
+<wp:status>trash</wp:status>
+<wp:post_type>post</wp:post_type>
+</item>
+</channel>
+</rss>`
+
+// What testWXR imports as: comments above wxrCommentBase, the draft dated locally,
+// and the attachment and trashed post left out.
+var testWXRWant = []string{
+	`post wordpress.post-7 "The Gift of the Magi" by Della Young at 2014-05-19T23:36:00Z labels O. Henry, Christmas & Gifts`,
+	`comment wordpress.post-1099511627779 "Jim" by Jim at 2014-05-20T10:00:00Z on wordpress/7`,
+	`comment wordpress.post-1099511627780 "Della" by Della at 2014-05-20T11:00:00Z on wordpress/7 replying to wordpress/1099511627779`,
+	`post wordpress.post-8 "Unfinished" by Della Young at 2014-06-01T09:00:00Z draft`,
+	`page wordpress.post-9 "About" by Della Young at 2014-01-01T00:00:00Z`,
+}
+
+func TestWordPressImport(t *testing.T) {
+	testImport(t, "wordpress", testWXR, testWXRWant)
+}
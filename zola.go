@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Zola's posts: YYYY-MM-DD-slug.md in the section converted into, which Zola dates by
+// and drops the date from for their URL, under an _index.md for the section. Its front
+// matter is TOML, with labels under [taxonomies] and everything Zola has no key for
+// under [extra], comments included, as Zola has nowhere else for them.
+type zolaTarget struct{}
+
+var zolaTempl = `+++
+title = "{{ .Title }}"
+slug = "{{ .Slug }}"
+date = {{ .Published }}
+updated = {{ .Updated }}{{ if .Draft }}
+draft = true{{ end }}{{ with quoteList .Aliases }}
+aliases = [{{ . }}]{{ end }}{{ with .Extra }}
+{{.}}{{ end }}
+[taxonomies]{{ with quoteList .Tags.Labels }}
+{{ tagsKey }} = [{{ . }}]{{ end }}{{ with authors . }}
+authors = [{{ . }}]{{ end }}
+[extra]
+author = "{{ .Author.Name }}"{{ with .Author.Uri }}
+author_uri = "{{ . }}"{{ end }}{{ if .Featured }}
+{{ featuredKey }} = true{{ end }}{{ with .Views }}
+views = {{ . }}{{ end }}{{ if .Unlisted }}
+unlisted = true{{ end }}{{ with .BloggerID }}
+blogger_id = "{{ . }}"{{ end }}{{ with .URL }}
+blogger_url = "{{ . }}"{{ end }}{{ range $k, $v := .Params }}
+{{ $k }} = {{ printf "%q" $v }}{{ end }}{{ with quoteList .Related }}
+related = [{{ . }}]{{ end }}{{ with quoteList .Warnings }}
+migration_warnings = [{{ . }}]{{ end }}{{ with .Enclosure }}
+[extra.enclosure]
+url = "{{ .URL }}"{{ if .Length }}
+length = {{ .Length }}{{ end }}
+type = "{{ .Type }}"{{ end }}{{ with extras . }}
+{{ . }}{{ end }}{{ with zolaComments . }}
+{{ . }}{{ end }}
++++
+{{ .Content }}
+`
+
+func (zolaTarget) Template() string { return zolaTempl }
+
+func (zolaTarget) PlacePost(c *Converter, e *Entry) error {
+	date := e.Published.String()[:10]
+	name, err := c.claimPath("", date+"-"+c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = strings.TrimPrefix(name, date+"-")
+	e.Path = name + ".md"
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+func (zolaTarget) PlacePage(c *Converter, e *Entry) error {
+	dir := path.Clean(filepath.ToSlash(*pagesDir))
+	name, err := c.claimPath(dir, c.entrySlug(*e), *e)
+	if err != nil {
+		return err
+	}
+	e.Slug = name
+	e.Path = path.Join(dir, name+".md")
+	c.addMapping(*e, e.Path)
+	return nil
+}
+
+// The section's path and the slug, e.g. /blog/hello/ for 2014-05-19-hello.md in
+// content/blog.
+func (zolaTarget) Permalink(c *Converter, e Entry, name string) string {
+	return path.Join(c.urlPrefix, path.Dir(name), e.Slug) + "/"
+}
+
+// Comments go into their post's [extra], see zolaComments.
+func (zolaTarget) WriteComment(c *Converter, e Entry) error { return nil }
+
+// The section's _index.md, listing its posts newest first.
+func (zolaTarget) WriteSite(c *Converter) error {
+	f, err := c.create("_index.md")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "+++\ntitle = %q\nsort_by = \"date\"\n+++\n", c.exp.Title)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// A post's comments, oldest thread first, as [[extra.comments]] tables for its
+// template to show.
+func (c *Converter) zolaComments(e Entry) string {
+	var b strings.Builder
+	for _, i := range e.Children {
+		comment := c.exp.Entries[i]
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[[extra.comments]]\nid = %q\nauthor = %q", comment.ID, strings.TrimSpace(comment.Author.Name))
+		if comment.Author.Uri != "" {
+			fmt.Fprintf(&b, "\nauthor_uri = %q", comment.Author.Uri)
+		}
+		if comment.Reply != 0 && fmt.Sprint(comment.Reply) != e.ID {
+			fmt.Fprintf(&b, "\nreplying_to = \"%d\"", comment.Reply)
+		}
+		fmt.Fprintf(&b, "\ndate = %s\ncontent = %q", comment.Published, entryContent(comment))
+	}
+	return b.String()
+}